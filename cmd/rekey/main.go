@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+
+	"github.com/grassrootseconomics/eth-custodial/internal/store"
+	"github.com/grassrootseconomics/eth-custodial/internal/util"
+	"github.com/knadh/koanf/v2"
+)
+
+var (
+	confFlag       string
+	queriesFlag    string
+	migrationsFlag string
+	batchSizeFlag  int
+	dryRun         bool
+
+	lo *slog.Logger
+	ko *koanf.Koanf
+)
+
+func init() {
+	flag.StringVar(&confFlag, "config", "config.toml", "Config file location")
+	flag.StringVar(&queriesFlag, "queries", "queries.sql", "Queries file location")
+	flag.StringVar(&migrationsFlag, "migrations", "migrations/", "Migrations folder location")
+	flag.IntVar(&batchSizeFlag, "batch-size", 100, "Number of keys to re-encrypt per database transaction")
+	flag.BoolVar(&dryRun, "dry-run", false, "Only count keys that would be re-encrypted")
+}
+
+// rekey encrypts legacy plaintext keys and re-encrypts keys sealed with an older KEK under keystore.kek_version.
+// Rotating the KEK:
+//  1. Add the new KEK to keystore.keks alongside the old one and point keystore.kek_version to it.
+//  2. Roll out the service, it can now decrypt both versions and seals new keys with the new one.
+//  3. Run rekey until it reports 0 remaining keys.
+//  4. Remove the old KEK from the config.
+func main() {
+	flag.Parse()
+
+	lo = util.InitLogger()
+	ko = util.InitConfig(lo, confFlag)
+
+	ctx := store.WithKeyAccess(context.Background(), store.KeyAccess{Caller: "rekey"})
+
+	keyring, err := util.LoadKeyring(ko)
+	if err != nil {
+		lo.Error("failed to load keystore keyring", "error", err)
+		os.Exit(1)
+	}
+	if keyring == nil {
+		lo.Error("no kek configured, set keystore.keks or keystore.kek_file")
+		os.Exit(1)
+	}
+
+	pgStore, err := store.NewPgStore(store.PgOpts{
+		Logg:                 lo,
+		DSN:                  ko.MustString("postgres.dsn"),
+		MigrationsFolderPath: migrationsFlag,
+		QueriesFolderPath:    queriesFlag,
+		Keyring:              keyring,
+	})
+	if err != nil {
+		lo.Error("failed to initialize store", "error", err)
+		os.Exit(1)
+	}
+
+	if dryRun {
+		var pending int
+		if err := pgStore.Pool().QueryRow(ctx,
+			`SELECT COUNT(*) FROM keystore WHERE key_version <> $1 AND derivation_index IS NULL AND private_key <> ''`, keyring.CurrentVersion(),
+		).Scan(&pending); err != nil {
+			lo.Error("failed to count keys", "error", err)
+			os.Exit(1)
+		}
+		lo.Info("[DRY RUN] keys pending re-encryption", "count", pending, "kek_version", keyring.CurrentVersion())
+		return
+	}
+
+	var total int
+	for {
+		rekeyed, err := rekeyBatch(ctx, pgStore)
+		if err != nil {
+			lo.Error("failed to re-encrypt batch, aborting", "rekeyed", total, "error", err)
+			os.Exit(1)
+		}
+		if rekeyed == 0 {
+			break
+		}
+
+		total += rekeyed
+		lo.Info("re-encrypted batch", "batch", rekeyed, "total", total)
+	}
+
+	lo.Info("rekey complete", "rekeyed", total, "kek_version", keyring.CurrentVersion())
+}
+
+func rekeyBatch(ctx context.Context, pgStore store.Store) (int, error) {
+	tx, err := pgStore.Pool().Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	rekeyed, err := pgStore.RekeyKeyPairs(ctx, tx, batchSizeFlag)
+	if err != nil {
+		return 0, err
+	}
+
+	return rekeyed, tx.Commit(ctx)
+}
@@ -0,0 +1,493 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+	"github.com/grassrootseconomics/eth-custodial/internal/keypair"
+	txsigner "github.com/grassrootseconomics/eth-custodial/internal/signer"
+	"github.com/grassrootseconomics/eth-custodial/internal/store"
+	"github.com/grassrootseconomics/eth-custodial/internal/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/knadh/koanf/v2"
+	"github.com/lmittmann/w3"
+	"github.com/lmittmann/w3/module/eth"
+	"github.com/lmittmann/w3/w3types"
+)
+
+const transferGasLimit = 21_000
+
+var (
+	confFlag       string
+	queriesFlag    string
+	migrationsFlag string
+	oldFlag        string
+	settleTimeout  time.Duration
+	pollInterval   time.Duration
+	dryRun         bool
+
+	lo *slog.Logger
+	ko *koanf.Koanf
+
+	ownerFunc             = w3.MustNewFunc("owner()", "address")
+	transferOwnershipFunc = w3.MustNewFunc("transferOwnership(address)", "bool")
+)
+
+func init() {
+	flag.StringVar(&confFlag, "config", "config.toml", "Config file location")
+	flag.StringVar(&queriesFlag, "queries", "queries.sql", "Queries file location")
+	flag.StringVar(&migrationsFlag, "migrations", "migrations/", "Migrations folder location")
+	flag.StringVar(&oldFlag, "old", "", "System signer to rotate out (defaults to the master signer)")
+	flag.DurationVar(&settleTimeout, "settle-timeout", 30*time.Minute, "Maximum time to wait for pending transactions of the old key")
+	flag.DurationVar(&pollInterval, "poll-interval", 15*time.Second, "Interval between network nonce checks")
+	flag.BoolVar(&dryRun, "dry-run", false, "Log actions without executing")
+}
+
+// rotate-master replaces a system signer, typically the master signer created on bootstrap, with a newly generated key:
+//  1. The new key is registered outside the system signer pool, the old key keeps receiving work.
+//  2. Once the new key is funded and allowed on the gas faucet and the custodial registration proxy, the old key is
+//     retired and the new key added to the system signer pool in one step.
+//  3. Wait until every nonce acquired by the old key is mined.
+//  4. Transfer ownership of contracts deployed by the old key that it still owns.
+//  5. Move the remaining native balance of the old key to the new key.
+//
+// The old key stays active only to finish its pending transactions. An interrupted rotation is resumed on the next run,
+// a run that stops because the new key is not funded or allowlisted yet resumes at step 2.
+func main() {
+	flag.Parse()
+
+	lo = util.InitLogger()
+	ko = util.InitConfig(lo, confFlag)
+
+	ctx := store.WithKeyAccess(context.Background(), store.KeyAccess{Caller: "rotate-master"})
+
+	if backend := ko.String("signer.backend"); backend != "" && backend != "keystore" {
+		lo.Error("system signers can only be rotated with the keystore signer backend", "backend", backend)
+		os.Exit(1)
+	}
+
+	keyring, err := util.LoadKeyring(ko)
+	if err != nil {
+		lo.Error("failed to load keystore keyring", "error", err)
+		os.Exit(1)
+	}
+
+	pgStore, err := store.NewPgStore(store.PgOpts{
+		Logg:                 lo,
+		DSN:                  ko.MustString("postgres.dsn"),
+		MigrationsFolderPath: migrationsFlag,
+		QueriesFolderPath:    queriesFlag,
+		Keyring:              keyring,
+	})
+	if err != nil {
+		lo.Error("failed to initialize store", "error", err)
+		os.Exit(1)
+	}
+
+	client, err := w3.Dial(ko.MustString("chain.rpc_endpoint"))
+	if err != nil {
+		lo.Error("failed to dial RPC endpoint", "error", err)
+		os.Exit(1)
+	}
+	defer client.Close()
+
+	chainSigner := types.LatestSignerForChainID(big.NewInt(ko.MustInt64("chain.id")))
+	txSigner, err := util.LoadSigner(ko, pgStore, chainSigner)
+	if err != nil {
+		lo.Error("failed to initialize signer", "error", err)
+		os.Exit(1)
+	}
+
+	rotation, err := loadRotation(ctx, pgStore)
+	if err != nil {
+		lo.Error("failed to load master signer rotation", "error", err)
+		os.Exit(1)
+	}
+	if dryRun {
+		return
+	}
+
+	r := &rotator{
+		store:    pgStore,
+		client:   client,
+		signer:   txSigner,
+		rotation: rotation,
+	}
+
+	if err := r.run(ctx); err != nil {
+		lo.Error("master signer rotation failed, run again to resume", "rotation_id", rotation.ID, "error", err)
+		os.Exit(1)
+	}
+
+	lo.Info("master signer rotation complete",
+		"old", rotation.OldPublicKey,
+		"new", rotation.NewPublicKey,
+	)
+}
+
+// loadRotation resumes the rotation in progress or begins a new one.
+func loadRotation(ctx context.Context, pgStore store.Store) (*store.MasterSignerRotation, error) {
+	tx, err := pgStore.Pool().Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	rotation, err := pgStore.GetPendingMasterSignerRotation(ctx, tx)
+	if err == nil {
+		lo.Info("resuming master signer rotation", "rotation_id", rotation.ID, "old", rotation.OldPublicKey, "new", rotation.NewPublicKey)
+		return rotation, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	oldPublicKey := oldFlag
+	if oldPublicKey == "" {
+		if oldPublicKey, err = pgStore.LoadMasterSignerAddress(ctx, tx); err != nil {
+			return nil, err
+		}
+	}
+
+	if dryRun {
+		lo.Info("[DRY RUN] would rotate system signer", "old", oldPublicKey)
+		return &store.MasterSignerRotation{OldPublicKey: oldPublicKey}, nil
+	}
+
+	newKeyPair, err := keypair.GenerateKeyPair()
+	if err != nil {
+		return nil, err
+	}
+
+	rotationID, err := pgStore.BeginMasterSignerRotation(ctx, tx, oldPublicKey, newKeyPair)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	lo.Info("began master signer rotation, fund the new key and allow it on the gas faucet and custodial registration proxy",
+		"rotation_id", rotationID,
+		"old", oldPublicKey,
+		"new", newKeyPair.Public,
+	)
+
+	return &store.MasterSignerRotation{
+		ID:           rotationID,
+		OldPublicKey: oldPublicKey,
+		NewPublicKey: newKeyPair.Public,
+	}, nil
+}
+
+type rotator struct {
+	store    store.Store
+	client   *w3.Client
+	signer   txsigner.Signer
+	rotation *store.MasterSignerRotation
+}
+
+func (r *rotator) run(ctx context.Context) error {
+	if !r.rotation.Switched {
+		if err := r.switchSigners(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err := r.waitSettled(ctx); err != nil {
+		return err
+	}
+
+	if err := r.transferOwnerships(ctx); err != nil {
+		return err
+	}
+
+	if err := r.waitSettled(ctx); err != nil {
+		return err
+	}
+
+	if err := r.moveBalance(ctx); err != nil {
+		return err
+	}
+
+	if err := r.waitSettled(ctx); err != nil {
+		return err
+	}
+
+	tx, err := r.store.Pool().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := r.store.CompleteMasterSignerRotation(ctx, tx, r.rotation.ID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// switchSigners retires the old key and adds the new key to the system signer pool once the new key is funded and
+// allowlisted. Until then the old key keeps receiving work.
+func (r *rotator) switchSigners(ctx context.Context) error {
+	if err := util.CheckSystemSigner(ctx, ko, common.HexToAddress(r.rotation.NewPublicKey)); err != nil {
+		return fmt.Errorf("new key is not ready, fund it and allow it on the gas faucet and custodial registration proxy: %w", err)
+	}
+
+	tx, err := r.store.Pool().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := r.store.SwitchMasterSignerRotation(ctx, tx, r.rotation.ID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	r.rotation.Switched = true
+	lo.Info("switched system signer", "old", r.rotation.OldPublicKey, "new", r.rotation.NewPublicKey)
+
+	return nil
+}
+
+// waitSettled blocks until the network nonce of the old key catches up with every nonce acquired from the noncestore.
+func (r *rotator) waitSettled(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, settleTimeout)
+	defer cancel()
+
+	oldAddress := common.HexToAddress(r.rotation.OldPublicKey)
+	for {
+		nextNonce, err := r.nextNonce(ctx)
+		if err != nil {
+			return err
+		}
+
+		var networkNonce uint64
+		if err := r.client.CallCtx(ctx, eth.Nonce(oldAddress, nil).Returns(&networkNonce)); err != nil {
+			return err
+		}
+
+		if networkNonce >= nextNonce {
+			lo.Info("old key settled", "nonce", networkNonce)
+			return nil
+		}
+		lo.Info("waiting for old key to settle", "network_nonce", networkNonce, "next_nonce", nextNonce)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("old key did not settle, run the unlocker for %s: %w", r.rotation.OldPublicKey, ctx.Err())
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+func (r *rotator) nextNonce(ctx context.Context) (uint64, error) {
+	tx, err := r.store.Pool().Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	return r.store.GetNextNonce(ctx, tx, r.rotation.OldPublicKey)
+}
+
+func (r *rotator) deployNonces(ctx context.Context) ([]uint64, error) {
+	tx, err := r.store.Pool().Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	return r.store.GetDeployNonces(ctx, tx, r.rotation.OldPublicKey)
+}
+
+// transferOwnerships hands over contracts deployed by the old key. Contracts that are not ownable or whose ownership
+// was already transferred during deployment are skipped.
+func (r *rotator) transferOwnerships(ctx context.Context) error {
+	nonces, err := r.deployNonces(ctx)
+	if err != nil {
+		return err
+	}
+
+	oldAddress := common.HexToAddress(r.rotation.OldPublicKey)
+	newAddress := common.HexToAddress(r.rotation.NewPublicKey)
+
+	for _, nonce := range nonces {
+		contractAddress := crypto.CreateAddress(oldAddress, nonce)
+
+		var owner common.Address
+		if err := r.client.CallCtx(ctx, eth.CallFunc(contractAddress, ownerFunc).Returns(&owner)); err != nil {
+			lo.Debug("skipping contract without owner", "contract", contractAddress, "error", err)
+			continue
+		}
+		if owner != oldAddress {
+			continue
+		}
+
+		input, err := transferOwnershipFunc.EncodeArgs(newAddress)
+		if err != nil {
+			return err
+		}
+
+		if err := r.send(ctx, store.TRANSFER_OWNERSHIP, contractAddress, nil, input, 0); err != nil {
+			return err
+		}
+		lo.Info("transferred contract ownership", "contract", contractAddress)
+	}
+
+	return nil
+}
+
+// moveBalance sends the old key's native balance less the maximum transfer fee to the new key. The difference between
+// the fee cap and the effective gas price remains on the old key.
+func (r *rotator) moveBalance(ctx context.Context) error {
+	var balance *big.Int
+	if err := r.client.CallCtx(ctx, eth.Balance(common.HexToAddress(r.rotation.OldPublicKey), nil).Returns(&balance)); err != nil {
+		return err
+	}
+
+	gasFeeCap, _, err := r.gasPrice(ctx)
+	if err != nil {
+		return err
+	}
+
+	value := new(big.Int).Sub(balance, new(big.Int).Mul(gasFeeCap, big.NewInt(transferGasLimit)))
+	if value.Sign() <= 0 {
+		lo.Warn("old key balance does not cover the transfer fee, skipping balance move", "balance", balance)
+		return nil
+	}
+
+	if err := r.send(ctx, store.GAS_TRANSFER, common.HexToAddress(r.rotation.NewPublicKey), value, nil, transferGasLimit); err != nil {
+		return err
+	}
+	lo.Info("moved balance to new key", "value", value)
+
+	return nil
+}
+
+// send signs a transaction from the old key and records it as an OTX before broadcasting it so that it is tracked like
+// any other custodial transaction. A gasLimit of 0 is estimated.
+func (r *rotator) send(ctx context.Context, otxType string, to common.Address, value *big.Int, input []byte, gasLimit uint64) error {
+	oldAddress := common.HexToAddress(r.rotation.OldPublicKey)
+
+	if gasLimit == 0 {
+		if err := r.client.CallCtx(ctx, eth.EstimateGas(&w3types.Message{
+			From:  oldAddress,
+			To:    &to,
+			Value: value,
+			Input: input,
+		}, nil).Returns(&gasLimit)); err != nil {
+			return err
+		}
+	}
+
+	gasFeeCap, gasTipCap, err := r.gasPrice(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.store.Pool().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	nonce, err := r.store.AcquireNonce(ctx, tx, r.rotation.OldPublicKey)
+	if err != nil {
+		return err
+	}
+
+	signedTx, err := r.signer.SignTx(ctx, tx, oldAddress, types.NewTx(&types.DynamicFeeTx{
+		Nonce:     nonce,
+		To:        &to,
+		Value:     value,
+		Data:      input,
+		Gas:       gasLimit,
+		GasFeeCap: gasFeeCap,
+		GasTipCap: gasTipCap,
+	}))
+	if err != nil {
+		return err
+	}
+
+	rawTx, err := signedTx.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	otxID, err := r.store.InsertOTX(ctx, tx, store.OTX{
+		TrackingID:    uuid.NewString(),
+		OTXType:       otxType,
+		SignerAccount: r.rotation.OldPublicKey,
+		RawTx:         hexutil.Encode(rawTx),
+		TxHash:        signedTx.Hash().Hex(),
+		Nonce:         nonce,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := r.store.InsertDispatchTx(ctx, tx, store.DispatchTx{
+		OTXID:  otxID,
+		Status: store.PENDING,
+	}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	if err := r.client.CallCtx(ctx, eth.SendRawTx(rawTx).Returns(new(common.Hash))); err != nil {
+		return fmt.Errorf("broadcast otx %d: %w", otxID, err)
+	}
+
+	return r.updateDispatchStatus(ctx, otxID, store.IN_NETWORK)
+}
+
+func (r *rotator) updateDispatchStatus(ctx context.Context, otxID uint64, status string) error {
+	tx, err := r.store.Pool().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := r.store.UpdateDispatchTxStatus(ctx, tx, store.DispatchTx{
+		OTXID:  otxID,
+		Status: status,
+	}); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// gasPrice bumps the network gas price by 20% to accommodate fluctuations while waiting for inclusion.
+func (r *rotator) gasPrice(ctx context.Context) (*big.Int, *big.Int, error) {
+	var gasPrice, gasTipCap *big.Int
+	if err := r.client.CallCtx(ctx,
+		eth.GasPrice().Returns(&gasPrice),
+		eth.GasTipCap().Returns(&gasTipCap),
+	); err != nil {
+		return nil, nil, err
+	}
+
+	gasPrice.Mul(gasPrice, big.NewInt(120))
+	gasPrice.Div(gasPrice, big.NewInt(100))
+
+	return gasPrice, gasTipCap, nil
+}
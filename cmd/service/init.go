@@ -2,16 +2,21 @@ package main
 
 import (
 	"context"
+	"math/big"
 	"os"
 	"runtime"
+	"slices"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/grassrootseconomics/eth-custodial/internal/api"
 	ensclient "github.com/grassrootseconomics/eth-custodial/internal/ens_client"
 	"github.com/grassrootseconomics/eth-custodial/internal/gas"
 	internaljs "github.com/grassrootseconomics/eth-custodial/internal/jetstream"
+	"github.com/grassrootseconomics/eth-custodial/internal/multirpc"
 	"github.com/grassrootseconomics/eth-custodial/internal/pub"
+	"github.com/grassrootseconomics/eth-custodial/internal/signer"
 	"github.com/grassrootseconomics/eth-custodial/internal/store"
 	"github.com/grassrootseconomics/eth-custodial/internal/sub"
 	"github.com/grassrootseconomics/eth-custodial/internal/util"
@@ -25,12 +30,14 @@ var (
 	pgStore         store.Store
 	gasOracle       gas.GasOracle
 	chainProvider   *ethutils.Provider
+	rpcClient       *multirpc.Client
 	jsPub           *pub.Pub
 	jsSub           *sub.Sub
 	registry        map[string]common.Address
 	workerContainer *worker.WorkerContainer
 	apiServer       *api.API
 	ensClient       *ensclient.EnsClient
+	txSigner        signer.Signer
 
 	js       jetstream.JetStream
 	natsConn *nats.Conn
@@ -57,13 +64,19 @@ func loadStore() store.Store {
 	if pgStore != nil {
 		return pgStore
 	}
-	var err error
+	keyring, err := util.LoadKeyring(ko)
+	if err != nil {
+		lo.Error("could not load keystore keyring", "error", err)
+		os.Exit(1)
+	}
 
 	pgStore, err = store.NewPgStore(store.PgOpts{
 		Logg:                 lo,
 		DSN:                  ko.MustString("postgres.dsn"),
 		MigrationsFolderPath: migrationsFolderFlag,
 		QueriesFolderPath:    queriesFlag,
+		Keyring:              keyring,
+		HDDerivation:         ko.Bool("keystore.hd_derivation"),
 	})
 	if err != nil {
 		lo.Error("could not initialize postgres store", "error", err)
@@ -90,6 +103,82 @@ func loadChainProvider() *ethutils.Provider {
 	return chainProvider
 }
 
+// loadRPCClient connects to chain.rpc_endpoint and every node in chain.rpc_endpoints.
+func loadRPCClient() *multirpc.Client {
+	if rpcClient != nil {
+		return rpcClient
+	}
+	var err error
+
+	endpoints := []string{ko.MustString("chain.rpc_endpoint")}
+	for _, endpoint := range ko.Strings("chain.rpc_endpoints") {
+		if !slices.Contains(endpoints, endpoint) {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+
+	rpcClient, err = multirpc.New(multirpc.ClientOpts{
+		Logg:        lo,
+		Endpoints:   endpoints,
+		MaxBlockLag: uint64(ko.Int64("chain.max_block_lag")),
+	})
+	if err != nil {
+		lo.Error("could not initialize rpc client", "error", err)
+		os.Exit(1)
+	}
+	lo.Debug("loaded rpc client", "endpoints", len(endpoints))
+
+	return rpcClient
+}
+
+func loadSigner() signer.Signer {
+	if txSigner != nil {
+		return txSigner
+	}
+	var err error
+
+	txSigner, err = util.LoadSigner(ko, loadStore(), loadChainProvider().Signer)
+	if err != nil {
+		lo.Error("could not initialize signer", "error", err)
+		os.Exit(1)
+	}
+	lo.Debug("loaded signer", "backend", ko.String("signer.backend"))
+
+	return txSigner
+}
+
+// loadMaxGasFeeCap returns the highest fee cap a speed-up or retrier bump may set, nil if gas.max_fee_cap_gwei is unset.
+func loadMaxGasFeeCap() *big.Int {
+	maxFeeCapGwei := ko.Int64("gas.max_fee_cap_gwei")
+	if maxFeeCapGwei <= 0 {
+		return nil
+	}
+
+	return new(big.Int).Mul(big.NewInt(maxFeeCapGwei), big.NewInt(params.GWei))
+}
+
+// loadGasLimitEstimator caps every otx type at gas.estimate.default_ceiling unless gas.estimate.ceilings has its own.
+func loadGasLimitEstimator() *gas.GasLimitEstimator {
+	ceilings := make(map[string]uint64)
+	for otxType, ceiling := range ko.Int64Map("gas.estimate.ceilings") {
+		ceilings[otxType] = uint64(ceiling)
+	}
+
+	fallbackLimit := uint64(ko.Int64("gas.estimate.fallback_limit"))
+	if fallbackLimit == 0 {
+		fallbackLimit = uint64(ethutils.SafeGasLimit)
+	}
+
+	return gas.NewGasLimitEstimator(gas.GasLimitEstimatorOpts{
+		Logg:           lo,
+		RPC:            loadRPCClient(),
+		MarginPercent:  uint64(ko.Int64("gas.estimate.margin_percent")),
+		Ceilings:       ceilings,
+		DefaultCeiling: uint64(ko.Int64("gas.estimate.default_ceiling")),
+		FallbackLimit:  fallbackLimit,
+	})
+}
+
 func loadGasOracle() gas.GasOracle {
 	if gasOracle != nil {
 		return gasOracle
@@ -101,13 +190,23 @@ func loadGasOracle() gas.GasOracle {
 		gasOracle = &gas.StaticGas{}
 	case "rpc":
 		gasOracle, err = gas.NewRPCGasOracle(gas.RPCGasOracleOpts{
-			Logg:          lo,
-			ChainProvider: loadChainProvider(),
+			Logg: lo,
+			RPC:  loadRPCClient(),
 		})
 		if err != nil {
 			lo.Error("could not initialize rpc gas oracle", "error", err)
 			os.Exit(1)
 		}
+	case "fee_history":
+		gasOracle, err = gas.NewFeeHistoryGasOracle(gas.FeeHistoryGasOracleOpts{
+			Logg:       lo,
+			RPC:        loadRPCClient(),
+			BlockCount: uint64(ko.Int64("gas.fee_history.block_count")),
+		})
+		if err != nil {
+			lo.Error("could not initialize fee history gas oracle", "error", err)
+			os.Exit(1)
+		}
 	default:
 		lo.Error("unknown gas oracle type", "type", ko.MustString("gas.oracle_type"))
 		os.Exit(1)
@@ -170,6 +269,7 @@ func initSub() *sub.Sub {
 
 	subopts := sub.SubObts{
 		Provider:   loadChainProvider(),
+		RPC:        loadRPCClient(),
 		Store:      loadStore(),
 		JS:         loadJetStream(),
 		ConsumerID: ko.MustString("jetstream.id"),
@@ -196,6 +296,12 @@ func initWorker() *worker.WorkerContainer {
 		return workerContainer
 	}
 
+	rpcErrors, err := util.LoadRPCErrorClassifier(ko)
+	if err != nil {
+		lo.Error("could not load rpc error classifier", "error", err)
+		os.Exit(1)
+	}
+
 	workerOpts := worker.WorkerOpts{
 		Registry: loadRegistry(),
 		// TODO: Tune max workers based on load type
@@ -205,8 +311,17 @@ func initWorker() *worker.WorkerContainer {
 		Logg:          lo,
 		Pub:           loadPub(),
 		ChainProvider: loadChainProvider(),
+		RPC:           loadRPCClient(),
+		RPCErrors:     rpcErrors,
+		Signer:        loadSigner(),
 		EnsClient:     loadEnsClient(),
 		Prod:          ko.Bool("workers.prod"),
+		Simulate:      ko.Bool("workers.simulate"),
+
+		SystemSignerStrategy: ko.String("workers.system_signer_strategy"),
+		MaxGasFeeCap:         loadMaxGasFeeCap(),
+		Confirmations:        uint64(ko.Int64("chain.confirmations")),
+		GasLimitEstimator:    loadGasLimitEstimator(),
 	}
 
 	if ko.Int("workers.max") <= 0 {
@@ -247,10 +362,12 @@ func initAPI() *api.API {
 		VerifyingKey:  publicKey,
 		GasOracle:     loadGasOracle(),
 		Store:         loadStore(),
-		ChainProvider: loadChainProvider(),
+		RPC:           loadRPCClient(),
+		Signer:        loadSigner(),
 		QueueClient:   worker.Client(),
 		Logg:          lo,
 		Debug:         true,
 		BannedTokens:  ko.Strings("chain.banned_tokens"),
+		MaxGasFeeCap:  loadMaxGasFeeCap(),
 	})
 }
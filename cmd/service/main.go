@@ -49,12 +49,12 @@ func main() {
 		In "worker" mode, workers rely on:
 			- Postgres
 			- NATS to publish messages
-			- RPC node to dispatch transactions (chainProvider)
+			- RPC nodes to dispatch transactions (rpcClient)
 			- GasOracle
 
 		In "api" mode, the API server relies on:
 			- Postgres
-			- RPC node to fetch data (chainProvider)
+			- RPC nodes to fetch data (rpcClient)
 
 		In "sub" mode, the JetStream subscriber relies on:
 		  	- NATS to subscrib to messages and publish them
@@ -98,6 +98,14 @@ func main() {
 		}()
 	}
 
+	if rpcClient != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rpcClient.Start(ctx)
+		}()
+	}
+
 	if workerComponent != nil {
 		wg.Add(1)
 		go func() {
@@ -142,7 +150,9 @@ func main() {
 			gasOracle.Stop()
 			workerComponent.Stop(shutdownCtx)
 		}
-
+		if rpcClient != nil {
+			rpcClient.Close()
+		}
 	}()
 
 	go func() {
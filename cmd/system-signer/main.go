@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+
+	"github.com/grassrootseconomics/eth-custodial/internal/keypair"
+	"github.com/grassrootseconomics/eth-custodial/internal/store"
+	"github.com/grassrootseconomics/eth-custodial/internal/util"
+	"github.com/grassrootseconomics/ethutils"
+	"github.com/knadh/koanf/v2"
+)
+
+var (
+	confFlag       string
+	queriesFlag    string
+	migrationsFlag string
+	addFlag        bool
+	addPKCS11Flag  bool
+	enableFlag     string
+	retireFlag     string
+
+	lo *slog.Logger
+	ko *koanf.Koanf
+)
+
+func init() {
+	flag.StringVar(&confFlag, "config", "config.toml", "Config file location")
+	flag.StringVar(&queriesFlag, "queries", "queries.sql", "Queries file location")
+	flag.StringVar(&migrationsFlag, "migrations", "migrations/", "Migrations folder location")
+	flag.BoolVar(&addFlag, "add", false, "Generate a new disabled system signer and add it to the pool")
+	flag.BoolVar(&addPKCS11Flag, "add-pkcs11", false, "Add the account of the key held by the signer.pkcs11 token to the pool as a disabled system signer")
+	flag.StringVar(&enableFlag, "enable", "", "Address of the funded and allowlisted system signer to enable")
+	flag.StringVar(&retireFlag, "retire", "", "Address of the system signer to retire")
+}
+
+// system-signer manages the pool of system signers used for account registration, gas refills and contract deploys.
+// Without -add, -add-pkcs11, -enable or -retire it lists the current pool.
+// Adding a system signer:
+//  1. Run with -add, or with -add-pkcs11 for a key held by the HSM. The new signer is disabled and receives no work.
+//  2. Fund the new address and allow it on the gas faucet and the custodial registration proxy.
+//  3. Run with -enable, the signer becomes eligible for work once its balance and allowlisting are checked on chain.
+//
+// Retired signers receive no new work but keep their keys so that in flight transactions can still be retried.
+func main() {
+	flag.Parse()
+
+	lo = util.InitLogger()
+	ko = util.InitConfig(lo, confFlag)
+
+	ctx := context.Background()
+
+	if backend := ko.String("signer.backend"); !addPKCS11Flag && backend != "" && backend != "keystore" {
+		lo.Error("system signers can only be managed with the keystore signer backend", "backend", backend)
+		os.Exit(1)
+	}
+
+	keyring, err := util.LoadKeyring(ko)
+	if err != nil {
+		lo.Error("failed to load keystore keyring", "error", err)
+		os.Exit(1)
+	}
+
+	pgStore, err := store.NewPgStore(store.PgOpts{
+		Logg:                 lo,
+		DSN:                  ko.MustString("postgres.dsn"),
+		MigrationsFolderPath: migrationsFlag,
+		QueriesFolderPath:    queriesFlag,
+		Keyring:              keyring,
+	})
+	if err != nil {
+		lo.Error("failed to initialize store", "error", err)
+		os.Exit(1)
+	}
+
+	tx, err := pgStore.Pool().Begin(ctx)
+	if err != nil {
+		lo.Error("failed to begin transaction", "error", err)
+		os.Exit(1)
+	}
+	defer tx.Rollback(ctx)
+
+	switch {
+	case addFlag:
+		systemKeyPair, err := keypair.GenerateKeyPair()
+		if err != nil {
+			lo.Error("failed to generate key pair", "error", err)
+			os.Exit(1)
+		}
+
+		if err := pgStore.AddSystemSigner(ctx, tx, systemKeyPair); err != nil {
+			lo.Error("failed to add system signer", "error", err)
+			os.Exit(1)
+		}
+		lo.Info("added disabled system signer, fund it, allow it on the gas faucet and custodial registration proxy and enable it", "address", systemKeyPair.Public)
+	case addPKCS11Flag:
+		hsm, err := util.LoadPKCS11Signer(ko, nil)
+		if err != nil {
+			lo.Error("failed to open pkcs11 token", "error", err)
+			os.Exit(1)
+		}
+		if hsm == nil {
+			lo.Error("signer.pkcs11.module is not configured")
+			os.Exit(1)
+		}
+		defer hsm.Close()
+
+		if err := pgStore.AddExternalSystemSigner(ctx, tx, hsm.Address().Hex()); err != nil {
+			lo.Error("failed to add system signer", "error", err)
+			os.Exit(1)
+		}
+		lo.Info("added disabled pkcs11 system signer, fund it, allow it on the gas faucet and custodial registration proxy and enable it", "address", hsm.Address().Hex())
+	case enableFlag != "":
+		if err := util.CheckSystemSigner(ctx, ko, ethutils.HexToAddress(enableFlag)); err != nil {
+			lo.Error("system signer is not ready", "address", enableFlag, "error", err)
+			os.Exit(1)
+		}
+
+		if err := pgStore.EnableSystemSigner(ctx, tx, enableFlag); err != nil {
+			lo.Error("failed to enable system signer", "address", enableFlag, "error", err)
+			os.Exit(1)
+		}
+		lo.Info("enabled system signer", "address", enableFlag)
+	case retireFlag != "":
+		if err := pgStore.RetireSystemSigner(ctx, tx, retireFlag); err != nil {
+			lo.Error("failed to retire system signer", "address", retireFlag, "error", err)
+			os.Exit(1)
+		}
+		lo.Info("retired system signer", "address", retireFlag)
+	default:
+		systemSigners, err := pgStore.GetSystemSigners(ctx, tx)
+		if err != nil {
+			lo.Error("failed to list system signers", "error", err)
+			os.Exit(1)
+		}
+
+		for _, v := range systemSigners {
+			lo.Info("system signer", "address", v.PublicKey, "enabled", v.Enabled, "retired", v.Retired, "created_at", v.CreatedAt)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		lo.Error("failed to commit transaction", "error", err)
+		os.Exit(1)
+	}
+}
@@ -2,26 +2,27 @@ package main
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"log/slog"
 	"math/big"
-	"net"
-	"net/url"
 	"os"
+	"slices"
 	"sort"
 	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/grassrootseconomics/eth-custodial/internal/multirpc"
+	"github.com/grassrootseconomics/eth-custodial/internal/rpcerror"
+	txsigner "github.com/grassrootseconomics/eth-custodial/internal/signer"
 	"github.com/grassrootseconomics/eth-custodial/internal/store"
 	"github.com/grassrootseconomics/eth-custodial/internal/util"
+	"github.com/jackc/pgx/v5"
 	"github.com/knadh/koanf/v2"
 	"github.com/lmittmann/w3"
 	"github.com/lmittmann/w3/module/eth"
@@ -52,13 +53,20 @@ func main() {
 	lo = util.InitLogger()
 	ko = util.InitConfig(lo, confFlag)
 
-	ctx := context.Background()
+	ctx := store.WithKeyAccess(context.Background(), store.KeyAccess{Caller: "unlocker"})
+
+	keyring, err := util.LoadKeyring(ko)
+	if err != nil {
+		lo.Error("failed to load keystore keyring", "error", err)
+		os.Exit(1)
+	}
 
 	pgStore, err := store.NewPgStore(store.PgOpts{
 		Logg:                 lo,
 		DSN:                  ko.MustString("postgres.dsn"),
 		MigrationsFolderPath: migrationsFlag,
 		QueriesFolderPath:    queriesFlag,
+		Keyring:              keyring,
 	})
 	if err != nil {
 		lo.Error("failed to initialize store", "error", err)
@@ -72,24 +80,31 @@ func main() {
 	}
 	lo.Info("rpc endpoints loaded", "count", len(endpoints))
 
-	clients := make([]*w3.Client, 0, len(endpoints))
-	for _, ep := range endpoints {
-		c, err := w3.Dial(ep)
-		if err != nil {
-			lo.Warn("failed to dial RPC endpoint", "endpoint", ep, "error", err)
-			continue
-		}
-		defer c.Close()
-		clients = append(clients, c)
+	clients, err := multirpc.New(multirpc.ClientOpts{
+		Logg:      lo,
+		Endpoints: endpoints,
+	})
+	if err != nil {
+		lo.Error("could not connect to the RPC endpoints", "error", err)
+		os.Exit(1)
 	}
-	if len(clients) == 0 {
-		lo.Error("could not connect to any RPC endpoint")
+	defer clients.Close()
+
+	rpcErrors, err := util.LoadRPCErrorClassifier(ko)
+	if err != nil {
+		lo.Error("failed to load rpc error classifier", "error", err)
 		os.Exit(1)
 	}
 
 	chainID := big.NewInt(ko.MustInt64("chain.id"))
 	signer := types.LatestSignerForChainID(chainID)
 
+	txSigner, err := util.LoadSigner(ko, pgStore, signer)
+	if err != nil {
+		lo.Error("failed to initialize signer", "error", err)
+		os.Exit(1)
+	}
+
 	stuckOTXs, err := getStuckOTXs(ctx, pgStore)
 	if err != nil {
 		lo.Error("failed to get stuck OTXs", "error", err)
@@ -114,7 +129,7 @@ func main() {
 	}
 
 	for account := range affectedAccounts {
-		networkNonce, err := getNonceMultiNode(ctx, clients, common.HexToAddress(account))
+		networkNonce, err := clients.Nonce(ctx, common.HexToAddress(account), nil)
 		if err != nil {
 			lo.Error("failed to get network nonce, skipping account", "account", account, "error", err)
 			stats.skipped++
@@ -183,22 +198,29 @@ func main() {
 				continue
 			}
 
-			errType := classifyRPCError(err)
-			lo.Info("resubmit failed, classifying", "otx_id", otx.ID, "error_type", errType, "error", err)
+			class := classifyRPCError(rpcErrors, err)
+			lo.Info("resubmit failed, classifying", "otx_id", otx.ID, "status", class.Status, "error", err)
 
-			switch errType {
-			case "nonce_low":
+			switch class.Action {
+			case rpcerror.ActionInNetwork:
+				lo.Info("node already has the tx", "otx_id", otx.ID, "nonce", otx.Nonce)
+				if err := updateDispatchStatus(ctx, pgStore, otx.ID, store.IN_NETWORK); err != nil {
+					lo.Error("failed to update dispatch status", "otx_id", otx.ID, "error", err)
+				}
+				stats.resubmitted++
+
+			case rpcerror.ActionReconcileNonce:
 				if err := checkReceiptAndUpdate(ctx, clients, pgStore, otx); err != nil {
 					lo.Warn("failed to check receipt", "otx_id", otx.ID, "error", err)
 				}
 				stats.alreadyDone++
 
-			case "gas_price", "replacement_underpriced":
+			case rpcerror.ActionBumpFees:
 				lo.Info("gas-related error, attempting re-sign", "otx_id", otx.ID)
 
-				newRawTxHex, newTxHash, err := resignAndSubmit(ctx, clients, pgStore, signer, otx, account)
+				newRawTxHex, newTxHash, err := resignAndSubmit(ctx, clients, pgStore, txSigner, otx, account)
 				if err != nil {
-					if classifyRPCError(err) == "no_gas" {
+					if classifyRPCError(rpcErrors, err).Action == rpcerror.ActionRefillGas {
 						lo.Warn("re-sign requires gas top-up, skipping remaining txs", "account", account, "otx_id", otx.ID)
 						noGasAccounts[account] = struct{}{}
 						skipAccount = true
@@ -210,7 +232,7 @@ func main() {
 					continue
 				}
 
-				if err := updateOTXAndDispatch(ctx, pgStore, otx.ID, newRawTxHex, newTxHash); err != nil {
+				if err := insertReplacementAndDispatch(ctx, pgStore, otx, newRawTxHex, newTxHash); err != nil {
 					lo.Error("failed to update OTX after re-sign", "otx_id", otx.ID, "error", err)
 					stats.failed++
 					continue
@@ -218,7 +240,7 @@ func main() {
 				lo.Info("re-signed and resubmitted", "otx_id", otx.ID, "new_tx_hash", newTxHash)
 				stats.resigned++
 
-			case "no_gas":
+			case rpcerror.ActionRefillGas:
 				lo.Warn("account has insufficient gas, skipping remaining txs", "account", account)
 				noGasAccounts[account] = struct{}{}
 				skipAccount = true
@@ -260,7 +282,13 @@ func parseRPCEndpoints() []string {
 		return endpoints
 	}
 
-	return []string{ko.MustString("chain.rpc_endpoint")}
+	endpoints := []string{ko.MustString("chain.rpc_endpoint")}
+	for _, endpoint := range ko.Strings("chain.rpc_endpoints") {
+		if !slices.Contains(endpoints, endpoint) {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	return endpoints
 }
 
 func getStuckOTXs(ctx context.Context, pgStore store.Store) ([]*store.OTX, error) {
@@ -270,8 +298,9 @@ func getStuckOTXs(ctx context.Context, pgStore store.Store) ([]*store.OTX, error
 		FROM keystore
 		INNER JOIN otx ON keystore.id = otx.signer_account
 		INNER JOIN dispatch ON otx.id = dispatch.otx_id
-		WHERE dispatch.status NOT IN ('SUCCESS', 'REVERTED', 'EXTERNAL_DISPATCH')
+		WHERE dispatch.status NOT IN ('SUCCESS', 'CONFIRMED', 'REVERTED', 'EXTERNAL_DISPATCH', 'CANCELLED', 'REPLACED', 'ABORTED')
 		  AND otx.otx_type NOT IN ('GENERIC_SIGN', 'OTHER_MANUAL')
+		  AND NOT otx.replaced
 		  AND dispatch.updated_at <= NOW() - INTERVAL '5 minutes'
 		ORDER BY otx.id ASC
 		LIMIT 100`
@@ -336,6 +365,7 @@ func getOTXsFromNonce(ctx context.Context, pgStore store.Store, account string,
 		INNER JOIN dispatch ON otx.id = dispatch.otx_id
 		WHERE keystore.public_key = $1
 		  AND otx.nonce >= $2
+		  AND NOT otx.replaced
 		ORDER BY otx.nonce ASC`
 
 	rows, err := pgStore.Pool().Query(ctx, q, account, fromNonce)
@@ -360,76 +390,46 @@ func getOTXsFromNonce(ctx context.Context, pgStore store.Store, account string,
 	return otxs, rows.Err()
 }
 
-func getNonceMultiNode(ctx context.Context, clients []*w3.Client, addr common.Address) (uint64, error) {
-	var lastErr error
-	for _, c := range clients {
-		var nonce uint64
-		if err := c.CallCtx(ctx, eth.Nonce(addr, nil).Returns(&nonce)); err != nil {
-			lastErr = err
-			continue
-		}
-		return nonce, nil
-	}
-	return 0, fmt.Errorf("all nodes failed to get nonce: %w", lastErr)
-}
-
-func sendRawTxMultiNode(ctx context.Context, clients []*w3.Client, rawTx []byte) error {
-	var lastErr error
-	for _, c := range clients {
-		var txHash common.Hash
-		var callErrs w3.CallErrors
+// sendRawTxMultiNode broadcasts to every healthy node, a node's rejection is returned as the bare JSON-RPC error.
+func sendRawTxMultiNode(ctx context.Context, clients *multirpc.Client, rawTx []byte) error {
+	var callErrs w3.CallErrors
 
-		if err := c.CallCtx(ctx, eth.SendRawTx(rawTx).Returns(&txHash)); errors.As(err, &callErrs) {
-			if _, ok := callErrs[0].(rpc.Error); ok {
-				return callErrs[0]
-			}
-			return err
-		} else if err != nil {
-			if isNetworkError(err) {
-				lastErr = err
-				continue
-			}
-			return err
+	if _, err := clients.Broadcast(ctx, rawTx); errors.As(err, &callErrs) {
+		if _, ok := callErrs[0].(rpc.Error); ok {
+			return callErrs[0]
 		}
-		return nil
+		return err
+	} else if err != nil {
+		return fmt.Errorf("all nodes failed: %w", err)
 	}
-	return fmt.Errorf("all nodes failed: %w", lastErr)
+
+	return nil
 }
 
-func getGasPriceMultiNode(ctx context.Context, clients []*w3.Client) (*big.Int, *big.Int, error) {
-	var lastErr error
-	for _, c := range clients {
-		var gasPrice, tipCap *big.Int
-		if err := c.CallCtx(ctx,
-			eth.GasPrice().Returns(&gasPrice),
-			eth.GasTipCap().Returns(&tipCap),
-		); err != nil {
-			lastErr = err
-			continue
-		}
-		// Bump by 20% to accommodate fluctuations
-		bumpFactor := big.NewInt(120)
-		gasPrice.Mul(gasPrice, bumpFactor)
-		gasPrice.Div(gasPrice, big.NewInt(100))
-		return gasPrice, tipCap, nil
+func getGasPriceMultiNode(ctx context.Context, clients *multirpc.Client) (*big.Int, *big.Int, error) {
+	var gasPrice, tipCap *big.Int
+	if err := clients.CallCtx(ctx,
+		eth.GasPrice().Returns(&gasPrice),
+		eth.GasTipCap().Returns(&tipCap),
+	); err != nil {
+		return nil, nil, fmt.Errorf("all nodes failed to get gas price: %w", err)
 	}
-	return nil, nil, fmt.Errorf("all nodes failed to get gas price: %w", lastErr)
+	// Bump by 20% to accommodate fluctuations
+	bumpFactor := big.NewInt(120)
+	gasPrice.Mul(gasPrice, bumpFactor)
+	gasPrice.Div(gasPrice, big.NewInt(100))
+	return gasPrice, tipCap, nil
 }
 
-func getReceiptMultiNode(ctx context.Context, clients []*w3.Client, txHash common.Hash) (*types.Receipt, error) {
-	var lastErr error
-	for _, c := range clients {
-		var receipt *types.Receipt
-		if err := c.CallCtx(ctx, eth.TxReceipt(txHash).Returns(&receipt)); err != nil {
-			lastErr = err
-			continue
-		}
-		return receipt, nil
+func getReceiptMultiNode(ctx context.Context, clients *multirpc.Client, txHash common.Hash) (*types.Receipt, error) {
+	var receipt *types.Receipt
+	if err := clients.CallCtx(ctx, eth.TxReceipt(txHash).Returns(&receipt)); err != nil {
+		return nil, fmt.Errorf("all nodes failed to get receipt: %w", err)
 	}
-	return nil, fmt.Errorf("all nodes failed to get receipt: %w", lastErr)
+	return receipt, nil
 }
 
-func checkReceiptAndUpdate(ctx context.Context, clients []*w3.Client, pgStore store.Store, otx *store.OTX) error {
+func checkReceiptAndUpdate(ctx context.Context, clients *multirpc.Client, pgStore store.Store, otx *store.OTX) error {
 	receipt, err := getReceiptMultiNode(ctx, clients, common.HexToHash(otx.TxHash))
 	if err != nil {
 		return err
@@ -445,9 +445,9 @@ func checkReceiptAndUpdate(ctx context.Context, clients []*w3.Client, pgStore st
 
 func resignAndSubmit(
 	ctx context.Context,
-	clients []*w3.Client,
+	clients *multirpc.Client,
 	pgStore store.Store,
-	chainSigner types.Signer,
+	txSigner txsigner.Signer,
 	otx *store.OTX,
 	account string,
 ) (string, string, error) {
@@ -484,25 +484,15 @@ func resignAndSubmit(
 	}
 	defer dbTx.Rollback(ctx)
 
-	keypair, err := pgStore.LoadPrivateKey(ctx, dbTx, account)
-	if err != nil {
-		return "", "", fmt.Errorf("load private key: %w", err)
-	}
-
-	privateKey, err := crypto.HexToECDSA(keypair.Private)
+	newRawTxHex, newTxHash, err := resignTx(ctx, dbTx, originalTx, txSigner, common.HexToAddress(account), newGasFeeCap, newGasTipCap)
 	if err != nil {
-		return "", "", fmt.Errorf("parse private key: %w", err)
+		return "", "", fmt.Errorf("re-sign: %w", err)
 	}
 
 	if err := dbTx.Commit(ctx); err != nil {
 		return "", "", fmt.Errorf("commit read tx: %w", err)
 	}
 
-	newRawTxHex, newTxHash, err := resignTx(originalTx, privateKey, chainSigner, newGasFeeCap, newGasTipCap)
-	if err != nil {
-		return "", "", fmt.Errorf("re-sign: %w", err)
-	}
-
 	newRawTxBytes, err := hexutil.Decode(newRawTxHex)
 	if err != nil {
 		return "", "", fmt.Errorf("decode new raw tx: %w", err)
@@ -516,9 +506,11 @@ func resignAndSubmit(
 }
 
 func resignTx(
+	ctx context.Context,
+	dbTx pgx.Tx,
 	originalTx *types.Transaction,
-	privateKey *ecdsa.PrivateKey,
-	signer types.Signer,
+	txSigner txsigner.Signer,
+	account common.Address,
 	newGasFeeCap *big.Int,
 	newGasTipCap *big.Int,
 ) (string, string, error) {
@@ -527,7 +519,7 @@ func resignTx(
 		return "", "", fmt.Errorf("original tx has no recipient (contract creation)")
 	}
 
-	newTx, err := types.SignNewTx(privateKey, signer, &types.DynamicFeeTx{
+	newTx, err := txSigner.SignTx(ctx, dbTx, account, types.NewTx(&types.DynamicFeeTx{
 		Nonce:     originalTx.Nonce(),
 		To:        to,
 		Value:     originalTx.Value(),
@@ -535,7 +527,7 @@ func resignTx(
 		Gas:       originalTx.Gas(),
 		GasFeeCap: newGasFeeCap,
 		GasTipCap: newGasTipCap,
-	})
+	}))
 	if err != nil {
 		return "", "", fmt.Errorf("sign new tx: %w", err)
 	}
@@ -565,22 +557,33 @@ func updateDispatchStatus(ctx context.Context, pgStore store.Store, otxID uint64
 	return dbTx.Commit(ctx)
 }
 
-func updateOTXAndDispatch(ctx context.Context, pgStore store.Store, otxID uint64, newRawTxHex string, newTxHash string) error {
+// insertReplacementAndDispatch stores the re-signed tx as a new OTX linked to the original, which is kept as history.
+func insertReplacementAndDispatch(ctx context.Context, pgStore store.Store, otx *store.OTX, newRawTxHex string, newTxHash string) error {
 	dbTx, err := pgStore.Pool().Begin(ctx)
 	if err != nil {
 		return err
 	}
 	defer dbTx.Rollback(ctx)
 
-	if _, err := dbTx.Exec(ctx,
-		`UPDATE otx SET raw_tx = $1, tx_hash = $2 WHERE id = $3`,
-		newRawTxHex, newTxHash, otxID,
-	); err != nil {
+	newOTXID, err := pgStore.InsertOTX(ctx, dbTx, store.OTX{
+		TrackingID:    otx.TrackingID,
+		OTXType:       otx.OTXType,
+		SignerAccount: otx.SignerAccount,
+		RawTx:         newRawTxHex,
+		TxHash:        newTxHash,
+		Nonce:         otx.Nonce,
+		ReplacesOTXID: &otx.ID,
+	})
+	if err != nil {
 		return err
 	}
 
-	if err := pgStore.UpdateDispatchTxStatus(ctx, dbTx, store.DispatchTx{
-		OTXID:  otxID,
+	if err := pgStore.MarkOTXReplaced(ctx, dbTx, otx.ID); err != nil {
+		return err
+	}
+
+	if err := pgStore.InsertDispatchTx(ctx, dbTx, store.DispatchTx{
+		OTXID:  newOTXID,
 		Status: store.IN_NETWORK,
 	}); err != nil {
 		return err
@@ -589,38 +592,16 @@ func updateOTXAndDispatch(ctx context.Context, pgStore store.Store, otxID uint64
 	return dbTx.Commit(ctx)
 }
 
-func classifyRPCError(err error) string {
-	if err == nil {
-		return ""
-	}
-
-	msg := err.Error()
-	switch {
-	case strings.Contains(msg, "nonce too low"):
-		return "nonce_low"
-	case strings.Contains(msg, "replacement transaction underpriced"):
-		return "replacement_underpriced"
-	case strings.Contains(msg, "transaction underpriced"), strings.Contains(msg, "gas fee cap is below the minimum base fee"):
-		return "gas_price"
-	case strings.Contains(msg, "insufficient funds for gas"):
-		return "no_gas"
-	case isNetworkError(err):
-		return "network"
-	default:
-		return "unknown"
+// classifyRPCError maps a failed broadcast with the classifier of the service, errors that never reached a node are
+// network errors.
+func classifyRPCError(rpcErrors *rpcerror.Classifier, err error) rpcerror.Class {
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		return rpcErrors.Classify(rpcErr.Error())
 	}
-}
-
-func isNetworkError(err error) bool {
-	if err == nil {
-		return false
+	if rpcerror.IsNetworkError(err) {
+		return rpcerror.Network
 	}
 
-	var netErr net.Error
-	var urlErr *url.Error
-
-	return errors.As(err, &netErr) ||
-		errors.As(err, &urlErr) ||
-		strings.Contains(err.Error(), "timeout") ||
-		errors.Is(err, context.DeadlineExceeded)
+	return rpcErrors.Classify(err.Error())
 }
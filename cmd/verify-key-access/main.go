@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+
+	"github.com/grassrootseconomics/eth-custodial/internal/store"
+	"github.com/grassrootseconomics/eth-custodial/internal/util"
+	"github.com/knadh/koanf/v2"
+)
+
+var (
+	confFlag       string
+	queriesFlag    string
+	migrationsFlag string
+	batchSizeFlag  int
+
+	lo *slog.Logger
+	ko *koanf.Koanf
+)
+
+func init() {
+	flag.StringVar(&confFlag, "config", "config.toml", "Config file location")
+	flag.StringVar(&queriesFlag, "queries", "queries.sql", "Queries file location")
+	flag.StringVar(&migrationsFlag, "migrations", "migrations/", "Migrations folder location")
+	flag.IntVar(&batchSizeFlag, "batch-size", 1000, "Number of log entries to verify per query")
+}
+
+// verify-key-access walks the sealed key access log in chain order and recomputes every hash. It exits with a non zero
+// status on the first gap, broken link or modified entry. Entries that are not yet sealed are not covered.
+func main() {
+	flag.Parse()
+
+	lo = util.InitLogger()
+	ko = util.InitConfig(lo, confFlag)
+
+	ctx := context.Background()
+
+	pgStore, err := store.NewPgStore(store.PgOpts{
+		Logg:                 lo,
+		DSN:                  ko.MustString("postgres.dsn"),
+		MigrationsFolderPath: migrationsFlag,
+		QueriesFolderPath:    queriesFlag,
+	})
+	if err != nil {
+		lo.Error("failed to initialize store", "error", err)
+		os.Exit(1)
+	}
+
+	var (
+		seq      uint64
+		prevHash string
+	)
+	for {
+		batch, err := loadBatch(ctx, pgStore, seq)
+		if err != nil {
+			lo.Error("failed to load key access log", "after_seq", seq, "error", err)
+			os.Exit(1)
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		for _, v := range batch {
+			seq++
+
+			switch {
+			case *v.Seq != seq:
+				lo.Error("key access log chain has a gap", "expected_seq", seq, "seq", *v.Seq, "id", v.ID)
+				os.Exit(1)
+			case *v.PrevHash != prevHash:
+				lo.Error("key access log chain link is broken", "seq", seq, "id", v.ID)
+				os.Exit(1)
+			case v.ChainHash(seq, prevHash) != *v.Hash:
+				lo.Error("key access log entry was modified", "seq", seq, "id", v.ID)
+				os.Exit(1)
+			}
+
+			prevHash = *v.Hash
+		}
+	}
+
+	lo.Info("key access log chain verified", "entries", seq, "head", prevHash)
+}
+
+func loadBatch(ctx context.Context, pgStore store.Store, afterSeq uint64) ([]*store.KeyAccessLog, error) {
+	tx, err := pgStore.Pool().Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	return pgStore.GetSealedKeyAccessLog(ctx, tx, afterSeq, batchSizeFlag)
+}
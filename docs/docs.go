@@ -6,10 +6,10 @@ import "github.com/swaggo/swag/v2"
 
 const docTemplate = `{
     "schemes": {{ marshal .Schemes }},
-    "components": {"schemas":{"api.DemurrageERC20DeployRequest":{"properties":{"decimals":{"type":"integer"},"demurragePeriod":{"type":"string"},"demurrageRate":{"type":"string"},"initialMintee":{"type":"string"},"initialSupply":{"type":"string"},"name":{"type":"string"},"owner":{"type":"string"},"sinkAddress":{"type":"string"},"symbol":{"type":"string"}},"required":["decimals","demurragePeriod","demurrageRate","initialMintee","initialSupply","name","owner","sinkAddress","symbol"],"type":"object"},"api.ERC20DeployRequest":{"properties":{"decimals":{"type":"integer"},"expiryTimestamp":{"type":"string"},"initialMintee":{"type":"string"},"initialSupply":{"type":"string"},"name":{"type":"string"},"owner":{"type":"string"},"symbol":{"type":"string"}},"required":["decimals","initialMintee","initialSupply","name","owner","symbol"],"type":"object"},"api.ErrResponse":{"properties":{"description":{"type":"string"},"errorCode":{"type":"string"},"ok":{"type":"boolean"}},"type":"object"},"api.OKResponse":{"properties":{"description":{"type":"string"},"ok":{"type":"boolean"},"result":{"additionalProperties":{},"type":"object"}},"type":"object"},"api.PoolDeployRequest":{"properties":{"name":{"type":"string"},"owner":{"type":"string"},"symbol":{"type":"string"}},"required":["name","owner","symbol"],"type":"object"},"api.PoolDepositRequest":{"properties":{"amount":{"type":"string"},"from":{"type":"string"},"poolAddress":{"type":"string"},"tokenAddress":{"type":"string"}},"required":["amount","from","poolAddress","tokenAddress"],"type":"object"},"api.PoolSwapRequest":{"properties":{"amount":{"type":"string"},"from":{"type":"string"},"fromTokenAddress":{"type":"string"},"poolAddress":{"type":"string"},"toTokenAddress":{"type":"string"}},"required":["amount","from","fromTokenAddress","poolAddress","toTokenAddress"],"type":"object"},"api.SweepRequest":{"properties":{"from":{"type":"string"},"to":{"type":"string"},"tokenAddress":{"type":"string"}},"required":["from","to","tokenAddress"],"type":"object"},"api.TransferRequest":{"properties":{"amount":{"type":"string"},"from":{"type":"string"},"to":{"type":"string"},"tokenAddress":{"type":"string"}},"required":["amount","from","to","tokenAddress"],"type":"object"}},"securitySchemes":{"":{"description":"Service API Token","in":"header","name":"Authorization","type":"apiKey"}}},
+    "components": {"schemas":{"api.AccountExportRequest":{"properties":{"address":{"type":"string"},"freeze":{"type":"boolean"},"password":{"minLength":8,"type":"string"}},"required":["address","password"],"type":"object"},"api.AccountImportRequest":{"properties":{"privateKey":{"type":"string"}},"required":["privateKey"],"type":"object"},"api.AccountStatusUpdateRequest":{"properties":{"address":{"type":"string"},"reason":{"type":"string"},"status":{"enum":["ACTIVE","FROZEN","CLOSED"],"type":"string"}},"required":["address","reason","status"],"type":"object"},"api.DemurrageERC20DeployRequest":{"properties":{"decimals":{"type":"integer"},"demurragePeriod":{"type":"string"},"demurrageRate":{"type":"string"},"feeClass":{"enum":["economy","normal","urgent"],"type":"string"},"initialMintee":{"type":"string"},"initialSupply":{"type":"string"},"name":{"type":"string"},"owner":{"type":"string"},"sinkAddress":{"type":"string"},"symbol":{"type":"string"}},"required":["decimals","demurragePeriod","demurrageRate","initialMintee","initialSupply","name","owner","sinkAddress","symbol"],"type":"object"},"api.ERC20DeployRequest":{"properties":{"decimals":{"type":"integer"},"expiryTimestamp":{"type":"string"},"feeClass":{"enum":["economy","normal","urgent"],"type":"string"},"initialMintee":{"type":"string"},"initialSupply":{"type":"string"},"name":{"type":"string"},"owner":{"type":"string"},"symbol":{"type":"string"}},"required":["decimals","initialMintee","initialSupply","name","owner","symbol"],"type":"object"},"api.ErrResponse":{"properties":{"description":{"type":"string"},"errorCode":{"type":"string"},"ok":{"type":"boolean"}},"type":"object"},"api.OKResponse":{"properties":{"description":{"type":"string"},"ok":{"type":"boolean"},"result":{"additionalProperties":{},"type":"object"}},"type":"object"},"api.PoolDeployRequest":{"properties":{"feeClass":{"enum":["economy","normal","urgent"],"type":"string"},"name":{"type":"string"},"owner":{"type":"string"},"symbol":{"type":"string"}},"required":["name","owner","symbol"],"type":"object"},"api.PoolDepositRequest":{"properties":{"amount":{"type":"string"},"feeClass":{"enum":["economy","normal","urgent"],"type":"string"},"from":{"type":"string"},"poolAddress":{"type":"string"},"tokenAddress":{"type":"string"}},"required":["amount","from","poolAddress","tokenAddress"],"type":"object"},"api.PoolSwapRequest":{"properties":{"amount":{"type":"string"},"feeClass":{"enum":["economy","normal","urgent"],"type":"string"},"from":{"type":"string"},"fromTokenAddress":{"type":"string"},"poolAddress":{"type":"string"},"toTokenAddress":{"type":"string"}},"required":["amount","from","fromTokenAddress","poolAddress","toTokenAddress"],"type":"object"},"api.SpeedUpRequest":{"properties":{"gasFeeCap":{"type":"string"},"gasTipCap":{"type":"string"},"multiplier":{"maximum":10,"type":"number"},"trackingID":{"type":"string"}},"required":["trackingID"],"type":"object"},"api.SweepRequest":{"properties":{"feeClass":{"enum":["economy","normal","urgent"],"type":"string"},"from":{"type":"string"},"to":{"type":"string"},"tokenAddress":{"type":"string"}},"required":["from","to","tokenAddress"],"type":"object"},"api.TransferRequest":{"properties":{"amount":{"type":"string"},"feeClass":{"enum":["economy","normal","urgent"],"type":"string"},"from":{"type":"string"},"to":{"type":"string"},"tokenAddress":{"type":"string"}},"required":["amount","from","to","tokenAddress"],"type":"object"}},"securitySchemes":{"":{"description":"Service API Token","in":"header","name":"Authorization","type":"apiKey"}}},
     "info": {"contact":{"email":"devops@grassecon.org","name":"API Support","url":"https://grassecon.org/pages/contact-us"},"description":"{{escape .Description}}","license":{"name":"AGPL-3.0","url":"https://www.gnu.org/licenses/agpl-3.0.en.html"},"termsOfService":"https://grassecon.org/pages/terms-and-conditions.html","title":"{{.Title}}","version":"{{.Version}}"},
     "externalDocs": {"description":"","url":""},
-    "paths": {"/account/create":{"post":{"description":"Create a new custodial account","requestBody":{"content":{"*/*":{"schema":{"type":"string"}}}},"responses":{"200":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.OKResponse"}}},"description":"OK"},"500":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Internal Server Error"}},"security":[{"ApiKeyAuth":[]}],"summary":"Create a new custodial account","tags":["Account"]}},"/account/otx/{address}":{"get":{"description":"Get an accounts OTX's (Origin transaction)","parameters":[{"description":"Account","in":"path","name":"address","required":true,"schema":{"type":"string"}},{"description":"Next","in":"query","name":"next","schema":{"type":"boolean"}},{"description":"Cursor","in":"query","name":"cursor","schema":{"type":"integer"}},{"description":"Per page","in":"query","name":"perPage","required":true,"schema":{"type":"integer"}}],"requestBody":{"content":{"*/*":{"schema":{"type":"string"}}}},"responses":{"200":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.OKResponse"}}},"description":"OK"},"500":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Internal Server Error"}},"security":[{"ApiKeyAuth":[]}],"summary":"Get an accounts OTX's (Origin transaction)","tags":["Account"]}},"/account/status/{address}":{"get":{"description":"Check a custodial account's status","parameters":[{"description":"Account address","in":"path","name":"address","required":true,"schema":{"type":"string"}}],"requestBody":{"content":{"*/*":{"schema":{"type":"string"}}}},"responses":{"200":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.OKResponse"}}},"description":"OK"},"500":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Internal Server Error"}},"security":[{"ApiKeyAuth":[]}],"summary":"Check a custodial account's status","tags":["Account"]}},"/contracts/erc20":{"post":{"description":"ERC20 deploy request","requestBody":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ERC20DeployRequest"}}},"description":"ERC20 deploy request","required":true},"responses":{"200":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.OKResponse"}}},"description":"OK"},"400":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Bad Request"},"500":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Internal Server Error"}},"security":[{"ApiKeyAuth":[]}],"summary":"ERC20 deploy request","tags":["Contracts"]}},"/contracts/erc20-demurrage":{"post":{"description":"Demurrage ERC20 deploy request","requestBody":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.DemurrageERC20DeployRequest"}}},"description":"Demurrage ERC20 deploy request","required":true},"responses":{"200":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.OKResponse"}}},"description":"OK"},"400":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Bad Request"},"500":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Internal Server Error"}},"security":[{"ApiKeyAuth":[]}],"summary":"Demurrage ERC20 deploy request","tags":["Contracts"]}},"/contracts/pool":{"post":{"description":"Pool deploy request","requestBody":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.PoolDeployRequest"}}},"description":"Pool deploy request","required":true},"responses":{"200":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.OKResponse"}}},"description":"OK"},"400":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Bad Request"},"500":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Internal Server Error"}},"security":[{"ApiKeyAuth":[]}],"summary":"Pool deploy request","tags":["Contracts"]}},"/otx/track/{trackingId}":{"get":{"description":"Track an OTX's (Origin transaction) chain status","parameters":[{"description":"Tracking ID","in":"path","name":"trackingId","required":true,"schema":{"type":"string"}}],"requestBody":{"content":{"*/*":{"schema":{"type":"string"}}}},"responses":{"200":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.OKResponse"}}},"description":"OK"},"500":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Internal Server Error"}},"security":[{"ApiKeyAuth":[]}],"summary":"Track an OTX's (Origin transaction) chain status","tags":["OTX"]}},"/pool/deposit":{"post":{"description":"Pool deposit request","requestBody":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.PoolDepositRequest"}}},"description":"Pool deposit request","required":true},"responses":{"200":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.OKResponse"}}},"description":"OK"},"400":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Bad Request"},"500":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Internal Server Error"}},"security":[{"ApiKeyAuth":[]}],"summary":"Pool deposit request","tags":["Sign"]}},"/pool/quote":{"post":{"description":"Get a pool swap quote","requestBody":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.PoolSwapRequest"}}},"description":"Get a pool swap quote","required":true},"responses":{"200":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.OKResponse"}}},"description":"OK"},"400":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Bad Request"},"500":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Internal Server Error"}},"security":[{"ApiKeyAuth":[]}],"summary":"Get a pool swap quote","tags":["Sign"]}},"/pool/swap":{"post":{"description":"Pool swap request","requestBody":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.PoolSwapRequest"}}},"description":"Pool swap request","required":true},"responses":{"200":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.OKResponse"}}},"description":"OK"},"400":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Bad Request"},"500":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Internal Server Error"}},"security":[{"ApiKeyAuth":[]}],"summary":"Pool swap request","tags":["Sign"]}},"/system":{"get":{"description":"Get the current system information","responses":{"200":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.OKResponse"}}},"description":"OK"},"500":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Internal Server Error"}},"security":[{"ApiKeyAuth":[]}],"summary":"Get the current system information","tags":["System"]}},"/token/sweep":{"post":{"description":"Sign a token sweep request","requestBody":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.SweepRequest"}}},"description":"Sweep request","required":true},"responses":{"200":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.OKResponse"}}},"description":"OK"},"400":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Bad Request"},"500":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Internal Server Error"}},"security":[{"ApiKeyAuth":[]}],"summary":"Sign a token sweep request","tags":["Sign"]}},"/token/transfer":{"post":{"description":"Sign a token transfer request","requestBody":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.TransferRequest"}}},"description":"Transfer request","required":true},"responses":{"200":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.OKResponse"}}},"description":"OK"},"400":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Bad Request"},"500":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Internal Server Error"}},"security":[{"ApiKeyAuth":[]}],"summary":"Sign a token transfer request","tags":["Sign"]}}},
+    "paths": {"/account/create":{"post":{"description":"Create a new custodial account","requestBody":{"content":{"*/*":{"schema":{"type":"string"}}}},"responses":{"200":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.OKResponse"}}},"description":"OK"},"500":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Internal Server Error"}},"security":[{"ApiKeyAuth":[]}],"summary":"Create a new custodial account","tags":["Account"]}},"/account/export":{"post":{"description":"Export a custodial account's private key as a password encrypted Web3 Secret Storage (keystore v3) JSON. Every export is recorded and the account can optionally be frozen.","requestBody":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.AccountExportRequest"}}},"description":"Account export request","required":true},"responses":{"200":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.OKResponse"}}},"description":"OK"},"400":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Bad Request"},"403":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Forbidden"},"404":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Not Found"},"500":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Internal Server Error"}},"security":[{"ApiKeyAuth":[]}],"summary":"Export a custodial account's private key","tags":["Account"]}},"/account/import":{"post":{"description":"Import an existing private key as a custodial account. The account is registered through the custodial registration proxy.","requestBody":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.AccountImportRequest"}}},"description":"Account import request","required":true},"responses":{"200":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.OKResponse"}}},"description":"OK"},"400":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Bad Request"},"403":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Forbidden"},"409":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Conflict"},"500":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Internal Server Error"}},"security":[{"ApiKeyAuth":[]}],"summary":"Import an existing private key as a custodial account","tags":["Account"]}},"/account/key-access/{address}":{"get":{"description":"Get the hash chained private key access log of a custodial account. Recent entries are unsealed until the next chain sealing run.","parameters":[{"description":"Account address","in":"path","name":"address","required":true,"schema":{"type":"string"}},{"description":"Next","in":"query","name":"next","schema":{"type":"boolean"}},{"description":"Cursor","in":"query","name":"cursor","schema":{"type":"integer"}},{"description":"Per page","in":"query","name":"perPage","required":true,"schema":{"type":"integer"}}],"requestBody":{"content":{"*/*":{"schema":{"type":"string"}}}},"responses":{"200":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.OKResponse"}}},"description":"OK"},"403":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Forbidden"},"500":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Internal Server Error"}},"security":[{"ApiKeyAuth":[]}],"summary":"Get the private key access log of a custodial account","tags":["Account"]}},"/account/otx/{address}":{"get":{"description":"Get an accounts OTX's (Origin transaction)","parameters":[{"description":"Account","in":"path","name":"address","required":true,"schema":{"type":"string"}},{"description":"Next","in":"query","name":"next","schema":{"type":"boolean"}},{"description":"Cursor","in":"query","name":"cursor","schema":{"type":"integer"}},{"description":"Per page","in":"query","name":"perPage","required":true,"schema":{"type":"integer"}}],"requestBody":{"content":{"*/*":{"schema":{"type":"string"}}}},"responses":{"200":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.OKResponse"}}},"description":"OK"},"500":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Internal Server Error"}},"security":[{"ApiKeyAuth":[]}],"summary":"Get an accounts OTX's (Origin transaction)","tags":["Account"]}},"/account/status/{address}":{"get":{"description":"Check a custodial account's status","parameters":[{"description":"Account address","in":"path","name":"address","required":true,"schema":{"type":"string"}}],"requestBody":{"content":{"*/*":{"schema":{"type":"string"}}}},"responses":{"200":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.OKResponse"}}},"description":"OK"},"500":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Internal Server Error"}},"security":[{"ApiKeyAuth":[]}],"summary":"Check a custodial account's status","tags":["Account"]},"put":{"description":"Freeze, unfreeze or close a custodial account. Queued work of frozen or closed accounts is cancelled.","parameters":[{"description":"Account address","in":"path","name":"address","required":true,"schema":{"type":"string"}}],"requestBody":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.AccountStatusUpdateRequest"}}},"description":"Account status update request","required":true},"responses":{"200":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.OKResponse"}}},"description":"OK"},"400":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Bad Request"},"403":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Forbidden"},"404":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Not Found"},"500":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Internal Server Error"}},"security":[{"ApiKeyAuth":[]}],"summary":"Change a custodial account's lifecycle status","tags":["Account"]}},"/account/status/{address}/history":{"get":{"description":"Get a custodial account's lifecycle status history, latest first","parameters":[{"description":"Account address","in":"path","name":"address","required":true,"schema":{"type":"string"}}],"requestBody":{"content":{"*/*":{"schema":{"type":"string"}}}},"responses":{"200":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.OKResponse"}}},"description":"OK"},"403":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Forbidden"},"500":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Internal Server Error"}},"security":[{"ApiKeyAuth":[]}],"summary":"Get a custodial account's lifecycle status history","tags":["Account"]}},"/contracts/erc20":{"post":{"description":"ERC20 deploy request","requestBody":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ERC20DeployRequest"}}},"description":"ERC20 deploy request","required":true},"responses":{"200":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.OKResponse"}}},"description":"OK"},"400":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Bad Request"},"500":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Internal Server Error"}},"security":[{"ApiKeyAuth":[]}],"summary":"ERC20 deploy request","tags":["Contracts"]}},"/contracts/erc20-demurrage":{"post":{"description":"Demurrage ERC20 deploy request","requestBody":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.DemurrageERC20DeployRequest"}}},"description":"Demurrage ERC20 deploy request","required":true},"responses":{"200":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.OKResponse"}}},"description":"OK"},"400":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Bad Request"},"500":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Internal Server Error"}},"security":[{"ApiKeyAuth":[]}],"summary":"Demurrage ERC20 deploy request","tags":["Contracts"]}},"/contracts/pool":{"post":{"description":"Pool deploy request","requestBody":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.PoolDeployRequest"}}},"description":"Pool deploy request","required":true},"responses":{"200":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.OKResponse"}}},"description":"OK"},"400":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Bad Request"},"500":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Internal Server Error"}},"security":[{"ApiKeyAuth":[]}],"summary":"Pool deploy request","tags":["Contracts"]}},"/otx/cancel/{trackingId}":{"post":{"description":"Replace every OTX of the tracking ID that is not yet final with a zero value transfer at the same nonce and a bumped fee. The original becomes CANCELLED if the replacement is mined, otherwise it keeps its own status.","parameters":[{"description":"Tracking ID","in":"path","name":"trackingId","required":true,"schema":{"type":"string"}}],"requestBody":{"content":{"*/*":{"schema":{"type":"string"}}}},"responses":{"200":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.OKResponse"}}},"description":"OK"},"404":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Not Found"},"409":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Conflict"},"500":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Internal Server Error"}},"security":[{"ApiKeyAuth":[]}],"summary":"Cancel an OTX (Origin transaction) that is not yet mined","tags":["OTX"]}},"/otx/speedup/{trackingId}":{"post":{"description":"Re-sign every OTX of the tracking ID that is not yet final at the same nonce with higher fees and rebroadcast it. Both the original and the replacement are kept and whichever is mined resolves the OTX.","parameters":[{"description":"Tracking ID","in":"path","name":"trackingId","required":true,"schema":{"type":"string"}}],"requestBody":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.SpeedUpRequest"}}},"description":"Fee bump"},"responses":{"200":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.OKResponse"}}},"description":"OK"},"400":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Bad Request"},"404":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Not Found"},"409":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Conflict"},"500":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Internal Server Error"}},"security":[{"ApiKeyAuth":[]}],"summary":"Speed up an OTX (Origin transaction) that is not yet mined","tags":["OTX"]}},"/otx/track/{trackingId}":{"get":{"description":"Track an OTX's (Origin transaction) chain status. Each nonce reports its effective transaction, replaced\ntransactions are returned as history along with the timeline of every broadcast attempt and any\nsimulation that reverted before signing","parameters":[{"description":"Tracking ID","in":"path","name":"trackingId","required":true,"schema":{"type":"string"}}],"requestBody":{"content":{"*/*":{"schema":{"type":"string"}}}},"responses":{"200":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.OKResponse"}}},"description":"OK"},"500":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Internal Server Error"}},"security":[{"ApiKeyAuth":[]}],"summary":"Track an OTX's (Origin transaction) chain status","tags":["OTX"]}},"/pool/deposit":{"post":{"description":"Pool deposit request","requestBody":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.PoolDepositRequest"}}},"description":"Pool deposit request","required":true},"responses":{"200":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.OKResponse"}}},"description":"OK"},"400":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Bad Request"},"500":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Internal Server Error"}},"security":[{"ApiKeyAuth":[]}],"summary":"Pool deposit request","tags":["Sign"]}},"/pool/quote":{"post":{"description":"Get a pool swap quote","requestBody":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.PoolSwapRequest"}}},"description":"Get a pool swap quote","required":true},"responses":{"200":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.OKResponse"}}},"description":"OK"},"400":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Bad Request"},"500":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Internal Server Error"}},"security":[{"ApiKeyAuth":[]}],"summary":"Get a pool swap quote","tags":["Sign"]}},"/pool/swap":{"post":{"description":"Pool swap request","requestBody":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.PoolSwapRequest"}}},"description":"Pool swap request","required":true},"responses":{"200":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.OKResponse"}}},"description":"OK"},"400":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Bad Request"},"500":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Internal Server Error"}},"security":[{"ApiKeyAuth":[]}],"summary":"Pool swap request","tags":["Sign"]}},"/system":{"get":{"description":"Get the current system information","responses":{"200":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.OKResponse"}}},"description":"OK"},"500":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Internal Server Error"}},"security":[{"ApiKeyAuth":[]}],"summary":"Get the current system information","tags":["System"]}},"/system/fees":{"get":{"description":"Get the native gas fees of mined OTXs per signer account, JWT subject and OTX type in a time range","parameters":[{"description":"From (unix seconds)","in":"query","name":"from","required":true,"schema":{"type":"integer"}},{"description":"To (unix seconds), defaults to now","in":"query","name":"to","schema":{"type":"integer"}}],"responses":{"200":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.OKResponse"}}},"description":"OK"},"400":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Bad Request"},"403":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Forbidden"},"500":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Internal Server Error"}},"security":[{"ApiKeyAuth":[]}],"summary":"Get native gas fees spent","tags":["System"]}},"/system/nonce-gaps":{"get":{"description":"Get the latest nonce gaps found by the periodic nonce check and how each was repaired","responses":{"200":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.OKResponse"}}},"description":"OK"},"403":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Forbidden"},"500":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Internal Server Error"}},"security":[{"ApiKeyAuth":[]}],"summary":"Get nonce gap findings","tags":["System"]}},"/token/sweep":{"post":{"description":"Sign a token sweep request","requestBody":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.SweepRequest"}}},"description":"Sweep request","required":true},"responses":{"200":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.OKResponse"}}},"description":"OK"},"400":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Bad Request"},"500":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Internal Server Error"}},"security":[{"ApiKeyAuth":[]}],"summary":"Sign a token sweep request","tags":["Sign"]}},"/token/transfer":{"post":{"description":"Sign a token transfer request","requestBody":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.TransferRequest"}}},"description":"Transfer request","required":true},"responses":{"200":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.OKResponse"}}},"description":"OK"},"400":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Bad Request"},"500":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/api.ErrResponse"}}},"description":"Internal Server Error"}},"security":[{"ApiKeyAuth":[]}],"summary":"Sign a token transfer request","tags":["Sign"]}}},
     "openapi": "3.1.0"
 }`
 
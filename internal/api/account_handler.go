@@ -1,16 +1,27 @@
 package api
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"math/big"
 	"net/http"
+	"strings"
 
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/google/uuid"
 	"github.com/grassrootseconomics/eth-custodial/internal/keypair"
+	"github.com/grassrootseconomics/eth-custodial/internal/store"
 	"github.com/grassrootseconomics/eth-custodial/internal/worker"
 	apiresp "github.com/grassrootseconomics/eth-custodial/pkg/api"
 	"github.com/grassrootseconomics/ethutils"
+	"github.com/jackc/pgx/v5"
 	"github.com/labstack/echo/v4"
 	"github.com/lmittmann/w3/module/eth"
+	"github.com/riverqueue/river"
 )
 
 // accountCreateHandler godoc
@@ -25,31 +36,244 @@ import (
 //	@Security		ApiKeyAuth
 //	@Router			/account/create [post]
 func (a *API) accountCreateHandler(c echo.Context) error {
-	generatedKeyPair, err := keypair.GenerateKeyPair()
+	trackingID := uuid.NewString()
+
+	tx, err := a.store.Pool().Begin(c.Request().Context())
+	if err != nil {
+		return handlePostgresError(c, err)
+	}
+	defer tx.Rollback(c.Request().Context())
+
+	// The key is saved (inactive) alongside the job so that the private key never enters the queue's job args.
+	publicKey, err := a.store.CreateKeyPair(c.Request().Context(), tx)
+	if err != nil {
+		return handlePostgresError(c, err)
+	}
+
+	_, err = a.queueClient.InsertTx(c.Request().Context(), tx, worker.AccountCreateArgs{
+		TrackingID: trackingID,
+		PublicKey:  publicKey,
+	}, nil)
+	if err != nil {
+		return handlePostgresError(c, err)
+	}
+
+	if err := tx.Commit(c.Request().Context()); err != nil {
+		return handlePostgresError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, apiresp.OKResponse{
+		Ok:          true,
+		Description: "Account creation request successfully created",
+		Result: map[string]any{
+			"publicKey":  publicKey,
+			"trackingId": trackingID,
+		},
+	})
+}
+
+// accountImportHandler godoc
+//
+//	@Summary		Import an existing private key as a custodial account
+//	@Description	Import an existing private key as a custodial account. The account is registered through the custodial registration proxy.
+//	@Tags			Account
+//	@Accept			json
+//	@Produce		json
+//	@Param			accountImportRequest	body		apiresp.AccountImportRequest	true	"Account import request"
+//	@Success		200						{object}	apiresp.OKResponse
+//	@Failure		400						{object}	apiresp.ErrResponse
+//	@Failure		403						{object}	apiresp.ErrResponse
+//	@Failure		409						{object}	apiresp.ErrResponse
+//	@Failure		500						{object}	apiresp.ErrResponse
+//	@Security		ApiKeyAuth
+//	@Router			/account/import [post]
+func (a *API) accountImportHandler(c echo.Context) error {
+	req := apiresp.AccountImportRequest{}
+
+	if err := c.Bind(&req); err != nil {
+		return handleBindError(c)
+	}
+
+	if err := c.Validate(req); err != nil {
+		return handleValidateError(c)
+	}
+
+	privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(req.PrivateKey, "0x"))
+	if err != nil {
+		return handleValidateError(c)
+	}
+
+	importedKeyPair := keypair.Key{
+		Public:  crypto.PubkeyToAddress(privateKey.PublicKey).Hex(),
+		Private: hexutil.Encode(crypto.FromECDSA(privateKey))[2:],
+	}
+
+	// Imported accounts may already have sent transactions from elsewhere.
+	networkNonce, err := a.rpc.Nonce(c.Request().Context(), ethutils.HexToAddress(importedKeyPair.Public), nil)
 	if err != nil {
 		return err
 	}
 
+	tx, err := a.store.Pool().Begin(c.Request().Context())
+	if err != nil {
+		return handlePostgresError(c, err)
+	}
+	defer tx.Rollback(c.Request().Context())
+
+	exists, err := a.store.KeyPairExists(c.Request().Context(), tx, importedKeyPair.Public)
+	if err != nil {
+		return handlePostgresError(c, err)
+	}
+	if exists {
+		return c.JSON(http.StatusConflict, apiresp.ErrResponse{
+			Ok:          false,
+			Description: fmt.Sprintf("Account %s already exists", importedKeyPair.Public),
+			ErrCode:     apiresp.ErrCodeAccountExists,
+		})
+	}
+
+	if err := a.store.InsertKeyPair(c.Request().Context(), tx, importedKeyPair); err != nil {
+		return handlePostgresError(c, err)
+	}
+
+	if err := a.store.SetAccountNonce(c.Request().Context(), tx, importedKeyPair.Public, networkNonce); err != nil {
+		return handlePostgresError(c, err)
+	}
+
 	trackingID := uuid.NewString()
 
-	_, err = a.queueClient.Insert(c.Request().Context(), worker.AccountCreateArgs{
+	_, err = a.queueClient.InsertTx(c.Request().Context(), tx, worker.AccountCreateArgs{
 		TrackingID: trackingID,
-		KeyPair:    generatedKeyPair,
+		PublicKey:  importedKeyPair.Public,
 	}, nil)
 	if err != nil {
-		return err
+		return handlePostgresError(c, err)
+	}
+
+	if err := tx.Commit(c.Request().Context()); err != nil {
+		return handlePostgresError(c, err)
 	}
 
 	return c.JSON(http.StatusOK, apiresp.OKResponse{
 		Ok:          true,
-		Description: "Account creation request successfully created",
+		Description: "Account import request successfully created",
 		Result: map[string]any{
-			"publicKey":  generatedKeyPair.Public,
+			"publicKey":  importedKeyPair.Public,
 			"trackingId": trackingID,
 		},
 	})
 }
 
+// accountExportHandler godoc
+//
+//	@Summary		Export a custodial account's private key
+//	@Description	Export a custodial account's private key as a password encrypted Web3 Secret Storage (keystore v3) JSON. Every export is recorded and the account can optionally be frozen.
+//	@Tags			Account
+//	@Accept			json
+//	@Produce		json
+//	@Param			accountExportRequest	body		apiresp.AccountExportRequest	true	"Account export request"
+//	@Success		200						{object}	apiresp.OKResponse
+//	@Failure		400						{object}	apiresp.ErrResponse
+//	@Failure		403						{object}	apiresp.ErrResponse
+//	@Failure		404						{object}	apiresp.ErrResponse
+//	@Failure		500						{object}	apiresp.ErrResponse
+//	@Security		ApiKeyAuth
+//	@Router			/account/export [post]
+func (a *API) accountExportHandler(c echo.Context) error {
+	req := apiresp.AccountExportRequest{}
+
+	if err := c.Bind(&req); err != nil {
+		return handleBindError(c)
+	}
+
+	if err := c.Validate(req); err != nil {
+		return handleValidateError(c)
+	}
+
+	tx, err := a.store.Pool().Begin(c.Request().Context())
+	if err != nil {
+		return handlePostgresError(c, err)
+	}
+	defer tx.Rollback(c.Request().Context())
+
+	// System signers, including retired ones, share the master_key table and must never leave the custodial.
+	systemSigners, err := a.store.GetSystemSigners(c.Request().Context(), tx)
+	if err != nil {
+		return handlePostgresError(c, err)
+	}
+	for _, v := range systemSigners {
+		if v.PublicKey == req.Address {
+			return c.JSON(http.StatusForbidden, apiresp.ErrResponse{
+				Ok:          false,
+				Description: "System signer keys cannot be exported",
+				ErrCode:     apiresp.ErrCodeSystemSigner,
+			})
+		}
+	}
+
+	exportedKeyPair, err := a.store.LoadPrivateKey(c.Request().Context(), tx, req.Address)
+	if err != nil {
+		return handlePostgresError(c, err)
+	}
+
+	privateKey, err := crypto.HexToECDSA(exportedKeyPair.Private)
+	if err != nil {
+		return err
+	}
+
+	keyJSON, err := keystore.EncryptKey(&keystore.Key{
+		Id:         uuid.New(),
+		Address:    crypto.PubkeyToAddress(privateKey.PublicKey),
+		PrivateKey: privateKey,
+	}, req.Password, keystore.StandardScryptN, keystore.StandardScryptP)
+	if err != nil {
+		return err
+	}
+
+	exportedBy, _ := c.Get("subject").(string)
+	if err := a.store.InsertKeyExport(c.Request().Context(), tx, store.KeyExport{
+		PublicKey:  exportedKeyPair.Public,
+		ExportedBy: exportedBy,
+		Frozen:     req.Freeze,
+	}); err != nil {
+		return handlePostgresError(c, err)
+	}
+
+	if req.Freeze {
+		status, err := a.store.GetAccountStatus(c.Request().Context(), tx, exportedKeyPair.Public)
+		if err != nil {
+			return handlePostgresError(c, err)
+		}
+
+		if status != store.ACCOUNT_FROZEN && status != store.ACCOUNT_CLOSED {
+			if err := a.changeAccountStatus(c.Request().Context(), tx, store.AccountStatusChange{
+				PublicKey:  exportedKeyPair.Public,
+				FromStatus: status,
+				ToStatus:   store.ACCOUNT_FROZEN,
+				Reason:     "private key exported",
+				ChangedBy:  exportedBy,
+			}); err != nil {
+				return handlePostgresError(c, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(c.Request().Context()); err != nil {
+		return handlePostgresError(c, err)
+	}
+	a.logg.Info("private key exported", "public_key", exportedKeyPair.Public, "exported_by", exportedBy, "frozen", req.Freeze)
+
+	return c.JSON(http.StatusOK, apiresp.OKResponse{
+		Ok:          true,
+		Description: "Account successfully exported",
+		Result: map[string]any{
+			"publicKey": exportedKeyPair.Public,
+			"keystore":  json.RawMessage(keyJSON),
+			"frozen":    req.Freeze,
+		},
+	})
+}
+
 // accountStatusHandler godoc
 //
 //	@Summary		Check a custodial account's status
@@ -73,16 +297,17 @@ func (a *API) accountStatusHandler(c echo.Context) error {
 		return handleValidateError(c)
 	}
 
-	var (
-		gasBalance   *big.Int
-		networkNonce uint64
-	)
+	var gasBalance *big.Int
 
 	accountAddress := ethutils.HexToAddress(req.Address)
 
-	if err := a.chainProvider.Client.CallCtx(
+	networkNonce, err := a.rpc.Nonce(c.Request().Context(), accountAddress, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := a.rpc.CallCtx(
 		c.Request().Context(),
-		eth.Nonce(accountAddress, nil).Returns(&networkNonce),
 		eth.Balance(accountAddress, nil).Returns(&gasBalance),
 	); err != nil {
 		return err
@@ -108,6 +333,11 @@ func (a *API) accountStatusHandler(c echo.Context) error {
 		return handlePostgresError(c, err)
 	}
 
+	status, err := a.store.GetAccountStatus(c.Request().Context(), tx, req.Address)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return handlePostgresError(c, err)
+	}
+
 	if err := tx.Commit(c.Request().Context()); err != nil {
 		return handlePostgresError(c, err)
 	}
@@ -120,6 +350,230 @@ func (a *API) accountStatusHandler(c echo.Context) error {
 			"networkNonce":  networkNonce,
 			"internalNonce": internalNonce,
 			"active":        active,
+			"status":        status,
 		},
 	})
 }
+
+// accountStatusUpdateHandler godoc
+//
+//	@Summary		Change a custodial account's lifecycle status
+//	@Description	Freeze, unfreeze or close a custodial account. Queued work of frozen or closed accounts is cancelled.
+//	@Tags			Account
+//	@Accept			json
+//	@Produce		json
+//	@Param			address						path		string								true	"Account address"
+//	@Param			accountStatusUpdateRequest	body		apiresp.AccountStatusUpdateRequest	true	"Account status update request"
+//	@Success		200							{object}	apiresp.OKResponse
+//	@Failure		400							{object}	apiresp.ErrResponse
+//	@Failure		403							{object}	apiresp.ErrResponse
+//	@Failure		404							{object}	apiresp.ErrResponse
+//	@Failure		500							{object}	apiresp.ErrResponse
+//	@Security		ApiKeyAuth
+//	@Router			/account/status/{address} [put]
+func (a *API) accountStatusUpdateHandler(c echo.Context) error {
+	req := apiresp.AccountStatusUpdateRequest{}
+
+	if err := c.Bind(&req); err != nil {
+		return handleBindError(c)
+	}
+
+	if err := c.Validate(req); err != nil {
+		return handleValidateError(c)
+	}
+
+	tx, err := a.store.Pool().Begin(c.Request().Context())
+	if err != nil {
+		return handlePostgresError(c, err)
+	}
+	defer tx.Rollback(c.Request().Context())
+
+	systemSigners, err := a.store.GetSystemSigners(c.Request().Context(), tx)
+	if err != nil {
+		return handlePostgresError(c, err)
+	}
+	for _, v := range systemSigners {
+		if v.PublicKey == req.Address {
+			return c.JSON(http.StatusBadRequest, apiresp.ErrResponse{
+				Ok:          false,
+				Description: "The system signer status cannot be changed",
+				ErrCode:     apiresp.ErrCodeInvalidTransition,
+			})
+		}
+	}
+
+	status, err := a.store.GetAccountStatus(c.Request().Context(), tx, req.Address)
+	if err != nil {
+		return handlePostgresError(c, err)
+	}
+
+	changedBy, _ := c.Get("subject").(string)
+	if err := a.changeAccountStatus(c.Request().Context(), tx, store.AccountStatusChange{
+		PublicKey:  req.Address,
+		FromStatus: status,
+		ToStatus:   req.Status,
+		Reason:     req.Reason,
+		ChangedBy:  changedBy,
+	}); err != nil {
+		if errors.Is(err, store.ErrInvalidStatusTransition) {
+			return c.JSON(http.StatusBadRequest, apiresp.ErrResponse{
+				Ok:          false,
+				Description: fmt.Sprintf("Account status cannot be changed from %s to %s", status, req.Status),
+				ErrCode:     apiresp.ErrCodeInvalidTransition,
+			})
+		}
+		return handlePostgresError(c, err)
+	}
+
+	if err := tx.Commit(c.Request().Context()); err != nil {
+		return handlePostgresError(c, err)
+	}
+	a.logg.Info("account status changed", "public_key", req.Address, "from", status, "to", req.Status, "changed_by", changedBy)
+
+	return c.JSON(http.StatusOK, apiresp.OKResponse{
+		Ok:          true,
+		Description: "Account status successfully changed",
+		Result: map[string]any{
+			"previousStatus": status,
+			"status":         req.Status,
+		},
+	})
+}
+
+// accountStatusHistoryHandler godoc
+//
+//	@Summary		Get a custodial account's lifecycle status history
+//	@Description	Get a custodial account's lifecycle status history, latest first
+//	@Tags			Account
+//	@Accept			*/*
+//	@Produce		json
+//	@Param			address	path		string	true	"Account address"
+//	@Success		200		{object}	apiresp.OKResponse
+//	@Failure		403		{object}	apiresp.ErrResponse
+//	@Failure		500		{object}	apiresp.ErrResponse
+//	@Security		ApiKeyAuth
+//	@Router			/account/status/{address}/history [get]
+func (a *API) accountStatusHistoryHandler(c echo.Context) error {
+	req := apiresp.AccountAddressParam{}
+
+	if err := c.Bind(&req); err != nil {
+		return handleBindError(c)
+	}
+
+	if err := c.Validate(req); err != nil {
+		return handleValidateError(c)
+	}
+
+	tx, err := a.store.Pool().Begin(c.Request().Context())
+	if err != nil {
+		return handlePostgresError(c, err)
+	}
+	defer tx.Rollback(c.Request().Context())
+
+	changes, err := a.store.GetAccountStatusChanges(c.Request().Context(), tx, req.Address)
+	if err != nil {
+		return handlePostgresError(c, err)
+	}
+
+	if err := tx.Commit(c.Request().Context()); err != nil {
+		return handlePostgresError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, apiresp.OKResponse{
+		Ok:          true,
+		Description: "Account status history",
+		Result: map[string]any{
+			"changes": changes,
+		},
+	})
+}
+
+// accountKeyAccessHandler godoc
+//
+//	@Summary		Get the private key access log of a custodial account
+//	@Description	Get the hash chained private key access log of a custodial account. Recent entries are unsealed until the next chain sealing run.
+//	@Tags			Account
+//	@Accept			*/*
+//	@Produce		json
+//	@Param			address	path		string	true	"Account address"
+//	@Param			next	query		bool	false	"Next"
+//	@Param			cursor	query		int		false	"Cursor"
+//	@Param			perPage	query		int		true	"Per page"
+//	@Success		200		{object}	apiresp.OKResponse
+//	@Failure		403		{object}	apiresp.ErrResponse
+//	@Failure		500		{object}	apiresp.ErrResponse
+//	@Security		ApiKeyAuth
+//	@Router			/account/key-access/{address} [get]
+func (a *API) accountKeyAccessHandler(c echo.Context) error {
+	req := apiresp.OTXByAccountRequest{}
+
+	if err := c.Bind(&req); err != nil {
+		return handleBindError(c)
+	}
+
+	if err := c.Validate(req); err != nil {
+		return handleValidateError(c)
+	}
+
+	pagination := validatePagination(req)
+
+	tx, err := a.store.Pool().Begin(c.Request().Context())
+	if err != nil {
+		return handlePostgresError(c, err)
+	}
+	defer tx.Rollback(c.Request().Context())
+
+	var keyAccessLog []*store.KeyAccessLog
+
+	if pagination.FirstPage {
+		keyAccessLog, err = a.store.GetKeyAccessLogByAccount(c.Request().Context(), tx, req.Address, pagination.PerPage)
+	} else if pagination.Next {
+		keyAccessLog, err = a.store.GetKeyAccessLogByAccountNext(c.Request().Context(), tx, req.Address, pagination.Cursor, pagination.PerPage)
+	} else {
+		keyAccessLog, err = a.store.GetKeyAccessLogByAccountPrevious(c.Request().Context(), tx, req.Address, pagination.Cursor, pagination.PerPage)
+	}
+	if err != nil {
+		return handlePostgresError(c, err)
+	}
+
+	if err := tx.Commit(c.Request().Context()); err != nil {
+		return handlePostgresError(c, err)
+	}
+
+	var first, last uint64
+
+	if len(keyAccessLog) > 0 {
+		first = keyAccessLog[0].ID
+		last = keyAccessLog[len(keyAccessLog)-1].ID
+	}
+
+	return c.JSON(http.StatusOK, apiresp.OKResponse{
+		Ok:          true,
+		Description: fmt.Sprintf("Successfully fetched key access log for %s", req.Address),
+		Result: map[string]any{
+			"keyAccess": keyAccessLog,
+			"first":     first,
+			"last":      last,
+		},
+	})
+}
+
+// changeAccountStatus applies a status change and, when the account is frozen or closed, enqueues cancellation of its
+// queued work within the same transaction.
+func (a *API) changeAccountStatus(ctx context.Context, tx pgx.Tx, change store.AccountStatusChange) error {
+	if err := a.store.UpdateAccountStatus(ctx, tx, change); err != nil {
+		return err
+	}
+
+	if change.ToStatus == store.ACCOUNT_FROZEN || change.ToStatus == store.ACCOUNT_CLOSED {
+		if _, err := a.queueClient.InsertTx(ctx, tx, worker.AccountDeactivateArgs{
+			PublicKey: change.PublicKey,
+		}, &river.InsertOpts{
+			Priority: 1,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -4,13 +4,15 @@ import (
 	"context"
 	"crypto"
 	"log/slog"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/go-playground/validator/v10"
 	"github.com/grassrootseconomics/eth-custodial/internal/gas"
+	"github.com/grassrootseconomics/eth-custodial/internal/multirpc"
+	"github.com/grassrootseconomics/eth-custodial/internal/signer"
 	"github.com/grassrootseconomics/eth-custodial/internal/store"
 	"github.com/grassrootseconomics/eth-custodial/internal/util"
-	"github.com/grassrootseconomics/ethutils"
 	"github.com/jackc/pgx/v5"
 	"github.com/kamikazechaser/jrpc"
 	echojwt "github.com/labstack/echo-jwt/v4"
@@ -35,10 +37,13 @@ type (
 		SigningKey    crypto.PrivateKey
 		Store         store.Store
 		Logg          *slog.Logger
-		ChainProvider *ethutils.Provider
+		RPC           *multirpc.Client
+		Signer        signer.Signer
 		GasOracle     gas.GasOracle
 		QueueClient   *river.Client[pgx.Tx]
 		BannedTokens  []string
+		// MaxGasFeeCap caps the fee of manual speed-ups, nil means no cap
+		MaxGasFeeCap *big.Int
 	}
 
 	API struct {
@@ -51,10 +56,12 @@ type (
 		store         store.Store
 		gasOracle     gas.GasOracle
 		logg          *slog.Logger
-		chainProvider *ethutils.Provider
+		rpc           *multirpc.Client
+		signer        signer.Signer
 		router        *echo.Echo
 		queueClient   *river.Client[pgx.Tx]
 		bannedTokens  map[string]struct{}
+		maxGasFeeCap  *big.Int
 	}
 )
 
@@ -76,9 +83,11 @@ func New(o APIOpts) *API {
 		logg:          o.Logg,
 		store:         o.Store,
 		gasOracle:     o.GasOracle,
-		chainProvider: o.ChainProvider,
+		rpc:           o.RPC,
+		signer:        o.Signer,
 		queueClient:   o.QueueClient,
 		bannedTokens:  make(map[string]struct{}, len(o.BannedTokens)),
+		maxGasFeeCap:  o.MaxGasFeeCap,
 	}
 
 	for _, addr := range o.BannedTokens {
@@ -155,10 +164,19 @@ func New(o APIOpts) *API {
 	}
 
 	apiGroup.GET("/system", api.systemInfoHandler)
+	apiGroup.GET("/system/nonce-gaps", api.systemNonceGapsHandler, api.serviceOnlyMiddleware())
+	apiGroup.GET("/system/fees", api.systemFeesHandler, api.serviceOnlyMiddleware())
 	apiGroup.POST("/account/create", api.accountCreateHandler)
+	apiGroup.POST("/account/import", api.accountImportHandler, api.serviceOnlyMiddleware())
+	apiGroup.POST("/account/export", api.accountExportHandler, api.serviceOnlyMiddleware())
 	apiGroup.GET("/account/status/:address", api.accountStatusHandler)
+	apiGroup.PUT("/account/status/:address", api.accountStatusUpdateHandler, api.serviceOnlyMiddleware())
+	apiGroup.GET("/account/status/:address/history", api.accountStatusHistoryHandler, api.serviceOnlyMiddleware())
+	apiGroup.GET("/account/key-access/:address", api.accountKeyAccessHandler, api.serviceOnlyMiddleware())
 	apiGroup.GET("/account/otx/:address", api.getOTXByAddressHandler)
 	apiGroup.GET("/otx/track/:trackingId", api.trackOTXHandler)
+	apiGroup.POST("/otx/cancel/:trackingId", api.cancelOTXHandler)
+	apiGroup.POST("/otx/speedup/:trackingId", api.speedUpOTXHandler)
 	apiGroup.POST("/token/transfer", api.transferHandler)
 	apiGroup.POST("/token/sweep", api.sweepHandler)
 	apiGroup.POST("/pool/quote", api.poolQuoteHandler)
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/grassrootseconomics/eth-custodial/internal/store"
 	apiresp "github.com/grassrootseconomics/eth-custodial/pkg/api"
 	"github.com/grassrootseconomics/ethutils"
 	echojwt "github.com/labstack/echo-jwt/v4"
@@ -150,6 +151,27 @@ func (a *API) authStatusMiddleware() echo.MiddlewareFunc {
 
 			c.Set("publicKey", pubKey)
 			c.Set("service", serviceKey)
+			c.Set("subject", subject)
+			c.SetRequest(c.Request().WithContext(store.WithKeyAccess(c.Request().Context(), store.KeyAccess{
+				Caller:  c.Request().Method + " " + c.Path(),
+				Subject: subject,
+			})))
+
+			return next(c)
+		}
+	}
+}
+
+func (a *API) serviceOnlyMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if service, _ := c.Get("service").(bool); !service {
+				return c.JSON(http.StatusForbidden, apiresp.ErrResponse{
+					Ok:          false,
+					Description: "This action requires a service token",
+					ErrCode:     apiresp.ErrCodeServiceOnly,
+				})
+			}
 
 			return next(c)
 		}
@@ -67,6 +67,7 @@ func (a *API) contractsERC20Handler(c echo.Context) error {
 		InitialMintee:   req.InitialMintee,
 		Owner:           req.Owner,
 		ExpiryTimestamp: req.ExpiryTimestamp,
+		FeeClass:        req.FeeClass,
 	}, nil)
 	if err != nil {
 		return handlePostgresError(c, err)
@@ -139,6 +140,7 @@ func (a *API) contractsPoolHandler(c echo.Context) error {
 		Name:       req.Name,
 		Symbol:     req.Symbol,
 		Owner:      req.Owner,
+		FeeClass:   req.FeeClass,
 	}, nil)
 	if err != nil {
 		return handlePostgresError(c, err)
@@ -217,6 +219,7 @@ func (a *API) contractsDemurrageERC20Handler(c echo.Context) error {
 		DemurrageRate:   req.DemurrageRate,
 		DemurragePeriod: req.DemurragePeriod,
 		SinkAddress:     req.SinkAddress,
+		FeeClass:        req.FeeClass,
 	}, nil)
 	if err != nil {
 		return handlePostgresError(c, err)
@@ -238,7 +241,7 @@ func (a *API) contractsDemurrageERC20Handler(c echo.Context) error {
 func (a *API) alreadyExists(ctx context.Context, index common.Address, tokenSymbol string) (bool, error) {
 	var address common.Address
 
-	if err := a.chainProvider.Client.CallCtx(
+	if err := a.rpc.CallCtx(
 		ctx,
 		eth.CallFunc(index, worker.Abi[worker.AddressOf], common.BytesToHash(common.RightPadBytes([]byte(tokenSymbol), 32))).Returns(&address),
 	); err != nil {
@@ -4,8 +4,8 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/google/uuid"
+	"github.com/grassrootseconomics/eth-custodial/internal/gas"
 	"github.com/grassrootseconomics/eth-custodial/internal/store"
 	"github.com/grassrootseconomics/eth-custodial/internal/worker"
 	"github.com/grassrootseconomics/ethutils"
@@ -49,14 +49,12 @@ func (a *API) methodEthSendTransaction(c jrpc.Context) error {
 	}
 	defer tx.Rollback(ctx)
 
-	keypair, err := a.store.LoadPrivateKey(ctx, tx, params[0].From)
+	active, err := a.store.CheckKeypair(ctx, tx, params[0].From)
 	if err != nil {
 		return err
 	}
-
-	privateKey, err := crypto.HexToECDSA(keypair.Private)
-	if err != nil {
-		return err
+	if !active {
+		return jrpc.NewErrorInvalidParams("Account does not exist or is not active")
 	}
 
 	nonce, err := a.store.AcquireNonce(ctx, tx, params[0].From)
@@ -71,12 +69,16 @@ func (a *API) methodEthSendTransaction(c jrpc.Context) error {
 
 	to := ethutils.HexToAddress(params[0].To)
 
-	gasSettings, err := a.gasOracle.GetSettings()
+	gasSettings, err := a.gasOracle.GetSettings(gas.DefaultFeeClass(store.GENERIC_SIGN))
 	if err != nil {
 		return err
 	}
 
-	builtTx, err := types.SignNewTx(privateKey, a.chainProvider.Signer, &types.DynamicFeeTx{
+	trackindID := uuid.NewString()
+	keyAccess := store.KeyAccessFromContext(ctx)
+	keyAccess.TrackingID = trackindID
+
+	builtTx, err := a.signer.SignTx(store.WithKeyAccess(ctx, keyAccess), tx, ethutils.HexToAddress(params[0].From), types.NewTx(&types.DynamicFeeTx{
 		Value:     n,
 		To:        &to,
 		Nonce:     nonce,
@@ -84,7 +86,7 @@ func (a *API) methodEthSendTransaction(c jrpc.Context) error {
 		Gas:       gasSettings.GasLimit,
 		GasFeeCap: gasSettings.GasFeeCap,
 		GasTipCap: gasSettings.GasTipCap,
-	})
+	}))
 	if err != nil {
 		return err
 	}
@@ -95,7 +97,6 @@ func (a *API) methodEthSendTransaction(c jrpc.Context) error {
 	}
 	rawTxHex := hexutil.Encode(rawTx)
 
-	trackindID := uuid.NewString()
 	otxID, err := a.store.InsertOTX(ctx, tx, store.OTX{
 		TrackingID:    trackindID,
 		OTXType:       store.GENERIC_SIGN,
@@ -2,9 +2,14 @@ package api
 
 import (
 	"fmt"
+	"math/big"
 	"net/http"
 
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/google/uuid"
 	"github.com/grassrootseconomics/eth-custodial/internal/store"
+	"github.com/grassrootseconomics/eth-custodial/internal/worker"
 	apiresp "github.com/grassrootseconomics/eth-custodial/pkg/api"
 	"github.com/labstack/echo/v4"
 )
@@ -12,7 +17,9 @@ import (
 // trackOTXHandler godoc
 //
 //	@Summary		Track an OTX's (Origin transaction) chain status
-//	@Description	Track an OTX's (Origin transaction) chain status
+//	@Description	Track an OTX's (Origin transaction) chain status. Each nonce reports its effective transaction, replaced
+//	@Description	transactions are returned as history along with the timeline of every broadcast attempt and any
+//	@Description	simulation that reverted before signing
 //	@Tags			OTX
 //	@Accept			*/*
 //	@Produce		json
@@ -43,15 +50,51 @@ func (a *API) trackOTXHandler(c echo.Context) error {
 		return handlePostgresError(c, err)
 	}
 
+	attempts, err := a.store.GetDispatchAttemptsByTrackingID(c.Request().Context(), tx, req.TrackingID)
+	if err != nil {
+		return handlePostgresError(c, err)
+	}
+
+	retrierActions, err := a.store.GetRetrierActionsByTrackingID(c.Request().Context(), tx, req.TrackingID)
+	if err != nil {
+		return handlePostgresError(c, err)
+	}
+
+	receipts, err := a.store.GetReceiptsByTrackingID(c.Request().Context(), tx, req.TrackingID)
+	if err != nil {
+		return handlePostgresError(c, err)
+	}
+
+	simulationFailures, err := a.store.GetSimulationFailuresByTrackingID(c.Request().Context(), tx, req.TrackingID)
+	if err != nil {
+		return handlePostgresError(c, err)
+	}
+
 	if err := tx.Commit(c.Request().Context()); err != nil {
 		return handlePostgresError(c, err)
 	}
 
+	// Replaced OTXs are the history of a nonce, only the effective transaction of each nonce is reported as the chain
+	effective := make([]*store.OTX, 0, len(otx))
+	history := make([]*store.OTX, 0)
+	for _, v := range otx {
+		if v.Replaced {
+			history = append(history, v)
+		} else {
+			effective = append(effective, v)
+		}
+	}
+
 	return c.JSON(http.StatusOK, apiresp.OKResponse{
 		Ok:          true,
 		Description: "Current OTX chain status",
 		Result: map[string]any{
-			"otx": otx,
+			"otx":            effective,
+			"history":        history,
+			"attempts":       attempts,
+			"retrierActions": retrierActions,
+			"receipts":       receipts,
+			"simulations":    simulationFailures,
 		},
 	})
 }
@@ -131,3 +174,220 @@ func (a *API) getOTXByAddressHandler(c echo.Context) error {
 		},
 	})
 }
+
+// cancelOTXHandler godoc
+//
+//	@Summary		Cancel an OTX (Origin transaction) that is not yet mined
+//	@Description	Replace every OTX of the tracking ID that is not yet final with a zero value transfer at the same nonce and a bumped fee. The original becomes CANCELLED if the replacement is mined, otherwise it keeps its own status.
+//	@Tags			OTX
+//	@Accept			*/*
+//	@Produce		json
+//	@Param			trackingId	path		string	true	"Tracking ID"
+//	@Success		200			{object}	apiresp.OKResponse
+//	@Failure		404			{object}	apiresp.ErrResponse
+//	@Failure		409			{object}	apiresp.ErrResponse
+//	@Failure		500			{object}	apiresp.ErrResponse
+//	@Security		ApiKeyAuth
+//	@Router			/otx/cancel/{trackingId} [post]
+func (a *API) cancelOTXHandler(c echo.Context) error {
+	req := apiresp.TrackingIDParam{}
+
+	if err := c.Bind(&req); err != nil {
+		return handleBindError(c)
+	}
+
+	if err := c.Validate(req); err != nil {
+		return handleValidateError(c)
+	}
+
+	tx, err := a.store.Pool().Begin(c.Request().Context())
+	if err != nil {
+		return handlePostgresError(c, err)
+	}
+	defer tx.Rollback(c.Request().Context())
+
+	otx, err := a.store.GetOTXByTrackingID(c.Request().Context(), tx, req.TrackingID)
+	if err != nil {
+		return handlePostgresError(c, err)
+	}
+	if len(otx) == 0 {
+		return c.JSON(http.StatusNotFound, apiresp.ErrResponse{
+			Ok:          false,
+			ErrCode:     apiresp.ErrNoRecordFound,
+			Description: fmt.Sprintf("No OTX found for tracking id %s", req.TrackingID),
+		})
+	}
+
+	var cancellable bool
+	for _, v := range otx {
+		if worker.IsCancellable(v) {
+			cancellable = true
+			break
+		}
+	}
+	if !cancellable {
+		return c.JSON(http.StatusConflict, apiresp.ErrResponse{
+			Ok:          false,
+			ErrCode:     apiresp.ErrCodeOTXFinal,
+			Description: fmt.Sprintf("OTX chain %s is already final and cannot be cancelled", req.TrackingID),
+		})
+	}
+
+	trackingID := uuid.NewString()
+
+	_, err = a.queueClient.InsertTx(c.Request().Context(), tx, worker.OTXCancelArgs{
+		TrackingID:       trackingID,
+		CancelTrackingID: req.TrackingID,
+	}, nil)
+	if err != nil {
+		return handlePostgresError(c, err)
+	}
+
+	if err := tx.Commit(c.Request().Context()); err != nil {
+		return handlePostgresError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, apiresp.OKResponse{
+		Ok:          true,
+		Description: "Cancellation request successfully created",
+		Result: map[string]any{
+			"trackingId": trackingID,
+		},
+	})
+}
+
+// speedUpOTXHandler godoc
+//
+//	@Summary		Speed up an OTX (Origin transaction) that is not yet mined
+//	@Description	Re-sign every OTX of the tracking ID that is not yet final at the same nonce with higher fees and rebroadcast it. Both the original and the replacement are kept and whichever is mined resolves the OTX.
+//	@Tags			OTX
+//	@Accept			json
+//	@Produce		json
+//	@Param			trackingId		path		string					true	"Tracking ID"
+//	@Param			speedUpRequest	body		apiresp.SpeedUpRequest	false	"Fee bump"
+//	@Success		200				{object}	apiresp.OKResponse
+//	@Failure		400				{object}	apiresp.ErrResponse
+//	@Failure		404				{object}	apiresp.ErrResponse
+//	@Failure		409				{object}	apiresp.ErrResponse
+//	@Failure		500				{object}	apiresp.ErrResponse
+//	@Security		ApiKeyAuth
+//	@Router			/otx/speedup/{trackingId} [post]
+func (a *API) speedUpOTXHandler(c echo.Context) error {
+	req := apiresp.SpeedUpRequest{}
+
+	if err := c.Bind(&req); err != nil {
+		return handleBindError(c)
+	}
+
+	if err := c.Validate(req); err != nil {
+		return handleValidateError(c)
+	}
+
+	feeBump := worker.FeeBump{
+		Multiplier: req.Multiplier,
+	}
+	if req.GasFeeCap != "" {
+		feeBump.GasFeeCap, _ = new(big.Int).SetString(req.GasFeeCap, 10)
+		feeBump.GasTipCap, _ = new(big.Int).SetString(req.GasTipCap, 10)
+		if feeBump.GasFeeCap == nil || feeBump.GasTipCap == nil {
+			return handleValidateError(c)
+		}
+	}
+
+	tx, err := a.store.Pool().Begin(c.Request().Context())
+	if err != nil {
+		return handlePostgresError(c, err)
+	}
+	defer tx.Rollback(c.Request().Context())
+
+	otx, err := a.store.GetOTXByTrackingID(c.Request().Context(), tx, req.TrackingID)
+	if err != nil {
+		return handlePostgresError(c, err)
+	}
+	if len(otx) == 0 {
+		return c.JSON(http.StatusNotFound, apiresp.ErrResponse{
+			Ok:          false,
+			ErrCode:     apiresp.ErrNoRecordFound,
+			Description: fmt.Sprintf("No OTX found for tracking id %s", req.TrackingID),
+		})
+	}
+
+	var replacements []map[string]any
+	for _, v := range otx {
+		if !worker.IsReplaceable(v) {
+			continue
+		}
+
+		originalTx, err := decodeRawTx(v.RawTx)
+		if err != nil {
+			return err
+		}
+
+		gasFeeCap, gasTipCap, err := feeBump.Apply(originalTx, a.maxGasFeeCap)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, apiresp.ErrResponse{
+				Ok:          false,
+				ErrCode:     apiresp.ErrCodeInvalidFeeBump,
+				Description: fmt.Sprintf("Cannot speed up OTX %d: %v", v.ID, err),
+			})
+		}
+
+		replacements = append(replacements, map[string]any{
+			"otxId":            v.ID,
+			"nonce":            v.Nonce,
+			"txHash":           v.TxHash,
+			"currentGasFeeCap": originalTx.GasFeeCap().String(),
+			"currentGasTipCap": originalTx.GasTipCap().String(),
+			"gasFeeCap":        gasFeeCap.String(),
+			"gasTipCap":        gasTipCap.String(),
+		})
+	}
+	if len(replacements) == 0 {
+		return c.JSON(http.StatusConflict, apiresp.ErrResponse{
+			Ok:          false,
+			ErrCode:     apiresp.ErrCodeOTXFinal,
+			Description: fmt.Sprintf("OTX chain %s is already final and cannot be sped up", req.TrackingID),
+		})
+	}
+
+	_, err = a.queueClient.InsertTx(c.Request().Context(), tx, worker.OTXSpeedUpArgs{
+		TrackingID: req.TrackingID,
+		FeeBump:    feeBump,
+	}, nil)
+	if err != nil {
+		return handlePostgresError(c, err)
+	}
+
+	if err := tx.Commit(c.Request().Context()); err != nil {
+		return handlePostgresError(c, err)
+	}
+
+	var maxGasFeeCap string
+	if a.maxGasFeeCap != nil {
+		maxGasFeeCap = a.maxGasFeeCap.String()
+	}
+
+	return c.JSON(http.StatusOK, apiresp.OKResponse{
+		Ok:          true,
+		Description: "Speed up request successfully created",
+		Result: map[string]any{
+			"trackingId":   req.TrackingID,
+			"maxGasFeeCap": maxGasFeeCap,
+			"otx":          replacements,
+		},
+	})
+}
+
+func decodeRawTx(rawTx string) (*types.Transaction, error) {
+	rawTxBytes, err := hexutil.Decode(rawTx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(rawTxBytes); err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
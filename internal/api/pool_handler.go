@@ -75,6 +75,7 @@ func (a *API) poolSwapHandler(c echo.Context) error {
 		ToTokenAddress:   req.ToTokenAddress,
 		PoolAddress:      req.PoolAddress,
 		Amount:           req.Amount,
+		FeeClass:         req.FeeClass,
 	}, nil)
 	if err != nil {
 		return handlePostgresError(c, err)
@@ -151,6 +152,7 @@ func (a *API) poolDepositHandler(c echo.Context) error {
 		TokenAddress: req.TokenAddress,
 		PoolAddress:  req.PoolAddress,
 		Amount:       req.Amount,
+		FeeClass:     req.FeeClass,
 	}, nil)
 	if err != nil {
 		return handlePostgresError(c, err)
@@ -202,7 +204,7 @@ func (a *API) poolQuoteHandler(c echo.Context) error {
 
 	var outValue *big.Int
 
-	if err := a.chainProvider.Client.CallCtx(
+	if err := a.rpc.CallCtx(
 		c.Request().Context(),
 		eth.CallFunc(
 			common.HexToAddress(req.PoolAddress),
@@ -2,11 +2,14 @@ package api
 
 import (
 	"net/http"
+	"time"
 
 	apiresp "github.com/grassrootseconomics/eth-custodial/pkg/api"
 	"github.com/labstack/echo/v4"
 )
 
+const nonceGapsReportLimit = 100
+
 // systemInfoHandler godoc
 //
 //	@Summary		Get the current system information
@@ -24,7 +27,12 @@ func (a *API) systemInfoHandler(c echo.Context) error {
 	}
 	defer tx.Rollback(c.Request().Context())
 
-	systemKey, err := a.store.LoadMasterSignerKey(c.Request().Context(), tx)
+	systemSigner, err := a.store.LoadMasterSignerAddress(c.Request().Context(), tx)
+	if err != nil {
+		return handlePostgresError(c, err)
+	}
+
+	systemSigners, err := a.store.GetSystemSigners(c.Request().Context(), tx)
 	if err != nil {
 		return handlePostgresError(c, err)
 	}
@@ -37,8 +45,100 @@ func (a *API) systemInfoHandler(c echo.Context) error {
 		Ok:          true,
 		Description: "Current system information",
 		Result: map[string]any{
-			"systemSigner": systemKey.Public,
-			"build":        a.build,
+			"systemSigner":  systemSigner,
+			"systemSigners": systemSigners,
+			"rpcNodes":      a.rpc.Status(),
+			"build":         a.build,
+		},
+	})
+}
+
+// systemNonceGapsHandler godoc
+//
+//	@Summary		Get nonce gap findings
+//	@Description	Get the latest nonce gaps found by the periodic nonce check and how each was repaired
+//	@Tags			System
+//	@Produce		json
+//	@Success		200	{object}	apiresp.OKResponse
+//	@Failure		403	{object}	apiresp.ErrResponse
+//	@Failure		500	{object}	apiresp.ErrResponse
+//	@Security		ApiKeyAuth
+//	@Router			/system/nonce-gaps [get]
+func (a *API) systemNonceGapsHandler(c echo.Context) error {
+	tx, err := a.store.Pool().Begin(c.Request().Context())
+	if err != nil {
+		return handlePostgresError(c, err)
+	}
+	defer tx.Rollback(c.Request().Context())
+
+	nonceGaps, err := a.store.GetNonceGapsReport(c.Request().Context(), tx, nonceGapsReportLimit)
+	if err != nil {
+		return handlePostgresError(c, err)
+	}
+
+	if err := tx.Commit(c.Request().Context()); err != nil {
+		return handlePostgresError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, apiresp.OKResponse{
+		Ok:          true,
+		Description: "Latest nonce gap findings",
+		Result: map[string]any{
+			"nonceGaps": nonceGaps,
+		},
+	})
+}
+
+// systemFeesHandler godoc
+//
+//	@Summary		Get native gas fees spent
+//	@Description	Get the native gas fees of mined OTXs per signer account, JWT subject and OTX type in a time range
+//	@Tags			System
+//	@Produce		json
+//	@Param			from	query		int	true	"From (unix seconds)"
+//	@Param			to		query		int	false	"To (unix seconds), defaults to now"
+//	@Success		200		{object}	apiresp.OKResponse
+//	@Failure		400		{object}	apiresp.ErrResponse
+//	@Failure		403		{object}	apiresp.ErrResponse
+//	@Failure		500		{object}	apiresp.ErrResponse
+//	@Security		ApiKeyAuth
+//	@Router			/system/fees [get]
+func (a *API) systemFeesHandler(c echo.Context) error {
+	req := apiresp.FeeReportRequest{}
+
+	if err := c.Bind(&req); err != nil {
+		return handleBindError(c)
+	}
+
+	if err := c.Validate(req); err != nil {
+		return handleValidateError(c)
+	}
+
+	to := time.Now()
+	if req.To > 0 {
+		to = time.Unix(req.To, 0)
+	}
+
+	tx, err := a.store.Pool().Begin(c.Request().Context())
+	if err != nil {
+		return handlePostgresError(c, err)
+	}
+	defer tx.Rollback(c.Request().Context())
+
+	fees, err := a.store.GetFeeReport(c.Request().Context(), tx, time.Unix(req.From, 0), to)
+	if err != nil {
+		return handlePostgresError(c, err)
+	}
+
+	if err := tx.Commit(c.Request().Context()); err != nil {
+		return handlePostgresError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, apiresp.OKResponse{
+		Ok:          true,
+		Description: "Native gas fees per account, subject and OTX type",
+		Result: map[string]any{
+			"fees": fees,
 		},
 	})
 }
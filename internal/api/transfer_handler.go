@@ -76,6 +76,7 @@ func (a *API) transferHandler(c echo.Context) error {
 		To:           req.To,
 		TokenAddress: req.TokenAddress,
 		Amount:       req.Amount,
+		FeeClass:     req.FeeClass,
 	}, nil)
 	if err != nil {
 		return handlePostgresError(c, err)
@@ -159,6 +160,7 @@ func (a *API) sweepHandler(c echo.Context) error {
 		From:         req.From,
 		To:           req.To,
 		TokenAddress: req.TokenAddress,
+		FeeClass:     req.FeeClass,
 	}, nil)
 	if err != nil {
 		return handlePostgresError(c, err)
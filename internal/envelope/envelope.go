@@ -0,0 +1,173 @@
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+type (
+	// Keyring holds every known key-encryption key (KEK) indexed by version.
+	// New data is always sealed with the current version, older versions are kept around only for opening
+	// existing data until it has been re-encrypted.
+	Keyring struct {
+		current int
+		keks    map[int]cipher.AEAD
+	}
+
+	// Sealed is an envelope encrypted secret. Ciphertext is encrypted with a random per-secret data key (DEK)
+	// and DataKey is that DEK wrapped with the KEK identified by Version. Both are hex encoded nonce||ciphertext.
+	Sealed struct {
+		Ciphertext string
+		DataKey    string
+		Version    int
+	}
+)
+
+const (
+	// PlaintextVersion marks legacy secrets that were stored before envelope encryption was introduced.
+	PlaintextVersion = 0
+
+	keySize = 32
+)
+
+var (
+	ErrInvalidKEK     = errors.New("envelope: kek must be 32 bytes")
+	ErrInvalidVersion = errors.New("envelope: kek version must be greater than 0")
+	ErrUnknownVersion = errors.New("envelope: unknown kek version")
+	ErrMalformed      = errors.New("envelope: malformed sealed data")
+)
+
+func NewKeyring(current int, keks map[int][]byte) (*Keyring, error) {
+	if _, ok := keks[current]; !ok {
+		return nil, fmt.Errorf("%w %d", ErrUnknownVersion, current)
+	}
+
+	keyring := &Keyring{
+		current: current,
+		keks:    make(map[int]cipher.AEAD, len(keks)),
+	}
+
+	for version, kek := range keks {
+		if version <= PlaintextVersion {
+			return nil, ErrInvalidVersion
+		}
+
+		aead, err := newAEAD(kek)
+		if err != nil {
+			return nil, err
+		}
+		keyring.keks[version] = aead
+	}
+
+	return keyring, nil
+}
+
+func (k *Keyring) CurrentVersion() int {
+	return k.current
+}
+
+// Seal encrypts plaintext under a fresh DEK and wraps the DEK with the current KEK.
+// additionalData is authenticated but not encrypted, it binds the secret to its owner (e.g. the public key).
+func (k *Keyring) Seal(plaintext []byte, additionalData []byte) (Sealed, error) {
+	dek := make([]byte, keySize)
+	if _, err := rand.Read(dek); err != nil {
+		return Sealed{}, err
+	}
+
+	dekAEAD, err := newAEAD(dek)
+	if err != nil {
+		return Sealed{}, err
+	}
+
+	ciphertext, err := seal(dekAEAD, plaintext, additionalData)
+	if err != nil {
+		return Sealed{}, err
+	}
+
+	wrappedDEK, err := seal(k.keks[k.current], dek, additionalData)
+	if err != nil {
+		return Sealed{}, err
+	}
+
+	return Sealed{
+		Ciphertext: hex.EncodeToString(ciphertext),
+		DataKey:    hex.EncodeToString(wrappedDEK),
+		Version:    k.current,
+	}, nil
+}
+
+// Open unwraps the DEK with the KEK version recorded in the envelope and decrypts the secret.
+func (k *Keyring) Open(sealed Sealed, additionalData []byte) ([]byte, error) {
+	kekAEAD, ok := k.keks[sealed.Version]
+	if !ok {
+		return nil, fmt.Errorf("%w %d", ErrUnknownVersion, sealed.Version)
+	}
+
+	wrappedDEK, err := hex.DecodeString(sealed.DataKey)
+	if err != nil {
+		return nil, ErrMalformed
+	}
+
+	dek, err := open(kekAEAD, wrappedDEK, additionalData)
+	if err != nil {
+		return nil, err
+	}
+
+	dekAEAD, err := newAEAD(dek)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := hex.DecodeString(sealed.Ciphertext)
+	if err != nil {
+		return nil, ErrMalformed
+	}
+
+	return open(dekAEAD, ciphertext, additionalData)
+}
+
+// Rewrap re-seals an existing envelope under the current KEK. The secret itself is re-encrypted with a new DEK
+// so that a compromised DEK from the old envelope is of no further use.
+func (k *Keyring) Rewrap(sealed Sealed, additionalData []byte) (Sealed, error) {
+	plaintext, err := k.Open(sealed, additionalData)
+	if err != nil {
+		return Sealed{}, err
+	}
+
+	return k.Seal(plaintext, additionalData)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != keySize {
+		return nil, ErrInvalidKEK
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func seal(aead cipher.AEAD, plaintext []byte, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(aead cipher.AEAD, data []byte, additionalData []byte) ([]byte, error) {
+	if len(data) < aead.NonceSize() {
+		return nil, ErrMalformed
+	}
+
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, additionalData)
+}
@@ -0,0 +1,84 @@
+package envelope
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func testKEK(b byte) []byte {
+	return bytes.Repeat([]byte{b}, keySize)
+}
+
+func TestKeyring_SealOpen(t *testing.T) {
+	keyring, err := NewKeyring(1, map[int][]byte{1: testKEK(1)})
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+
+	secret := []byte("c0ffee")
+	aad := []byte("0x9cBcD1C2e587C8eCd8AB05a33D28A6C438a2adEC")
+
+	sealed, err := keyring.Seal(secret, aad)
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	if sealed.Version != 1 {
+		t.Errorf("Seal() version = %d, want 1", sealed.Version)
+	}
+
+	got, err := keyring.Open(sealed, aad)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if !bytes.Equal(got, secret) {
+		t.Errorf("Open() = %s, want %s", got, secret)
+	}
+
+	if _, err := keyring.Open(sealed, []byte("another-account")); err == nil {
+		t.Errorf("Open() with mismatched additional data should fail")
+	}
+}
+
+func TestKeyring_Rewrap(t *testing.T) {
+	oldKeyring, err := NewKeyring(1, map[int][]byte{1: testKEK(1)})
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+
+	secret := []byte("c0ffee")
+	sealed, err := oldKeyring.Seal(secret, nil)
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+
+	rotatedKeyring, err := NewKeyring(2, map[int][]byte{1: testKEK(1), 2: testKEK(2)})
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+
+	rewrapped, err := rotatedKeyring.Rewrap(sealed, nil)
+	if err != nil {
+		t.Fatalf("Rewrap() error = %v", err)
+	}
+	if rewrapped.Version != 2 {
+		t.Errorf("Rewrap() version = %d, want 2", rewrapped.Version)
+	}
+
+	newKeyring, err := NewKeyring(2, map[int][]byte{2: testKEK(2)})
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+
+	got, err := newKeyring.Open(rewrapped, nil)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if !bytes.Equal(got, secret) {
+		t.Errorf("Open() = %s, want %s", got, secret)
+	}
+
+	if _, err := newKeyring.Open(sealed, nil); !errors.Is(err, ErrUnknownVersion) {
+		t.Errorf("Open() error = %v, want %v", err, ErrUnknownVersion)
+	}
+}
@@ -0,0 +1,59 @@
+package gas
+
+import (
+	"fmt"
+
+	"github.com/grassrootseconomics/eth-custodial/internal/store"
+)
+
+// Fee classes trade inclusion speed for cost. Oracles that don't look at the fee market return the same settings for
+// every class.
+const (
+	FeeClassEconomy = "economy"
+	FeeClassNormal  = "normal"
+	FeeClassUrgent  = "urgent"
+)
+
+// defaultFeeClasses is the class of otx types that don't use FeeClassNormal when the caller doesn't pick one.
+var defaultFeeClasses = map[string]string{
+	store.STANDARD_TOKEN_DEPLOY:   FeeClassEconomy,
+	store.DEMURRAGE_TOKEN_DEPLOY:  FeeClassEconomy,
+	store.EXPIRING_TOKEN_DEPLOY:   FeeClassEconomy,
+	store.POOL_DEPLOY:             FeeClassEconomy,
+	store.TOKEN_INDEX_DEPLOY:      FeeClassEconomy,
+	store.LIMITER_DEPLOY:          FeeClassEconomy,
+	store.SWAPPOOL_DEPLOY:         FeeClassEconomy,
+	store.PRICEINDEXQUOTER_DEPLOY: FeeClassEconomy,
+	store.TRANSFER_OWNERSHIP:      FeeClassEconomy,
+	store.TOKEN_INDEX_ADD:         FeeClassEconomy,
+	store.POOL_INDEX_ADD:          FeeClassEconomy,
+	store.SET_QUOTER:              FeeClassEconomy,
+	// Both unblock every later OTX of the signer.
+	store.NONCE_GAP_FILL: FeeClassUrgent,
+	store.CANCEL:         FeeClassUrgent,
+}
+
+// DefaultFeeClass returns the fee class an otx type is signed with when the caller doesn't pick one.
+func DefaultFeeClass(otxType string) string {
+	if feeClass, ok := defaultFeeClasses[otxType]; ok {
+		return feeClass
+	}
+	return FeeClassNormal
+}
+
+// ResolveFeeClass returns feeClass or, if it is empty, the default of otxType.
+func ResolveFeeClass(feeClass string, otxType string) string {
+	if feeClass == "" {
+		return DefaultFeeClass(otxType)
+	}
+	return feeClass
+}
+
+func validateFeeClass(feeClass string) error {
+	switch feeClass {
+	case FeeClassEconomy, FeeClassNormal, FeeClassUrgent:
+		return nil
+	default:
+		return fmt.Errorf("gas: unknown fee class %q", feeClass)
+	}
+}
@@ -0,0 +1,149 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/grassrootseconomics/eth-custodial/internal/multirpc"
+	"github.com/grassrootseconomics/eth-custodial/internal/store"
+	"github.com/lmittmann/w3/module/eth"
+	"github.com/lmittmann/w3/w3types"
+)
+
+type (
+	GasLimitEstimatorOpts struct {
+		Logg *slog.Logger
+		RPC  *multirpc.Client
+		// MarginPercent is added on top of eth_estimateGas
+		MarginPercent uint64
+		// Ceilings caps the gas limit per otx type, types without one are capped at DefaultCeiling
+		Ceilings       map[string]uint64
+		DefaultCeiling uint64
+		// FallbackLimit is used when estimation fails and nothing is cached for the contract method
+		FallbackLimit uint64
+	}
+
+	GasLimitEstimator struct {
+		logg           *slog.Logger
+		rpc            *multirpc.Client
+		marginPercent  uint64
+		ceilings       map[string]uint64
+		defaultCeiling uint64
+		fallbackLimit  uint64
+
+		mu    sync.RWMutex
+		cache map[methodKey]uint64
+	}
+
+	// GasLimit is the gas limit a transaction is signed with and where it came from.
+	GasLimit struct {
+		Limit  uint64
+		Source string
+	}
+
+	methodKey struct {
+		contract common.Address
+		selector [4]byte
+	}
+)
+
+var (
+	// pendingBlock estimates on top of the transactions already in the node's pool.
+	pendingBlock = big.NewInt(-1)
+
+	// errNoCode is a contract call to an account without code, e.g. a contract deployed earlier in the same flow.
+	// The node estimates it as a plain transfer.
+	errNoCode = errors.New("gas: call target has no code")
+
+	// ErrCeilingExceeded is a call the node estimates above the ceiling of its otx type. Signing it at the ceiling
+	// would only run out of gas on chain.
+	ErrCeilingExceeded = errors.New("gas: estimate exceeds the otx type ceiling")
+)
+
+func NewGasLimitEstimator(o GasLimitEstimatorOpts) *GasLimitEstimator {
+	return &GasLimitEstimator{
+		logg:           o.Logg,
+		rpc:            o.RPC,
+		marginPercent:  o.MarginPercent,
+		ceilings:       o.Ceilings,
+		defaultCeiling: o.DefaultCeiling,
+		fallbackLimit:  o.FallbackLimit,
+		cache:          make(map[methodKey]uint64),
+	}
+}
+
+// Estimate returns the gas limit of a call from the signer at the pending block with the margin applied. If the node
+// can't estimate it, e.g. the call depends on a step of the same flow that isn't mined yet or targets a contract that
+// isn't deployed yet, the last estimate of the same contract method is used and otherwise the fallback limit. The
+// result never exceeds the ceiling of otxType, the margin is trimmed to fit it and ErrCeilingExceeded is returned if
+// the estimate itself is above it.
+func (e *GasLimitEstimator) Estimate(ctx context.Context, otxType string, from common.Address, to common.Address, input []byte, value *big.Int) (GasLimit, error) {
+	key := methodKey{contract: to}
+	copy(key.selector[:], input)
+
+	ceiling, ok := e.ceilings[otxType]
+	if !ok {
+		ceiling = e.defaultCeiling
+	}
+
+	var (
+		estimate uint64
+		code     []byte
+		gasLimit GasLimit
+	)
+
+	calls := []w3types.RPCCaller{
+		eth.EstimateGas(&w3types.Message{
+			From:  from,
+			To:    &to,
+			Input: input,
+			Value: value,
+		}, pendingBlock).Returns(&estimate),
+	}
+	if len(input) > 0 {
+		calls = append(calls, eth.Code(to, pendingBlock).Returns(&code))
+	}
+
+	err := e.rpc.CallCtx(ctx, calls...)
+	if err == nil && len(input) > 0 && len(code) == 0 {
+		err = errNoCode
+	}
+
+	if err == nil {
+		if ceiling > 0 && estimate > ceiling {
+			return GasLimit{}, fmt.Errorf("%w: %s to %s estimated at %d, ceiling %d", ErrCeilingExceeded, otxType, to.Hex(), estimate, ceiling)
+		}
+
+		gasLimit = GasLimit{
+			Limit:  estimate + estimate*e.marginPercent/100,
+			Source: store.GAS_LIMIT_ESTIMATED,
+		}
+
+		e.mu.Lock()
+		e.cache[key] = gasLimit.Limit
+		e.mu.Unlock()
+	} else {
+		e.mu.RLock()
+		cached, ok := e.cache[key]
+		e.mu.RUnlock()
+
+		if ok {
+			gasLimit = GasLimit{Limit: cached, Source: store.GAS_LIMIT_CACHED}
+		} else {
+			gasLimit = GasLimit{Limit: e.fallbackLimit, Source: store.GAS_LIMIT_FALLBACK}
+		}
+		e.logg.Debug("gas estimation failed", "otx_type", otxType, "to", to.Hex(), "source", gasLimit.Source, "gas_limit", gasLimit.Limit, "error", err)
+	}
+
+	if ceiling > 0 && gasLimit.Limit > ceiling {
+		e.logg.Warn("gas limit capped at otx type ceiling", "otx_type", otxType, "to", to.Hex(), "source", gasLimit.Source, "gas_limit", gasLimit.Limit, "ceiling", ceiling)
+		gasLimit.Limit = ceiling
+	}
+
+	return gasLimit, nil
+}
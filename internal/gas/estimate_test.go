@@ -0,0 +1,110 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/grassrootseconomics/eth-custodial/internal/store"
+)
+
+func TestGasLimitEstimator(t *testing.T) {
+	var reverts atomic.Bool
+	undeployed := common.HexToAddress("0x9cBcD1C2e587C8eCd8AB05a33D28A6C438a2adEC")
+
+	rpc := stubRPC(t, func(method string, params []any) (any, error) {
+		switch {
+		case method == "eth_getCode" && params[0] == strings.ToLower(undeployed.Hex()):
+			return "0x", nil
+		case method == "eth_getCode":
+			return "0x6080", nil
+		case reverts.Load():
+			return nil, errors.New("execution reverted")
+		default:
+			// 50000
+			return "0xc350", nil
+		}
+	})
+
+	estimator := NewGasLimitEstimator(GasLimitEstimatorOpts{
+		Logg:           discardLogg,
+		RPC:            rpc,
+		MarginPercent:  20,
+		Ceilings:       map[string]uint64{store.TOKEN_APPROVE: 55000, store.TOKEN_SWEEP: 40000},
+		DefaultCeiling: 300000,
+		FallbackLimit:  350000,
+	})
+
+	to := common.HexToAddress("0x765DE816845861e75A25fCA122bb6898B8B1282a")
+	transfer := common.FromHex("0xa9059cbb")
+	approve := common.FromHex("0x095ea7b3")
+
+	tests := []struct {
+		name    string
+		reverts bool
+		otxType string
+		to      common.Address
+		input   []byte
+		want    GasLimit
+		wantErr error
+	}{
+		{
+			name:    "fallback capped at default ceiling",
+			reverts: true,
+			otxType: store.TOKEN_TRANSFER,
+			input:   transfer,
+			want:    GasLimit{Limit: 300000, Source: store.GAS_LIMIT_FALLBACK},
+		},
+		{
+			name:    "estimate with margin",
+			otxType: store.TOKEN_TRANSFER,
+			input:   transfer,
+			want:    GasLimit{Limit: 60000, Source: store.GAS_LIMIT_ESTIMATED},
+		},
+		{
+			name:    "cached estimate of the same method",
+			reverts: true,
+			otxType: store.TOKEN_TRANSFER,
+			input:   transfer,
+			want:    GasLimit{Limit: 60000, Source: store.GAS_LIMIT_CACHED},
+		},
+		{
+			name:    "margin trimmed to otx type ceiling",
+			otxType: store.TOKEN_APPROVE,
+			input:   approve,
+			want:    GasLimit{Limit: 55000, Source: store.GAS_LIMIT_ESTIMATED},
+		},
+		{
+			name:    "estimate above otx type ceiling",
+			otxType: store.TOKEN_SWEEP,
+			input:   transfer,
+			wantErr: ErrCeilingExceeded,
+		},
+		{
+			name:    "fallback for a contract that isn't deployed yet",
+			otxType: store.TOKEN_TRANSFER,
+			to:      undeployed,
+			input:   transfer,
+			want:    GasLimit{Limit: 300000, Source: store.GAS_LIMIT_FALLBACK},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reverts.Store(tt.reverts)
+			if tt.to == (common.Address{}) {
+				tt.to = to
+			}
+
+			got, err := estimator.Estimate(context.Background(), tt.otxType, common.Address{}, tt.to, tt.input, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Estimate() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Estimate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package gas
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math/big"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/grassrootseconomics/eth-custodial/internal/multirpc"
+	"github.com/grassrootseconomics/ethutils"
+	"github.com/lmittmann/w3/module/eth"
+)
+
+type (
+	FeeHistoryGasOracleOpts struct {
+		Logg *slog.Logger
+		RPC  *multirpc.Client
+		// BlockCount is the number of recent blocks the tips are sampled from
+		BlockCount uint64
+	}
+
+	// FeeHistoryGasOracle prices each fee class from the tips paid in recent blocks and the pending base fee.
+	FeeHistoryGasOracle struct {
+		logg       *slog.Logger
+		rpc        *multirpc.Client
+		blockCount uint64
+		stopCh     chan struct{}
+
+		mu       sync.RWMutex
+		settings map[string]*GasSettings
+	}
+
+	// feeClassParams are the reward percentile the tip of a class is taken at and the headroom of its fee cap over
+	// the pending base fee in percent. The base fee rises by at most 12.5% per full block, so 110% covers the next
+	// block, 150% about three and 200% about six.
+	feeClassParams struct {
+		percentile        float64
+		baseFeeMultiplier int64
+	}
+
+	feeHistory struct {
+		BaseFeePerGas []*hexutil.Big   `json:"baseFeePerGas"`
+		GasUsedRatio  []float64        `json:"gasUsedRatio"`
+		Reward        [][]*hexutil.Big `json:"reward"`
+	}
+
+	feeHistoryCaller struct {
+		blockCount  uint64
+		percentiles []float64
+		ret         *feeHistory
+	}
+)
+
+const (
+	feeHistoryUpdateInterval = 15 * time.Second
+	defaultFeeHistoryBlocks  = 20
+)
+
+var (
+	// feeClasses is ordered by percentile, the reward columns of eth_feeHistory follow the same order.
+	feeClasses = []string{FeeClassEconomy, FeeClassNormal, FeeClassUrgent}
+
+	feeClassesParams = map[string]feeClassParams{
+		FeeClassEconomy: {percentile: 10, baseFeeMultiplier: 110},
+		FeeClassNormal:  {percentile: 50, baseFeeMultiplier: 150},
+		FeeClassUrgent:  {percentile: 90, baseFeeMultiplier: 200},
+	}
+)
+
+func NewFeeHistoryGasOracle(o FeeHistoryGasOracleOpts) (*FeeHistoryGasOracle, error) {
+	if o.BlockCount == 0 {
+		o.BlockCount = defaultFeeHistoryBlocks
+	}
+
+	feeHistoryGasOracle := &FeeHistoryGasOracle{
+		logg:       o.Logg,
+		rpc:        o.RPC,
+		blockCount: o.BlockCount,
+		stopCh:     make(chan struct{}),
+	}
+
+	if err := feeHistoryGasOracle.updateSettings(); err != nil {
+		return nil, err
+	}
+
+	return feeHistoryGasOracle, nil
+}
+
+func (g *FeeHistoryGasOracle) Stop() {
+	g.stopCh <- struct{}{}
+}
+
+func (g *FeeHistoryGasOracle) Start() {
+	ticker := time.NewTicker(feeHistoryUpdateInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-g.stopCh:
+			g.logg.Debug("stopping fee history gas oracle updater")
+			return
+		case <-ticker.C:
+			if err := g.updateSettings(); err != nil {
+				g.logg.Error("failed to update fee history gas settings", "err", err)
+			}
+		}
+	}
+}
+
+func (g *FeeHistoryGasOracle) GetSettings(feeClass string) (*GasSettings, error) {
+	if err := validateFeeClass(feeClass); err != nil {
+		return nil, err
+	}
+
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return g.settings[feeClass], nil
+}
+
+func (g *FeeHistoryGasOracle) updateSettings() error {
+	percentiles := make([]float64, len(feeClasses))
+	for i, feeClass := range feeClasses {
+		percentiles[i] = feeClassesParams[feeClass].percentile
+	}
+
+	var (
+		history   feeHistory
+		suggested *big.Int
+	)
+
+	if err := g.rpc.CallCtx(
+		context.Background(),
+		&feeHistoryCaller{blockCount: g.blockCount, percentiles: percentiles, ret: &history},
+		eth.GasTipCap().Returns(&suggested),
+	); err != nil {
+		return err
+	}
+
+	// The last base fee is that of the pending block
+	if len(history.BaseFeePerGas) == 0 {
+		return errors.New("gas: fee history without base fees")
+	}
+	pendingBaseFee := history.BaseFeePerGas[len(history.BaseFeePerGas)-1].ToInt()
+
+	settings := make(map[string]*GasSettings, len(feeClasses))
+	for i, feeClass := range feeClasses {
+		gasTipCap := medianReward(history, i)
+		if gasTipCap == nil {
+			// No block in the window had any transactions
+			gasTipCap = new(big.Int).Set(suggested)
+		}
+
+		gasFeeCap := new(big.Int).Mul(pendingBaseFee, big.NewInt(feeClassesParams[feeClass].baseFeeMultiplier))
+		gasFeeCap.Div(gasFeeCap, big.NewInt(100))
+		gasFeeCap.Add(gasFeeCap, gasTipCap)
+
+		settings[feeClass] = &GasSettings{
+			GasFeeCap: gasFeeCap,
+			GasTipCap: gasTipCap,
+			GasLimit:  uint64(ethutils.SafeGasLimit),
+		}
+	}
+
+	g.mu.Lock()
+	g.settings = settings
+	g.mu.Unlock()
+
+	g.logg.Info("updated fee history gas settings",
+		"pending_base_fee", pendingBaseFee,
+		"economy_tip_cap", settings[FeeClassEconomy].GasTipCap,
+		"normal_tip_cap", settings[FeeClassNormal].GasTipCap,
+		"urgent_tip_cap", settings[FeeClassUrgent].GasTipCap,
+	)
+
+	return nil
+}
+
+// medianReward is the median of the column'th reward percentile over the blocks that had transactions, nil if none
+// had any. Empty blocks report a zero reward which would drag the tip down.
+func medianReward(history feeHistory, column int) *big.Int {
+	var rewards []*big.Int
+	for i, blockRewards := range history.Reward {
+		if i < len(history.GasUsedRatio) && history.GasUsedRatio[i] == 0 {
+			continue
+		}
+		if column < len(blockRewards) && blockRewards[column] != nil {
+			rewards = append(rewards, blockRewards[column].ToInt())
+		}
+	}
+
+	if len(rewards) == 0 {
+		return nil
+	}
+
+	slices.SortFunc(rewards, func(a, b *big.Int) int { return a.Cmp(b) })
+	return new(big.Int).Set(rewards[len(rewards)/2])
+}
+
+func (c *feeHistoryCaller) CreateRequest() (rpc.BatchElem, error) {
+	return rpc.BatchElem{
+		Method: "eth_feeHistory",
+		Args:   []any{hexutil.Uint64(c.blockCount), "latest", c.percentiles},
+		Result: &json.RawMessage{},
+	}, nil
+}
+
+func (c *feeHistoryCaller) HandleResponse(elem rpc.BatchElem) error {
+	if elem.Error != nil {
+		return elem.Error
+	}
+
+	return json.Unmarshal(*elem.Result.(*json.RawMessage), c.ret)
+}
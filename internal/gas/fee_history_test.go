@@ -0,0 +1,81 @@
+package gas
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/grassrootseconomics/eth-custodial/internal/store"
+)
+
+func TestFeeHistoryGasOracle(t *testing.T) {
+	results := map[string]any{
+		// The last block is empty and its zero rewards must be ignored, the last base fee (10 gwei) is the pending one.
+		"eth_feeHistory": map[string]any{
+			"oldestBlock":   "0x1",
+			"baseFeePerGas": []string{"0x1", "0x1", "0x1", "0x2540be400"},
+			"gasUsedRatio":  []float64{0.5, 0.7, 0},
+			"reward": [][]string{
+				{"0x1", "0x3", "0x9"},
+				{"0x2", "0x4", "0x8"},
+				{"0x0", "0x0", "0x0"},
+			},
+		},
+		"eth_maxPriorityFeePerGas": "0x5",
+	}
+
+	rpc := stubRPC(t, func(method string, _ []any) (any, error) {
+		return results[method], nil
+	})
+
+	oracle, err := NewFeeHistoryGasOracle(FeeHistoryGasOracleOpts{
+		Logg: discardLogg,
+		RPC:  rpc,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		feeClass  string
+		gasFeeCap int64
+		gasTipCap int64
+	}{
+		{feeClass: FeeClassEconomy, gasFeeCap: 11_000_000_002, gasTipCap: 2},
+		{feeClass: FeeClassNormal, gasFeeCap: 15_000_000_004, gasTipCap: 4},
+		{feeClass: FeeClassUrgent, gasFeeCap: 20_000_000_009, gasTipCap: 9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.feeClass, func(t *testing.T) {
+			settings, err := oracle.GetSettings(tt.feeClass)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if settings.GasFeeCap.Cmp(big.NewInt(tt.gasFeeCap)) != 0 || settings.GasTipCap.Cmp(big.NewInt(tt.gasTipCap)) != 0 {
+				t.Errorf("GetSettings() fee cap %s tip %s, want %d and %d", settings.GasFeeCap, settings.GasTipCap, tt.gasFeeCap, tt.gasTipCap)
+			}
+		})
+	}
+
+	if _, err := oracle.GetSettings("instant"); err == nil {
+		t.Error("GetSettings() accepted an unknown fee class")
+	}
+}
+
+func TestResolveFeeClass(t *testing.T) {
+	tests := []struct {
+		feeClass string
+		otxType  string
+		want     string
+	}{
+		{feeClass: "", otxType: store.STANDARD_TOKEN_DEPLOY, want: FeeClassEconomy},
+		{feeClass: "", otxType: store.TOKEN_TRANSFER, want: FeeClassNormal},
+		{feeClass: "", otxType: store.NONCE_GAP_FILL, want: FeeClassUrgent},
+		{feeClass: FeeClassUrgent, otxType: store.POOL_DEPLOY, want: FeeClassUrgent},
+	}
+	for _, tt := range tests {
+		if got := ResolveFeeClass(tt.feeClass, tt.otxType); got != tt.want {
+			t.Errorf("ResolveFeeClass(%q, %s) = %s, want %s", tt.feeClass, tt.otxType, got, tt.want)
+		}
+	}
+}
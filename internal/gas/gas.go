@@ -12,7 +12,8 @@ type (
 	}
 
 	GasOracle interface {
-		GetSettings() (*GasSettings, error)
+		// GetSettings returns the fees of a fee class, see DefaultFeeClass for the class of an otx type.
+		GetSettings(feeClass string) (*GasSettings, error)
 		Start()
 		Stop()
 	}
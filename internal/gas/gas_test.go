@@ -0,0 +1,76 @@
+package gas
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grassrootseconomics/eth-custodial/internal/multirpc"
+)
+
+type stubRequest struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params []any           `json:"params"`
+}
+
+var discardLogg = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// stubRPC returns a client for a single node that answers every call, batched or not, with respond. A non-nil error
+// is returned as a JSON-RPC error.
+func stubRPC(t *testing.T, respond func(method string, params []any) (any, error)) *multirpc.Client {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		batch := bytes.HasPrefix(bytes.TrimSpace(body), []byte("["))
+		var reqs []stubRequest
+		if batch {
+			err = json.Unmarshal(body, &reqs)
+		} else {
+			reqs = make([]stubRequest, 1)
+			err = json.Unmarshal(body, &reqs[0])
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		resps := make([]map[string]any, len(reqs))
+		for i, req := range reqs {
+			resps[i] = map[string]any{"jsonrpc": "2.0", "id": req.ID}
+			if result, err := respond(req.Method, req.Params); err != nil {
+				resps[i]["error"] = map[string]any{"code": -32000, "message": err.Error()}
+			} else {
+				resps[i]["result"] = result
+			}
+		}
+
+		if batch {
+			json.NewEncoder(w).Encode(resps)
+		} else {
+			json.NewEncoder(w).Encode(resps[0])
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	rpc, err := multirpc.New(multirpc.ClientOpts{
+		Logg:      discardLogg,
+		Endpoints: []string{server.URL},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(rpc.Close)
+
+	return rpc
+}
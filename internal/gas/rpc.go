@@ -6,19 +6,20 @@ import (
 	"math/big"
 	"time"
 
+	"github.com/grassrootseconomics/eth-custodial/internal/multirpc"
 	"github.com/grassrootseconomics/ethutils"
 	"github.com/lmittmann/w3/module/eth"
 )
 
 type (
 	RPCGasOracleOpts struct {
-		Logg          *slog.Logger
-		ChainProvider *ethutils.Provider
+		Logg *slog.Logger
+		RPC  *multirpc.Client
 	}
 
 	RPCGasOracle struct {
 		logg           *slog.Logger
-		chainProvider  *ethutils.Provider
+		rpc            *multirpc.Client
 		cachedGasPrice *GasSettings
 		stopCh         chan struct{}
 	}
@@ -28,9 +29,9 @@ const rpcUpdateInterval = 30 * time.Second
 
 func NewRPCGasOracle(o RPCGasOracleOpts) (*RPCGasOracle, error) {
 	rpcGasOracle := &RPCGasOracle{
-		logg:          o.Logg,
-		chainProvider: o.ChainProvider,
-		stopCh:        make(chan struct{}),
+		logg:   o.Logg,
+		rpc:    o.RPC,
+		stopCh: make(chan struct{}),
 		cachedGasPrice: &GasSettings{
 			GasLimit: uint64(ethutils.SafeGasLimit),
 		},
@@ -65,7 +66,12 @@ func (g *RPCGasOracle) Start() {
 	}
 }
 
-func (g *RPCGasOracle) GetSettings() (*GasSettings, error) {
+// GetSettings returns the same fees for every fee class.
+func (g *RPCGasOracle) GetSettings(feeClass string) (*GasSettings, error) {
+	if err := validateFeeClass(feeClass); err != nil {
+		return nil, err
+	}
+
 	return g.cachedGasPrice, nil
 }
 
@@ -75,7 +81,7 @@ func (g *RPCGasOracle) updateGasPrice() error {
 		newTipCap   *big.Int
 	)
 
-	if err := g.chainProvider.Client.CallCtx(
+	if err := g.rpc.CallCtx(
 		context.Background(),
 		eth.GasPrice().Returns(&newGasPrice),
 		eth.GasTipCap().Returns(&newTipCap),
@@ -8,7 +8,12 @@ import (
 
 type StaticGas struct{}
 
-func (sg *StaticGas) GetSettings() (*GasSettings, error) {
+// GetSettings returns the same fees for every fee class.
+func (sg *StaticGas) GetSettings(feeClass string) (*GasSettings, error) {
+	if err := validateFeeClass(feeClass); err != nil {
+		return nil, err
+	}
+
 	return &GasSettings{
 		GasFeeCap: big.NewInt(15000000000),
 		GasTipCap: ethutils.SafeGasTipCap,
@@ -0,0 +1,131 @@
+package keypair
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// SeedLength is the length of generated HD seeds, the maximum allowed by BIP-32.
+const SeedLength = 64
+
+var (
+	ErrInvalidSeed  = errors.New("keypair: hd seed must be between 16 and 64 bytes")
+	ErrInvalidChild = errors.New("keypair: derived child key is invalid")
+
+	masterKeyHMACKey = []byte("Bitcoin seed")
+)
+
+type extendedKey struct {
+	key       *big.Int
+	chainCode []byte
+}
+
+func GenerateSeed() ([]byte, error) {
+	seed := make([]byte, SeedLength)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, err
+	}
+
+	return seed, nil
+}
+
+// AccountDerivationPath returns the BIP-44 path m/44'/60'/0'/0/index for a custodial account.
+func AccountDerivationPath(index uint32) accounts.DerivationPath {
+	path := make(accounts.DerivationPath, len(accounts.DefaultRootDerivationPath), len(accounts.DefaultRootDerivationPath)+1)
+	copy(path, accounts.DefaultRootDerivationPath)
+
+	return append(path, index)
+}
+
+// DeriveKeyPair derives the account key at AccountDerivationPath(index) from seed.
+func DeriveKeyPair(seed []byte, index uint32) (Key, error) {
+	privateKey, err := DerivePrivateKey(seed, AccountDerivationPath(index))
+	if err != nil {
+		return Key{}, err
+	}
+
+	return Key{
+		Public:  crypto.PubkeyToAddress(privateKey.PublicKey).Hex(),
+		Private: hexutil.Encode(crypto.FromECDSA(privateKey))[2:],
+	}, nil
+}
+
+// DerivePrivateKey implements BIP-32 private parent to private child key derivation along path.
+func DerivePrivateKey(seed []byte, path accounts.DerivationPath) (*ecdsa.PrivateKey, error) {
+	if len(seed) < 16 || len(seed) > SeedLength {
+		return nil, ErrInvalidSeed
+	}
+
+	extended, err := newExtendedKey(masterKeyHMACKey, seed)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, index := range path {
+		if extended, err = extended.child(index); err != nil {
+			return nil, err
+		}
+	}
+
+	return crypto.ToECDSA(extended.key.FillBytes(make([]byte, 32)))
+}
+
+func newExtendedKey(hmacKey []byte, data []byte) (extendedKey, error) {
+	mac := hmac.New(sha512.New, hmacKey)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	key := new(big.Int).SetBytes(sum[:32])
+	if key.Sign() == 0 || key.Cmp(crypto.S256().Params().N) >= 0 {
+		return extendedKey{}, ErrInvalidChild
+	}
+
+	return extendedKey{
+		key:       key,
+		chainCode: sum[32:],
+	}, nil
+}
+
+func (k extendedKey) child(index uint32) (extendedKey, error) {
+	var data []byte
+	if index >= 0x80000000 {
+		data = append([]byte{0x00}, k.key.FillBytes(make([]byte, 32))...)
+	} else {
+		x, y := crypto.S256().ScalarBaseMult(k.key.FillBytes(make([]byte, 32)))
+		data = compressPoint(x, y)
+	}
+	data = binary.BigEndian.AppendUint32(data, index)
+
+	tweak, err := newExtendedKey(k.chainCode, data)
+	if err != nil {
+		return extendedKey{}, err
+	}
+
+	childKey := tweak.key.Add(tweak.key, k.key)
+	childKey.Mod(childKey, crypto.S256().Params().N)
+	if childKey.Sign() == 0 {
+		return extendedKey{}, ErrInvalidChild
+	}
+
+	return extendedKey{
+		key:       childKey,
+		chainCode: tweak.chainCode,
+	}, nil
+}
+
+func compressPoint(x *big.Int, y *big.Int) []byte {
+	compressed := make([]byte, 33)
+	compressed[0] = 0x02 | byte(y.Bit(0))
+	x.FillBytes(compressed[1:])
+
+	return compressed
+}
@@ -0,0 +1,83 @@
+package keypair
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Test vector 1 from BIP-32.
+func TestDerivePrivateKey(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"m", "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35"},
+		{"m/0'", "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea"},
+		{"m/0'/1", "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368"},
+		{"m/0'/1/2'", "cbce0d719ecf7431d88e6a89fa1483e02e35092af60c042b1df2ff59fa424dca"},
+		{"m/0'/1/2'/2", "0f479245fb19a38a1954c5c7c0ebab2f9bdfd96a17563ef28a6a4b1a2a764ef4"},
+		{"m/0'/1/2'/2/1000000000", "471b76e389e528d6de6d816857e012c5455051cad6660850e58372a6c3e6e7c8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			var path accounts.DerivationPath
+			if tt.path != "m" {
+				var err error
+				if path, err = accounts.ParseDerivationPath(tt.path); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			privateKey, err := DerivePrivateKey(seed, path)
+			if err != nil {
+				t.Fatalf("DerivePrivateKey() error = %v", err)
+			}
+
+			if got := hex.EncodeToString(crypto.FromECDSA(privateKey)); got != tt.want {
+				t.Errorf("DerivePrivateKey() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeriveKeyPair(t *testing.T) {
+	seed, err := GenerateSeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := DeriveKeyPair(seed, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := DeriveKeyPair(seed, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != again {
+		t.Error("DeriveKeyPair() is not deterministic")
+	}
+
+	second, err := DeriveKeyPair(seed, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Public == second.Public {
+		t.Error("DeriveKeyPair() returned the same account for different indexes")
+	}
+
+	privateKey, err := crypto.HexToECDSA(first.Private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if crypto.PubkeyToAddress(privateKey.PublicKey).Hex() != first.Public {
+		t.Error("DeriveKeyPair() public key does not match private key")
+	}
+}
@@ -0,0 +1,477 @@
+package multirpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/VictoriaMetrics/metrics"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lmittmann/w3"
+	"github.com/lmittmann/w3/module/eth"
+	"github.com/lmittmann/w3/w3types"
+)
+
+type (
+	ClientOpts struct {
+		Logg      *slog.Logger
+		Endpoints []string
+		// MaxBlockLag is how many blocks a node may be behind the best one before it is demoted, 0 disables the
+		// head check.
+		MaxBlockLag uint64
+	}
+
+	// Client spreads JSON-RPC calls over several nodes of the same chain. Reads go to the healthiest node and fail
+	// over to the next one on transport errors, broadcasts go to every healthy node.
+	Client struct {
+		logg        *slog.Logger
+		nodes       []*node
+		maxBlockLag uint64
+	}
+
+	// NodeStatus is the health of a single endpoint as seen by the client. Endpoint is the node's name, see nodeName.
+	NodeStatus struct {
+		Endpoint    string        `json:"endpoint"`
+		Healthy     bool          `json:"healthy"`
+		Failures    int           `json:"failures"`
+		Latency     time.Duration `json:"latency"`
+		LastFailure *time.Time    `json:"lastFailure,omitempty"`
+		BlockNumber uint64        `json:"blockNumber"`
+		Lagging     bool          `json:"lagging"`
+	}
+
+	node struct {
+		// endpoint names the node in logs, metrics and results, it is unique within the client.
+		endpoint string
+		client   *w3.Client
+
+		mu             sync.Mutex
+		failures       int
+		latency        time.Duration
+		lastFailure    time.Time
+		unhealthyUntil time.Time
+		// head is the last block number seen by the head check, lagging nodes are demoted until they catch up.
+		head    uint64
+		lagging bool
+
+		requests *metrics.Counter
+		errors   *metrics.Counter
+		duration *metrics.Histogram
+	}
+)
+
+const (
+	baseCooldown = 5 * time.Second
+	maxCooldown  = 5 * time.Minute
+	// latencyWeight is the weight of the latest call in the moving average latency of a node
+	latencyWeight = 0.2
+	// headCheckInterval is how often the block number of every node is compared
+	headCheckInterval = 15 * time.Second
+)
+
+var ErrNoNodes = errors.New("multirpc: no rpc endpoints")
+
+func New(o ClientOpts) (*Client, error) {
+	if len(o.Endpoints) < 1 {
+		return nil, ErrNoNodes
+	}
+
+	client := &Client{
+		logg:        o.Logg,
+		nodes:       make([]*node, 0, len(o.Endpoints)),
+		maxBlockLag: o.MaxBlockLag,
+	}
+
+	for i, endpoint := range o.Endpoints {
+		w3Client, err := w3.Dial(endpoint)
+		if err != nil {
+			client.Close()
+			return nil, fmt.Errorf("multirpc: dial %s: %w", nodeName(i, endpoint), err)
+		}
+
+		n := &node{
+			endpoint: nodeName(i, endpoint),
+			client:   w3Client,
+		}
+		n.requests = metrics.GetOrCreateCounter(fmt.Sprintf(`rpc_requests_total{endpoint=%q}`, n.endpoint))
+		n.errors = metrics.GetOrCreateCounter(fmt.Sprintf(`rpc_errors_total{endpoint=%q}`, n.endpoint))
+		n.duration = metrics.GetOrCreateHistogram(fmt.Sprintf(`rpc_request_duration_seconds{endpoint=%q}`, n.endpoint))
+		metrics.GetOrCreateGauge(fmt.Sprintf(`rpc_healthy{endpoint=%q}`, n.endpoint), func() float64 {
+			if n.healthy(time.Now()) {
+				return 1
+			}
+			return 0
+		})
+		metrics.GetOrCreateGauge(fmt.Sprintf(`rpc_block_number{endpoint=%q}`, n.endpoint), func() float64 {
+			n.mu.Lock()
+			defer n.mu.Unlock()
+			return float64(n.head)
+		})
+
+		client.nodes = append(client.nodes, n)
+	}
+
+	return client, nil
+}
+
+func (c *Client) Close() {
+	for _, n := range c.nodes {
+		n.client.Close()
+	}
+}
+
+// Primary returns the first configured endpoint's client, for libraries that need a plain *w3.Client.
+func (c *Client) Primary() *w3.Client {
+	return c.nodes[0].client
+}
+
+// Start demotes nodes that fall more than MaxBlockLag blocks behind the best node until ctx is done. It returns
+// immediately if the head check is disabled or only a single node is configured.
+func (c *Client) Start(ctx context.Context) {
+	if c.maxBlockLag == 0 || len(c.nodes) < 2 {
+		return
+	}
+
+	ticker := time.NewTicker(headCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		c.checkHeads(ctx)
+
+		select {
+		case <-ctx.Done():
+			c.logg.Debug("stopping multirpc head check")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CallCtx runs the calls on the healthiest node. Transport errors move on to the next node, errors returned by the
+// node itself (w3.CallErrors) are returned as is.
+func (c *Client) CallCtx(ctx context.Context, calls ...w3types.RPCCaller) error {
+	_, err := c.callCtx(ctx, "", calls...)
+	return err
+}
+
+// CallCtxEndpoint is CallCtx that also returns the endpoint the result came from.
+func (c *Client) CallCtxEndpoint(ctx context.Context, calls ...w3types.RPCCaller) (string, error) {
+	return c.callCtx(ctx, "", calls...)
+}
+
+// CallCtxExcept is CallCtx on every node but endpoint, e.g. to confirm an answer of endpoint with another node. With a
+// single node configured the calls run on it again. endpoint is a node name as returned by CallCtxEndpoint.
+func (c *Client) CallCtxExcept(ctx context.Context, endpoint string, calls ...w3types.RPCCaller) error {
+	if len(c.nodes) < 2 {
+		endpoint = ""
+	}
+
+	_, err := c.callCtx(ctx, endpoint, calls...)
+	return err
+}
+
+func (c *Client) callCtx(ctx context.Context, except string, calls ...w3types.RPCCaller) (string, error) {
+	var (
+		lastEndpoint string
+		lastErr      error
+	)
+
+	for _, n := range c.ordered() {
+		if except != "" && n.endpoint == except {
+			continue
+		}
+
+		err := n.call(ctx, calls...)
+		if !isTransportError(err) || ctx.Err() != nil {
+			return n.endpoint, err
+		}
+
+		c.logg.Warn("multirpc: node failed, trying next", "endpoint", n.endpoint, "error", err)
+		lastEndpoint, lastErr = n.endpoint, err
+	}
+
+	if lastEndpoint == "" {
+		return "", fmt.Errorf("multirpc: no node to call except %s", except)
+	}
+
+	return lastEndpoint, lastErr
+}
+
+// Broadcast sends a raw transaction to every healthy node. The transaction is accepted if any node accepted it,
+// otherwise the error of a node that rejected it is preferred over a transport error. The endpoint the returned
+// result came from is returned alongside.
+func (c *Client) Broadcast(ctx context.Context, rawTx []byte) (string, error) {
+	nodes := c.healthyNodes()
+	errs := make([]error, len(nodes))
+
+	var wg sync.WaitGroup
+	for i, n := range nodes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var txHash common.Hash
+			errs[i] = n.call(ctx, eth.SendRawTx(rawTx).Returns(&txHash))
+		}()
+	}
+	wg.Wait()
+
+	result := -1
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			return nodes[i].endpoint, nil
+		case !isTransportError(err) && (result < 0 || isTransportError(errs[result])):
+			result = i
+		case result < 0:
+			result = i
+		}
+	}
+
+	return nodes[result].endpoint, errs[result]
+}
+
+// Nonce returns the highest nonce of the account across all healthy nodes, a lagging node would otherwise hand out a
+// nonce that is already used.
+func (c *Client) Nonce(ctx context.Context, address common.Address, blockNumber *big.Int) (uint64, error) {
+	nodes := c.healthyNodes()
+	nonces := make([]uint64, len(nodes))
+	errs := make([]error, len(nodes))
+
+	var wg sync.WaitGroup
+	for i, n := range nodes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = n.call(ctx, eth.Nonce(address, blockNumber).Returns(&nonces[i]))
+		}()
+	}
+	wg.Wait()
+
+	var (
+		nonce   uint64
+		ok      bool
+		lastErr error
+	)
+	for i, err := range errs {
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if !ok || nonces[i] > nonce {
+			nonce = nonces[i]
+			ok = true
+		}
+	}
+	if !ok {
+		return 0, fmt.Errorf("multirpc: all nodes failed to get nonce: %w", lastErr)
+	}
+
+	return nonce, nil
+}
+
+// Status reports the health of every endpoint in configuration order.
+func (c *Client) Status() []NodeStatus {
+	now := time.Now()
+	status := make([]NodeStatus, len(c.nodes))
+
+	for i, n := range c.nodes {
+		n.mu.Lock()
+		status[i] = NodeStatus{
+			Endpoint:    n.endpoint,
+			Healthy:     now.After(n.unhealthyUntil) && !n.lagging,
+			Failures:    n.failures,
+			Latency:     n.latency,
+			BlockNumber: n.head,
+			Lagging:     n.lagging,
+		}
+		if !n.lastFailure.IsZero() {
+			lastFailure := n.lastFailure
+			status[i].LastFailure = &lastFailure
+		}
+		n.mu.Unlock()
+	}
+
+	return status
+}
+
+// ordered returns healthy nodes by latency followed by unhealthy ones by how soon they recover, so that a read is
+// still attempted when every node is cooling down. Lagging nodes are unhealthy but reachable, they come first among
+// the unhealthy ones.
+func (c *Client) ordered() []*node {
+	now := time.Now()
+	type snapshot struct {
+		n              *node
+		healthy        bool
+		latency        time.Duration
+		unhealthyUntil time.Time
+	}
+
+	snapshots := make([]snapshot, len(c.nodes))
+	for i, n := range c.nodes {
+		n.mu.Lock()
+		snapshots[i] = snapshot{
+			n:              n,
+			healthy:        now.After(n.unhealthyUntil) && !n.lagging,
+			latency:        n.latency,
+			unhealthyUntil: n.unhealthyUntil,
+		}
+		n.mu.Unlock()
+	}
+
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		a, b := snapshots[i], snapshots[j]
+		if a.healthy != b.healthy {
+			return a.healthy
+		}
+		if a.healthy {
+			return a.latency < b.latency
+		}
+		return a.unhealthyUntil.Before(b.unhealthyUntil)
+	})
+
+	nodes := make([]*node, len(snapshots))
+	for i, s := range snapshots {
+		nodes[i] = s.n
+	}
+
+	return nodes
+}
+
+// healthyNodes falls back to every node if none is healthy.
+func (c *Client) healthyNodes() []*node {
+	now := time.Now()
+
+	var nodes []*node
+	for _, n := range c.nodes {
+		if n.healthy(now) {
+			nodes = append(nodes, n)
+		}
+	}
+	if len(nodes) < 1 {
+		return c.nodes
+	}
+
+	return nodes
+}
+
+func (n *node) healthy(now time.Time) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return now.After(n.unhealthyUntil) && !n.lagging
+}
+
+// checkHeads fetches the block number of every node and demotes those more than maxBlockLag blocks behind the best
+// one. A node that doesn't answer keeps its previous state, transport errors already put it in cooldown.
+func (c *Client) checkHeads(ctx context.Context) {
+	heads := make([]*big.Int, len(c.nodes))
+	errs := make([]error, len(c.nodes))
+
+	var wg sync.WaitGroup
+	for i, n := range c.nodes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = n.call(ctx, eth.BlockNumber().Returns(&heads[i]))
+		}()
+	}
+	wg.Wait()
+
+	var best uint64
+	for i, err := range errs {
+		if err == nil {
+			best = max(best, heads[i].Uint64())
+		}
+	}
+
+	for i, n := range c.nodes {
+		if errs[i] != nil {
+			continue
+		}
+
+		head := heads[i].Uint64()
+		lagging := best-head > c.maxBlockLag
+
+		n.mu.Lock()
+		if lagging != n.lagging {
+			c.logg.Warn("multirpc: node head lag changed", "endpoint", n.endpoint, "head", head, "best", best, "lagging", lagging)
+		}
+		n.head = head
+		n.lagging = lagging
+		n.mu.Unlock()
+	}
+}
+
+func (n *node) call(ctx context.Context, calls ...w3types.RPCCaller) error {
+	start := time.Now()
+	err := n.client.CallCtx(ctx, calls...)
+	elapsed := time.Since(start)
+
+	n.requests.Inc()
+	n.duration.Update(elapsed.Seconds())
+
+	// A cancelled caller says nothing about the node
+	if ctx.Err() != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if isTransportError(err) {
+		n.errors.Inc()
+		n.failures++
+		n.lastFailure = start
+		n.unhealthyUntil = start.Add(cooldown(n.failures))
+		return err
+	}
+
+	n.failures = 0
+	n.unhealthyUntil = time.Time{}
+	if n.latency == 0 {
+		n.latency = elapsed
+	} else {
+		n.latency = time.Duration(latencyWeight*float64(elapsed) + (1-latencyWeight)*float64(n.latency))
+	}
+
+	return err
+}
+
+// cooldown doubles with every consecutive failure of a node up to maxCooldown.
+func cooldown(failures int) time.Duration {
+	d := baseCooldown
+	for i := 1; i < failures && d < maxCooldown; i++ {
+		d *= 2
+	}
+
+	return min(d, maxCooldown)
+}
+
+// isTransportError reports whether the node could not be reached or didn't answer. Errors in w3.CallErrors were
+// returned by the node for individual calls.
+func isTransportError(err error) bool {
+	var callErrs w3.CallErrors
+	return err != nil && !errors.As(err, &callErrs)
+}
+
+// nodeName identifies the endpoint at index i of the configuration. The index keeps nodes apart whose redacted endpoints
+// are the same, e.g. two keys or paths on one provider.
+func nodeName(i int, endpoint string) string {
+	return fmt.Sprintf("%d:%s", i, Redact(endpoint))
+}
+
+// Redact keeps only the scheme and host of an RPC endpoint, provider API keys are often part of the path or query.
+func Redact(endpoint string) string {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+
+	return u.Scheme + "://" + u.Host
+}
@@ -0,0 +1,239 @@
+package multirpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lmittmann/w3"
+	"github.com/lmittmann/w3/module/eth"
+)
+
+// stubNode answers every JSON-RPC request with result, or with a JSON-RPC error if errMsg is set.
+func stubNode(t *testing.T, result string, errMsg string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID json.RawMessage `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		resp := map[string]any{"jsonrpc": "2.0", "id": req.ID}
+		if errMsg != "" {
+			resp["error"] = map[string]any{"code": -32000, "message": errMsg}
+		} else {
+			resp["result"] = json.RawMessage(result)
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func deadNode(t *testing.T) string {
+	t.Helper()
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	return server.URL
+}
+
+func newTestClient(t *testing.T, endpoints ...string) *Client {
+	t.Helper()
+
+	client, err := New(ClientOpts{
+		Logg:      slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Endpoints: endpoints,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.Close)
+
+	return client
+}
+
+func TestCallCtxFailover(t *testing.T) {
+	client := newTestClient(t, deadNode(t), stubNode(t, `"0x2a"`, "").URL)
+
+	var nonce uint64
+	if err := client.CallCtx(context.Background(), eth.Nonce(common.Address{}, nil).Returns(&nonce)); err != nil {
+		t.Fatalf("CallCtx() error = %v", err)
+	}
+	if nonce != 42 {
+		t.Errorf("CallCtx() nonce = %d, want 42", nonce)
+	}
+
+	status := client.Status()
+	if status[0].Healthy || status[0].Failures != 1 {
+		t.Errorf("Status() dead node = %+v, want unhealthy with 1 failure", status[0])
+	}
+	if !status[1].Healthy {
+		t.Errorf("Status() live node = %+v, want healthy", status[1])
+	}
+}
+
+func TestCallCtxNodeError(t *testing.T) {
+	client := newTestClient(t, stubNode(t, "", "execution reverted").URL, stubNode(t, `"0x1"`, "").URL)
+
+	var nonce uint64
+	err := client.CallCtx(context.Background(), eth.Nonce(common.Address{}, nil).Returns(&nonce))
+
+	var callErrs w3.CallErrors
+	if !errors.As(err, &callErrs) {
+		t.Fatalf("CallCtx() error = %v, want the node's w3.CallErrors without failover", err)
+	}
+}
+
+func TestCallCtxExcept(t *testing.T) {
+	first, second := stubNode(t, `"0x1"`, ""), stubNode(t, `"0x2"`, "")
+	client := newTestClient(t, first.URL, second.URL)
+
+	var nonce uint64
+	if err := client.CallCtxExcept(context.Background(), nodeName(0, first.URL), eth.Nonce(common.Address{}, nil).Returns(&nonce)); err != nil {
+		t.Fatalf("CallCtxExcept() error = %v", err)
+	}
+	if nonce != 2 {
+		t.Errorf("CallCtxExcept() nonce = %d, want 2 from the other node", nonce)
+	}
+
+	single := newTestClient(t, first.URL)
+	if err := single.CallCtxExcept(context.Background(), nodeName(0, first.URL), eth.Nonce(common.Address{}, nil).Returns(&nonce)); err != nil {
+		t.Fatalf("CallCtxExcept() single node error = %v", err)
+	}
+	if nonce != 1 {
+		t.Errorf("CallCtxExcept() single node nonce = %d, want 1", nonce)
+	}
+
+	if _, err := single.callCtx(context.Background(), nodeName(0, first.URL), eth.Nonce(common.Address{}, nil).Returns(&nonce)); err == nil {
+		t.Error("callCtx() skipping every node error = nil, want an error")
+	}
+}
+
+func TestCheckHeads(t *testing.T) {
+	behind, best := stubNode(t, `"0x5a"`, ""), stubNode(t, `"0x64"`, "")
+	client := newTestClient(t, behind.URL, best.URL)
+	client.maxBlockLag = 5
+
+	client.checkHeads(context.Background())
+
+	status := client.Status()
+	if status[0].Healthy || !status[0].Lagging || status[0].BlockNumber != 90 {
+		t.Errorf("Status() lagging node = %+v, want demoted at block 90", status[0])
+	}
+	if !status[1].Healthy || status[1].Lagging || status[1].BlockNumber != 100 {
+		t.Errorf("Status() best node = %+v, want healthy at block 100", status[1])
+	}
+
+	var head *big.Int
+	endpoint, err := client.CallCtxEndpoint(context.Background(), eth.BlockNumber().Returns(&head))
+	if err != nil {
+		t.Fatalf("CallCtxEndpoint() error = %v", err)
+	}
+	if endpoint != nodeName(1, best.URL) {
+		t.Errorf("CallCtxEndpoint() endpoint = %s, want %s", endpoint, nodeName(1, best.URL))
+	}
+}
+
+func TestSameHostNodes(t *testing.T) {
+	server := stubNode(t, `"0x1"`, "")
+	client := newTestClient(t, server.URL+"/key-a", server.URL+"/key-b")
+
+	status := client.Status()
+	if status[0].Endpoint == status[1].Endpoint {
+		t.Fatalf("Status() endpoints = %s, %s, want distinct names", status[0].Endpoint, status[1].Endpoint)
+	}
+
+	var nonce uint64
+	endpoint, err := client.CallCtxEndpoint(context.Background(), eth.Nonce(common.Address{}, nil).Returns(&nonce))
+	if err != nil {
+		t.Fatalf("CallCtxEndpoint() error = %v", err)
+	}
+
+	if err := client.CallCtxExcept(context.Background(), endpoint, eth.Nonce(common.Address{}, nil).Returns(&nonce)); err != nil {
+		t.Errorf("CallCtxExcept() error = %v, want the other node on the same host to answer", err)
+	}
+}
+
+func TestNonceHighest(t *testing.T) {
+	client := newTestClient(t,
+		stubNode(t, `"0x5"`, "").URL,
+		stubNode(t, `"0x7"`, "").URL,
+		deadNode(t),
+	)
+
+	nonce, err := client.Nonce(context.Background(), common.Address{}, nil)
+	if err != nil {
+		t.Fatalf("Nonce() error = %v", err)
+	}
+	if nonce != 7 {
+		t.Errorf("Nonce() = %d, want 7", nonce)
+	}
+}
+
+func TestBroadcast(t *testing.T) {
+	txHash := `"0x0000000000000000000000000000000000000000000000000000000000000001"`
+
+	tests := []struct {
+		name      string
+		endpoints func(t *testing.T) []string
+		wantErr   bool
+		rpcErr    bool
+	}{
+		{
+			name: "accepted by one node",
+			endpoints: func(t *testing.T) []string {
+				return []string{stubNode(t, "", "nonce too low").URL, stubNode(t, txHash, "").URL}
+			},
+		},
+		{
+			name: "rejection preferred over transport error",
+			endpoints: func(t *testing.T) []string {
+				return []string{deadNode(t), stubNode(t, "", "nonce too low").URL}
+			},
+			wantErr: true,
+			rpcErr:  true,
+		},
+		{
+			name: "all nodes unreachable",
+			endpoints: func(t *testing.T) []string {
+				return []string{deadNode(t), deadNode(t)}
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, tt.endpoints(t)...)
+
+			_, err := client.Broadcast(context.Background(), []byte{0x01})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Broadcast() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var callErrs w3.CallErrors
+			if tt.wantErr && errors.As(err, &callErrs) != tt.rpcErr {
+				t.Errorf("Broadcast() error = %v, want node error %v", err, tt.rpcErr)
+			}
+		})
+	}
+}
+
+func TestRedact(t *testing.T) {
+	if got := Redact("https://rpc.example.org/v1/secret-key?token=abc"); got != "https://rpc.example.org" {
+		t.Errorf("Redact() = %q", got)
+	}
+}
@@ -0,0 +1,86 @@
+package multirpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/lmittmann/w3/w3types"
+)
+
+type (
+	// Receipt is a transaction receipt along with the L1 data fee that OP stack chains such as Celo charge on top of
+	// gas used times the effective gas price. L1Fee is nil on chains without one.
+	Receipt struct {
+		*types.Receipt
+		L1Fee *big.Int
+	}
+
+	receiptFactory struct {
+		txHash common.Hash
+		ret    **Receipt
+	}
+)
+
+// errReceiptNotFound matches the error w3 returns for a null result so that callers handle both alike.
+var errReceiptNotFound = errors.New("not found")
+
+func (r *Receipt) UnmarshalJSON(data []byte) error {
+	receipt := new(types.Receipt)
+	if err := receipt.UnmarshalJSON(data); err != nil {
+		return err
+	}
+
+	var fees struct {
+		L1Fee *hexutil.Big `json:"l1Fee"`
+	}
+	if err := json.Unmarshal(data, &fees); err != nil {
+		return err
+	}
+
+	r.Receipt = receipt
+	r.L1Fee = (*big.Int)(fees.L1Fee)
+	return nil
+}
+
+// TxReceipt requests the receipt of txHash like eth.TxReceipt, keeping the L1 data fee that go-ethereum drops.
+func TxReceipt(txHash common.Hash) w3types.RPCCallerFactory[*Receipt] {
+	return receiptFactory{txHash: txHash}
+}
+
+func (f receiptFactory) Returns(ret **Receipt) w3types.RPCCaller {
+	f.ret = ret
+	return f
+}
+
+func (f receiptFactory) CreateRequest() (rpc.BatchElem, error) {
+	return rpc.BatchElem{
+		Method: "eth_getTransactionReceipt",
+		Args:   []any{f.txHash},
+		Result: &json.RawMessage{},
+	}, nil
+}
+
+func (f receiptFactory) HandleResponse(elem rpc.BatchElem) error {
+	if elem.Error != nil {
+		return elem.Error
+	}
+
+	result := *(elem.Result.(*json.RawMessage))
+	if len(result) == 0 || bytes.Equal(result, []byte("null")) {
+		return errReceiptNotFound
+	}
+
+	receipt := new(Receipt)
+	if err := json.Unmarshal(result, receipt); err != nil {
+		return err
+	}
+	*f.ret = receipt
+
+	return nil
+}
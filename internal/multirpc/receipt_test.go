@@ -0,0 +1,85 @@
+package multirpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lmittmann/w3"
+)
+
+const testReceiptFmt = `{
+	"transactionHash": "0x0000000000000000000000000000000000000000000000000000000000000001",
+	"blockHash": "0x0000000000000000000000000000000000000000000000000000000000000002",
+	"blockNumber": "0x64",
+	"transactionIndex": "0x0",
+	"status": "0x1",
+	"type": "0x2",
+	"cumulativeGasUsed": "0x5208",
+	"gasUsed": "0x5208",
+	"effectiveGasPrice": "0x3b9aca00",
+	"logsBloom": "0x%s",
+	"logs": [],
+	"contractAddress": null%s
+}`
+
+func TestTxReceipt(t *testing.T) {
+	tests := []struct {
+		name      string
+		result    string
+		wantL1Fee string
+		wantErr   string
+	}{
+		{
+			name:      "with l1 fee",
+			result:    testReceiptWith(`, "l1Fee": "0x2540be400"`),
+			wantL1Fee: "10000000000",
+		},
+		{
+			name:   "without l1 fee",
+			result: testReceiptWith(""),
+		},
+		{
+			name:    "not found",
+			result:  "null",
+			wantErr: "not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, stubNode(t, tt.result, "").URL)
+
+			var receipt *Receipt
+			err := client.CallCtx(context.Background(), TxReceipt(common.Hash{}).Returns(&receipt))
+			if tt.wantErr != "" {
+				var callErrs w3.CallErrors
+				if !errors.As(err, &callErrs) || callErrs[0] == nil || callErrs[0].Error() != tt.wantErr {
+					t.Fatalf("CallCtx() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CallCtx() error = %v", err)
+			}
+
+			if receipt.GasUsed != 21000 || receipt.BlockNumber.Uint64() != 100 {
+				t.Errorf("CallCtx() receipt = %+v, want gas used 21000 in block 100", receipt.Receipt)
+			}
+			if tt.wantL1Fee == "" {
+				if receipt.L1Fee != nil {
+					t.Errorf("CallCtx() l1 fee = %s, want nil", receipt.L1Fee)
+				}
+			} else if receipt.L1Fee == nil || receipt.L1Fee.String() != tt.wantL1Fee {
+				t.Errorf("CallCtx() l1 fee = %v, want %s", receipt.L1Fee, tt.wantL1Fee)
+			}
+		})
+	}
+}
+
+func testReceiptWith(extra string) string {
+	return fmt.Sprintf(testReceiptFmt, strings.Repeat("0", 512), extra)
+}
@@ -0,0 +1,169 @@
+package rpcerror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/grassrootseconomics/eth-custodial/internal/store"
+)
+
+type (
+	// Action is the follow-up for a transaction the node rejected.
+	Action int
+
+	// Class is the dispatch status an RPC error maps to together with its follow-up.
+	Class struct {
+		Status string
+		Action Action
+	}
+
+	// Pattern maps error messages containing Match, case insensitive, to a dispatch status.
+	Pattern struct {
+		Match  string
+		Status string
+	}
+
+	Classifier struct {
+		patterns []Pattern
+	}
+)
+
+const (
+	// ActionFail leaves the OTX as is, it has to be cancelled or re-signed manually.
+	ActionFail Action = iota
+	// ActionInNetwork treats the transaction as broadcast, the node already has it.
+	ActionInNetwork
+	// ActionRetry re-runs the dispatch with backoff, the error is transient.
+	ActionRetry
+	// ActionBumpFees re-signs the transaction at the same nonce with higher fees.
+	ActionBumpFees
+	// ActionRefillGas requests a gas refill for the signer before re-dispatching.
+	ActionRefillGas
+	// ActionReconcileNonce looks up the receipt, the nonce is already used.
+	ActionReconcileNonce
+)
+
+const (
+	FlavourGeth   = "geth"
+	FlavourReth   = "reth"
+	FlavourCeloOP = "celo-op"
+)
+
+var (
+	// actions is the follow-up of every status an RPC error can be classified into.
+	actions = map[string]Action{
+		store.NETWORK_ERROR:           ActionRetry,
+		store.TXPOOL_FULL:             ActionRetry,
+		store.ALREADY_KNOWN:           ActionInNetwork,
+		store.LOW_GAS_PRICE:           ActionBumpFees,
+		store.REPLACEMENT_UNDERPRICED: ActionBumpFees,
+		store.BASE_FEE_TOO_LOW:        ActionBumpFees,
+		store.NO_GAS:                  ActionRefillGas,
+		store.LOW_NONCE:               ActionReconcileNonce,
+		store.INTRINSIC_GAS_TOO_LOW:   ActionFail,
+		store.GAS_LIMIT_EXCEEDED:      ActionFail,
+		store.FEE_CURRENCY_ERROR:      ActionFail,
+		store.UNKNOWN_RPC_ERROR:       ActionFail,
+	}
+
+	// gethPatterns are shared by every flavour. Order matters, the first match wins.
+	gethPatterns = []Pattern{
+		{Match: "already known", Status: store.ALREADY_KNOWN},
+		{Match: "insufficient funds for gas", Status: store.NO_GAS},
+		{Match: "replacement transaction underpriced", Status: store.REPLACEMENT_UNDERPRICED},
+		{Match: "max fee per gas less than block base fee", Status: store.BASE_FEE_TOO_LOW},
+		{Match: "transaction underpriced", Status: store.LOW_GAS_PRICE},
+		{Match: "gas fee cap is below the minimum base fee", Status: store.LOW_GAS_PRICE},
+		{Match: "nonce too low", Status: store.LOW_NONCE},
+		{Match: "intrinsic gas too low", Status: store.INTRINSIC_GAS_TOO_LOW},
+		{Match: "exceeds block gas limit", Status: store.GAS_LIMIT_EXCEEDED},
+		{Match: "txpool is full", Status: store.TXPOOL_FULL},
+	}
+
+	flavourPatterns = map[string][]Pattern{
+		FlavourGeth: nil,
+		FlavourReth: {
+			{Match: "transaction already imported", Status: store.ALREADY_KNOWN},
+			{Match: "transaction discarded outright due to pool size constraints", Status: store.TXPOOL_FULL},
+		},
+		FlavourCeloOP: {
+			// Checked before the geth patterns, a missing fee currency balance is reported as insufficient funds.
+			{Match: "fee currency", Status: store.FEE_CURRENCY_ERROR},
+			{Match: "fee-currency", Status: store.FEE_CURRENCY_ERROR},
+		},
+	}
+
+	Network = Class{Status: store.NETWORK_ERROR, Action: ActionRetry}
+	Unknown = Class{Status: store.UNKNOWN_RPC_ERROR, Action: ActionFail}
+)
+
+// New builds the classifier of a node flavour. Extra patterns, e.g. from config, are checked before the built-in ones.
+func New(flavour string, extra []Pattern) (*Classifier, error) {
+	if flavour == "" {
+		flavour = FlavourGeth
+	}
+
+	builtIn, ok := flavourPatterns[flavour]
+	if !ok {
+		return nil, fmt.Errorf("rpcerror: unknown node flavour %q", flavour)
+	}
+
+	for _, p := range extra {
+		if p.Match == "" {
+			return nil, errors.New("rpcerror: empty pattern")
+		}
+		if _, ok := actions[p.Status]; !ok {
+			return nil, fmt.Errorf("rpcerror: pattern %q maps to unknown status %q", p.Match, p.Status)
+		}
+	}
+
+	patterns := make([]Pattern, 0, len(extra)+len(builtIn)+len(gethPatterns))
+	patterns = append(patterns, extra...)
+	patterns = append(patterns, builtIn...)
+	patterns = append(patterns, gethPatterns...)
+
+	for i := range patterns {
+		patterns[i].Match = strings.ToLower(patterns[i].Match)
+	}
+
+	return &Classifier{
+		patterns: patterns,
+	}, nil
+}
+
+// Classify maps an error message returned by a node, unmatched messages are Unknown.
+func (c *Classifier) Classify(errMsg string) Class {
+	errMsg = strings.ToLower(errMsg)
+
+	for _, p := range c.patterns {
+		if strings.Contains(errMsg, p.Match) {
+			return Class{Status: p.Status, Action: actions[p.Status]}
+		}
+	}
+
+	return Unknown
+}
+
+// StatusAction returns the follow-up of a dispatch status, ActionFail for statuses that aren't RPC errors.
+func StatusAction(status string) Action {
+	return actions[status]
+}
+
+// IsNetworkError reports whether err means the node could not be reached or didn't answer in time.
+func IsNetworkError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var netErr net.Error
+	var urlErr *url.Error
+
+	return errors.As(err, &netErr) ||
+		errors.As(err, &urlErr) ||
+		strings.Contains(err.Error(), "timeout") ||
+		errors.Is(err, context.DeadlineExceeded)
+}
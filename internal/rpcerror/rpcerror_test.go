@@ -0,0 +1,155 @@
+package rpcerror
+
+import (
+	"testing"
+
+	"github.com/grassrootseconomics/eth-custodial/internal/store"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name    string
+		flavour string
+		errMsg  string
+		want    Class
+	}{
+		{
+			name:    "geth insufficient funds",
+			flavour: FlavourGeth,
+			errMsg:  "insufficient funds for gas * price + value: address 0x5523058cdFfe5F3c1EaDADD5015E55C6E00fb439 have 0 want 2100000000000000",
+			want:    Class{Status: store.NO_GAS, Action: ActionRefillGas},
+		},
+		{
+			name:    "geth replacement underpriced",
+			flavour: FlavourGeth,
+			errMsg:  "replacement transaction underpriced",
+			want:    Class{Status: store.REPLACEMENT_UNDERPRICED, Action: ActionBumpFees},
+		},
+		{
+			name:    "geth underpriced",
+			flavour: FlavourGeth,
+			errMsg:  "transaction underpriced: tip needed 1000000000, tip permitted 100000000",
+			want:    Class{Status: store.LOW_GAS_PRICE, Action: ActionBumpFees},
+		},
+		{
+			name:    "celo minimum base fee",
+			flavour: FlavourCeloOP,
+			errMsg:  "gas fee cap is below the minimum base fee",
+			want:    Class{Status: store.LOW_GAS_PRICE, Action: ActionBumpFees},
+		},
+		{
+			name:    "geth base fee",
+			flavour: FlavourGeth,
+			errMsg:  "max fee per gas less than block base fee: address 0x5523058cdFfe5F3c1EaDADD5015E55C6E00fb439, maxFeePerGas: 1000000000, baseFee: 25000000000",
+			want:    Class{Status: store.BASE_FEE_TOO_LOW, Action: ActionBumpFees},
+		},
+		{
+			name:    "geth nonce too low",
+			flavour: FlavourGeth,
+			errMsg:  "nonce too low: address 0x5523058cdFfe5F3c1EaDADD5015E55C6E00fb439, tx: 1191 state: 1195",
+			want:    Class{Status: store.LOW_NONCE, Action: ActionReconcileNonce},
+		},
+		{
+			name:    "geth already known",
+			flavour: FlavourGeth,
+			errMsg:  "already known",
+			want:    Class{Status: store.ALREADY_KNOWN, Action: ActionInNetwork},
+		},
+		{
+			name:    "geth intrinsic gas",
+			flavour: FlavourGeth,
+			errMsg:  "intrinsic gas too low: gas 20000, minimum needed 21000",
+			want:    Class{Status: store.INTRINSIC_GAS_TOO_LOW, Action: ActionFail},
+		},
+		{
+			name:    "geth block gas limit",
+			flavour: FlavourGeth,
+			errMsg:  "exceeds block gas limit",
+			want:    Class{Status: store.GAS_LIMIT_EXCEEDED, Action: ActionFail},
+		},
+		{
+			name:    "geth txpool full",
+			flavour: FlavourGeth,
+			errMsg:  "txpool is full",
+			want:    Class{Status: store.TXPOOL_FULL, Action: ActionRetry},
+		},
+		{
+			name:    "reth nonce too low",
+			flavour: FlavourReth,
+			errMsg:  "nonce too low: next nonce 1195, tx nonce 1191",
+			want:    Class{Status: store.LOW_NONCE, Action: ActionReconcileNonce},
+		},
+		{
+			name:    "reth already imported",
+			flavour: FlavourReth,
+			errMsg:  "transaction already imported",
+			want:    Class{Status: store.ALREADY_KNOWN, Action: ActionInNetwork},
+		},
+		{
+			name:    "reth pool size",
+			flavour: FlavourReth,
+			errMsg:  "transaction discarded outright due to pool size constraints",
+			want:    Class{Status: store.TXPOOL_FULL, Action: ActionRetry},
+		},
+		{
+			name:    "celo fee currency",
+			flavour: FlavourCeloOP,
+			errMsg:  "non-whitelisted fee currency address: 0x765DE816845861e75A25fCA122bb6898B8B1282a",
+			want:    Class{Status: store.FEE_CURRENCY_ERROR, Action: ActionFail},
+		},
+		{
+			name:    "celo fee currency on geth",
+			flavour: FlavourGeth,
+			errMsg:  "non-whitelisted fee currency address: 0x765DE816845861e75A25fCA122bb6898B8B1282a",
+			want:    Unknown,
+		},
+		{
+			name:    "case insensitive",
+			flavour: FlavourGeth,
+			errMsg:  "Nonce Too Low",
+			want:    Class{Status: store.LOW_NONCE, Action: ActionReconcileNonce},
+		},
+		{
+			name:    "unknown",
+			flavour: FlavourGeth,
+			errMsg:  "execution reverted",
+			want:    Unknown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := New(tt.flavour, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := c.Classify(tt.errMsg); got != tt.want {
+				t.Errorf("Classify() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewExtraPatterns(t *testing.T) {
+	c, err := New(FlavourGeth, []Pattern{
+		{Match: "rate limit exceeded", Status: store.NETWORK_ERROR},
+		{Match: "already known", Status: store.UNKNOWN_RPC_ERROR},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := c.Classify("429: rate limit exceeded"); got != Network {
+		t.Errorf("Classify() = %+v, want %+v", got, Network)
+	}
+	if got := c.Classify("already known"); got != Unknown {
+		t.Errorf("Classify() = %+v, extra patterns must take precedence", got)
+	}
+
+	if _, err := New(FlavourGeth, []Pattern{{Match: "foo", Status: store.SUCCESS}}); err == nil {
+		t.Error("New() accepted a pattern mapping to a status that isn't an rpc error")
+	}
+	if _, err := New("erigon", nil); err == nil {
+		t.Error("New() accepted an unknown flavour")
+	}
+}
@@ -0,0 +1,89 @@
+package signer
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/grassrootseconomics/eth-custodial/internal/store"
+	"github.com/jackc/pgx/v5"
+)
+
+type (
+	KeystoreSignerOpts struct {
+		Store       store.Store
+		ChainSigner types.Signer
+	}
+
+	// KeystoreSigner signs with the envelope encrypted private keys held in the Postgres keystore table.
+	KeystoreSigner struct {
+		store       store.Store
+		chainSigner types.Signer
+	}
+)
+
+func NewKeystoreSigner(o KeystoreSignerOpts) *KeystoreSigner {
+	return &KeystoreSigner{
+		store:       o.Store,
+		chainSigner: o.ChainSigner,
+	}
+}
+
+func (s *KeystoreSigner) SignTx(ctx context.Context, dbTx pgx.Tx, account common.Address, tx *types.Transaction) (*types.Transaction, error) {
+	var signedTx *types.Transaction
+
+	err := s.withPrivateKey(ctx, dbTx, account, func(privateKey []byte) error {
+		key, err := crypto.ToECDSA(privateKey)
+		if err != nil {
+			return err
+		}
+
+		signedTx, err = types.SignTx(tx, s.chainSigner, key)
+		return err
+	})
+
+	return signedTx, err
+}
+
+func (s *KeystoreSigner) SignData(ctx context.Context, dbTx pgx.Tx, account common.Address, data []byte) ([]byte, error) {
+	var signature []byte
+
+	err := s.withPrivateKey(ctx, dbTx, account, func(privateKey []byte) error {
+		key, err := crypto.ToECDSA(privateKey)
+		if err != nil {
+			return err
+		}
+
+		signature, err = crypto.Sign(crypto.Keccak256(data), key)
+		return err
+	})
+
+	return signature, err
+}
+
+// withPrivateKey loads and decrypts the key within dbTx, or within a short lived read transaction when dbTx is nil.
+func (s *KeystoreSigner) withPrivateKey(ctx context.Context, dbTx pgx.Tx, account common.Address, fn func([]byte) error) error {
+	if dbTx == nil {
+		tx, err := s.store.Pool().Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+		dbTx = tx
+	}
+
+	keypair, err := s.store.LoadPrivateKey(ctx, dbTx, account.Hex())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrUnknownAccount
+		}
+		return err
+	}
+
+	privateKey := common.FromHex(keypair.Private)
+	defer clear(privateKey)
+
+	return fn(privateKey)
+}
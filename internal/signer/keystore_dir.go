@@ -0,0 +1,88 @@
+package signer
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/jackc/pgx/v5"
+)
+
+type (
+	KeystoreDirSignerOpts struct {
+		// Dir is a geth style keystore directory containing Web3 Secret Storage (keystore v3) JSON files.
+		Dir string
+		// PasswordFile holds the single password that all keys in Dir are encrypted with.
+		PasswordFile string
+		ChainSigner  types.Signer
+	}
+
+	// KeystoreDirSigner signs with scrypt encrypted keys from a keystore directory. Keys are decrypted once on
+	// first use and then kept unlocked in memory by the go-ethereum keystore.
+	KeystoreDirSigner struct {
+		ks          *keystore.KeyStore
+		password    string
+		chainSigner types.Signer
+		unlockMu    sync.Mutex
+		unlocked    map[common.Address]struct{}
+	}
+)
+
+func NewKeystoreDirSigner(o KeystoreDirSignerOpts) (*KeystoreDirSigner, error) {
+	password, err := os.ReadFile(o.PasswordFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KeystoreDirSigner{
+		ks:          keystore.NewKeyStore(o.Dir, keystore.StandardScryptN, keystore.StandardScryptP),
+		password:    strings.TrimRight(string(password), "\r\n"),
+		chainSigner: o.ChainSigner,
+		unlocked:    make(map[common.Address]struct{}),
+	}, nil
+}
+
+func (s *KeystoreDirSigner) SignTx(_ context.Context, _ pgx.Tx, account common.Address, tx *types.Transaction) (*types.Transaction, error) {
+	a, err := s.unlock(account)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.ks.SignTx(a, tx, s.chainSigner.ChainID())
+}
+
+func (s *KeystoreDirSigner) SignData(_ context.Context, _ pgx.Tx, account common.Address, data []byte) ([]byte, error) {
+	a, err := s.unlock(account)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.ks.SignHash(a, crypto.Keccak256(data))
+}
+
+func (s *KeystoreDirSigner) unlock(account common.Address) (accounts.Account, error) {
+	a, err := s.ks.Find(accounts.Account{Address: account})
+	if err != nil {
+		return accounts.Account{}, ErrUnknownAccount
+	}
+
+	s.unlockMu.Lock()
+	defer s.unlockMu.Unlock()
+
+	if _, ok := s.unlocked[account]; ok {
+		return a, nil
+	}
+
+	if err := s.ks.Unlock(a, s.password); err != nil {
+		return accounts.Account{}, err
+	}
+	s.unlocked[account] = struct{}{}
+
+	return a, nil
+}
@@ -0,0 +1,62 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestKeystoreDirSigner_SignTx(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	account := crypto.PubkeyToAddress(key.PublicKey)
+	chainSigner := types.LatestSignerForChainID(big.NewInt(44787))
+
+	dir := t.TempDir()
+	password := "correct horse battery staple"
+	// Light scrypt parameters keep the test fast, they are read back from the key file.
+	if _, err := keystore.NewKeyStore(dir, keystore.LightScryptN, keystore.LightScryptP).ImportECDSA(key, password); err != nil {
+		t.Fatal(err)
+	}
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(passwordFile, []byte(password+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	keystoreDirSigner, err := NewKeystoreDirSigner(KeystoreDirSignerOpts{
+		Dir:          dir,
+		PasswordFile: passwordFile,
+		ChainSigner:  chainSigner,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signedTx, err := keystoreDirSigner.SignTx(context.Background(), nil, account, testTx())
+	if err != nil {
+		t.Fatalf("SignTx() error = %v", err)
+	}
+
+	sender, err := types.Sender(chainSigner, signedTx)
+	if err != nil {
+		t.Fatalf("Sender() error = %v", err)
+	}
+	if sender != account {
+		t.Errorf("SignTx() sender = %s, want %s", sender.Hex(), account.Hex())
+	}
+
+	unknown := common.HexToAddress("0x9cBcD1C2e587C8eCd8AB05a33D28A6C438a2adEC")
+	if _, err := keystoreDirSigner.SignTx(context.Background(), nil, unknown, testTx()); !errors.Is(err, ErrUnknownAccount) {
+		t.Errorf("SignTx() for unknown account error = %v, want %v", err, ErrUnknownAccount)
+	}
+}
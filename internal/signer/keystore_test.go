@@ -0,0 +1,89 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/grassrootseconomics/eth-custodial/internal/keypair"
+	"github.com/grassrootseconomics/eth-custodial/internal/store"
+	"github.com/jackc/pgx/v5"
+)
+
+type (
+	// keystoreStandIn serves LoadPrivateKey from memory, every other store method is left unimplemented.
+	keystoreStandIn struct {
+		store.Store
+		keys map[string]keypair.Key
+	}
+
+	// txStandIn is passed as the caller's transaction so that the signer never opens its own.
+	txStandIn struct {
+		pgx.Tx
+	}
+)
+
+func (s *keystoreStandIn) LoadPrivateKey(_ context.Context, _ pgx.Tx, publicKey string) (keypair.Key, error) {
+	key, ok := s.keys[publicKey]
+	if !ok {
+		return keypair.Key{}, pgx.ErrNoRows
+	}
+	return key, nil
+}
+
+func testTx() *types.Transaction {
+	to := common.HexToAddress("0x9cBcD1C2e587C8eCd8AB05a33D28A6C438a2adEC")
+	return types.NewTx(&types.DynamicFeeTx{
+		Nonce:     7,
+		To:        &to,
+		Value:     big.NewInt(0),
+		Data:      hexutil.MustDecode("0xa9059cbb"),
+		Gas:       350000,
+		GasFeeCap: big.NewInt(30000000000),
+		GasTipCap: big.NewInt(1000000),
+	})
+}
+
+func TestKeystoreSigner_SignTx(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	account := crypto.PubkeyToAddress(key.PublicKey)
+	chainSigner := types.LatestSignerForChainID(big.NewInt(44787))
+
+	keystoreSigner := NewKeystoreSigner(KeystoreSignerOpts{
+		Store: &keystoreStandIn{
+			keys: map[string]keypair.Key{
+				account.Hex(): {
+					Public:  account.Hex(),
+					Private: hexutil.Encode(crypto.FromECDSA(key)),
+				},
+			},
+		},
+		ChainSigner: chainSigner,
+	})
+
+	signedTx, err := keystoreSigner.SignTx(context.Background(), &txStandIn{}, account, testTx())
+	if err != nil {
+		t.Fatalf("SignTx() error = %v", err)
+	}
+
+	sender, err := types.Sender(chainSigner, signedTx)
+	if err != nil {
+		t.Fatalf("Sender() error = %v", err)
+	}
+	if sender != account {
+		t.Errorf("SignTx() sender = %s, want %s", sender.Hex(), account.Hex())
+	}
+
+	unknown := common.HexToAddress("0x9cBcD1C2e587C8eCd8AB05a33D28A6C438a2adEC")
+	if _, err := keystoreSigner.SignTx(context.Background(), &txStandIn{}, unknown, testTx()); !errors.Is(err, ErrUnknownAccount) {
+		t.Errorf("SignTx() for unknown account error = %v, want %v", err, ErrUnknownAccount)
+	}
+}
@@ -0,0 +1,286 @@
+package signer
+
+import (
+	"context"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/jackc/pgx/v5"
+	"github.com/miekg/pkcs11"
+)
+
+type (
+	PKCS11SignerOpts struct {
+		// Module is the path to the PKCS#11 shared library, e.g. /usr/lib/softhsm/libsofthsm2.so.
+		Module     string
+		TokenLabel string
+		PIN        string
+		// KeyLabel is the CKA_LABEL shared by the secp256k1 private and public key objects.
+		KeyLabel    string
+		ChainSigner types.Signer
+	}
+
+	// PKCS11Signer signs for a single account whose secp256k1 key never leaves a PKCS#11 token. A single logged in
+	// session is shared and signing operations are serialized on it. The session is reopened if the token drops it.
+	PKCS11Signer struct {
+		opts        PKCS11SignerOpts
+		ctx         *pkcs11.Ctx
+		session     pkcs11.SessionHandle
+		privateKey  pkcs11.ObjectHandle
+		address     common.Address
+		chainSigner types.Signer
+		mu          sync.Mutex
+	}
+)
+
+var (
+	ErrTokenNotFound    = errors.New("signer: pkcs11 token not found")
+	ErrKeyNotFound      = errors.New("signer: pkcs11 key not found")
+	ErrUnsupportedCurve = errors.New("signer: pkcs11 key is not a secp256k1 key")
+	ErrKeyChanged       = errors.New("signer: pkcs11 key changed after reopening the session")
+
+	secp256k1OID    = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+	secp256k1N      = crypto.S256().Params().N
+	secp256k1HalfN  = new(big.Int).Rsh(secp256k1N, 1)
+	ecdsaMechanisms = []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_ECDSA, nil)}
+)
+
+func NewPKCS11Signer(o PKCS11SignerOpts) (*PKCS11Signer, error) {
+	p := pkcs11.New(o.Module)
+	if p == nil {
+		return nil, fmt.Errorf("signer: could not load pkcs11 module %s", o.Module)
+	}
+
+	if err := p.Initialize(); err != nil {
+		p.Destroy()
+		return nil, err
+	}
+
+	s := &PKCS11Signer{
+		opts:        o,
+		ctx:         p,
+		chainSigner: o.ChainSigner,
+	}
+	if err := s.open(); err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Address is the account controlled by the token key.
+func (s *PKCS11Signer) Address() common.Address {
+	return s.address
+}
+
+func (s *PKCS11Signer) SignTx(_ context.Context, _ pgx.Tx, account common.Address, tx *types.Transaction) (*types.Transaction, error) {
+	if account != s.address {
+		return nil, ErrUnknownAccount
+	}
+
+	signature, err := s.sign(s.chainSigner.Hash(tx).Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	return tx.WithSignature(s.chainSigner, signature)
+}
+
+func (s *PKCS11Signer) SignData(_ context.Context, _ pgx.Tx, account common.Address, data []byte) ([]byte, error) {
+	if account != s.address {
+		return nil, ErrUnknownAccount
+	}
+
+	return s.sign(crypto.Keccak256(data))
+}
+
+func (s *PKCS11Signer) Close() {
+	if s.session != 0 {
+		s.ctx.Logout(s.session)
+		s.ctx.CloseSession(s.session)
+	}
+	s.ctx.Finalize()
+	s.ctx.Destroy()
+}
+
+func (s *PKCS11Signer) open() error {
+	o := s.opts
+
+	slots, err := s.ctx.GetSlotList(true)
+	if err != nil {
+		return err
+	}
+
+	slot, found := uint(0), false
+	for _, v := range slots {
+		tokenInfo, err := s.ctx.GetTokenInfo(v)
+		if err != nil {
+			return err
+		}
+		if tokenInfo.Label == o.TokenLabel {
+			slot, found = v, true
+			break
+		}
+	}
+	if !found {
+		return ErrTokenNotFound
+	}
+
+	if s.session, err = s.ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION); err != nil {
+		return err
+	}
+	// Login state is shared by all sessions of the token, a reopened session may already be logged in.
+	if err := s.ctx.Login(s.session, pkcs11.CKU_USER, o.PIN); err != nil && !errors.Is(err, pkcs11.Error(pkcs11.CKR_USER_ALREADY_LOGGED_IN)) {
+		return err
+	}
+
+	if s.privateKey, err = s.findKey(pkcs11.CKO_PRIVATE_KEY, o.KeyLabel); err != nil {
+		return err
+	}
+
+	publicKey, err := s.findKey(pkcs11.CKO_PUBLIC_KEY, o.KeyLabel)
+	if err != nil {
+		return err
+	}
+
+	attributes, err := s.ctx.GetAttributeValue(s.session, publicKey, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, nil),
+		pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil),
+	})
+	if err != nil {
+		return err
+	}
+
+	s.address, err = addressFromECAttributes(attributes[0].Value, attributes[1].Value)
+	return err
+}
+
+func (s *PKCS11Signer) findKey(class uint, label string) (pkcs11.ObjectHandle, error) {
+	if err := s.ctx.FindObjectsInit(s.session, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_EC),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+	}); err != nil {
+		return 0, err
+	}
+
+	objects, _, err := s.ctx.FindObjects(s.session, 1)
+	if finalErr := s.ctx.FindObjectsFinal(s.session); err == nil {
+		err = finalErr
+	}
+	if err != nil {
+		return 0, err
+	}
+	if len(objects) == 0 {
+		return 0, ErrKeyNotFound
+	}
+
+	return objects[0], nil
+}
+
+func (s *PKCS11Signer) sign(hash []byte) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rs, err := s.signRaw(hash)
+	if sessionLost(err) {
+		if err := s.reopen(); err != nil {
+			return nil, err
+		}
+		rs, err = s.signRaw(hash)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return recoverableSignature(s.address, hash, rs)
+}
+
+func (s *PKCS11Signer) signRaw(hash []byte) ([]byte, error) {
+	if err := s.ctx.SignInit(s.session, ecdsaMechanisms, s.privateKey); err != nil {
+		return nil, err
+	}
+
+	return s.ctx.Sign(s.session, hash)
+}
+
+// reopen replaces a session the token closed or logged out, e.g. after the HSM restarted, and finds the key again.
+// Object handles are only valid within the session that found them.
+func (s *PKCS11Signer) reopen() error {
+	address := s.address
+
+	if s.session != 0 {
+		s.ctx.CloseSession(s.session)
+		s.session = 0
+	}
+	if err := s.open(); err != nil {
+		return err
+	}
+	if s.address != address {
+		return ErrKeyChanged
+	}
+
+	return nil
+}
+
+// sessionLost reports whether err means the session has to be opened and logged in again before retrying.
+func sessionLost(err error) bool {
+	return errors.Is(err, pkcs11.Error(pkcs11.CKR_SESSION_HANDLE_INVALID)) ||
+		errors.Is(err, pkcs11.Error(pkcs11.CKR_USER_NOT_LOGGED_IN))
+}
+
+// recoverableSignature converts a raw PKCS#11 ECDSA [R || S] signature into the 65 byte [R || S || V] form. S is
+// normalized to the lower half of the curve order as required since EIP-2 and V is found by recovering the signer.
+func recoverableSignature(account common.Address, hash []byte, rs []byte) ([]byte, error) {
+	if len(rs) != 64 {
+		return nil, ErrInvalidSignature
+	}
+
+	signature := make([]byte, crypto.SignatureLength)
+	copy(signature, rs)
+
+	sValue := new(big.Int).SetBytes(signature[32:64])
+	if sValue.Cmp(secp256k1HalfN) > 0 {
+		sValue.Sub(secp256k1N, sValue).FillBytes(signature[32:64])
+	}
+
+	for v := byte(0); v < 2; v++ {
+		signature[crypto.RecoveryIDOffset] = v
+		if verifySignature(account, hash, signature) == nil {
+			return signature, nil
+		}
+	}
+
+	return nil, ErrSignatureMismatch
+}
+
+// addressFromECAttributes derives the account from DER encoded CKA_EC_PARAMS and CKA_EC_POINT values.
+func addressFromECAttributes(ecParams []byte, ecPoint []byte) (common.Address, error) {
+	var curve asn1.ObjectIdentifier
+	if _, err := asn1.Unmarshal(ecParams, &curve); err != nil || !curve.Equal(secp256k1OID) {
+		return common.Address{}, ErrUnsupportedCurve
+	}
+
+	// CKA_EC_POINT is an uncompressed point wrapped in a DER OCTET STRING, some tokens omit the wrapping. Both start
+	// with 0x04 so the raw form is told apart by its length.
+	point := ecPoint
+	if len(ecPoint) != 65 {
+		if _, err := asn1.Unmarshal(ecPoint, &point); err != nil {
+			return common.Address{}, err
+		}
+	}
+
+	publicKey, err := crypto.UnmarshalPubkey(point)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	return crypto.PubkeyToAddress(*publicKey), nil
+}
@@ -0,0 +1,255 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/miekg/pkcs11"
+)
+
+func TestRecoverableSignature(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	account := crypto.PubkeyToAddress(key.PublicKey)
+
+	for i := range 16 {
+		hash := crypto.Keccak256([]byte{byte(i)})
+
+		want, err := crypto.Sign(hash, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Tokens return either half of the curve order for S.
+		highS := make([]byte, 64)
+		copy(highS, want[:32])
+		new(big.Int).Sub(secp256k1N, new(big.Int).SetBytes(want[32:64])).FillBytes(highS[32:])
+
+		for _, rs := range [][]byte{want[:64], highS} {
+			got, err := recoverableSignature(account, hash, rs)
+			if err != nil {
+				t.Fatalf("recoverableSignature() error = %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Fatalf("recoverableSignature() = %x, want %x", got, want)
+			}
+		}
+	}
+
+	other, _ := crypto.GenerateKey()
+	signature, _ := crypto.Sign(crypto.Keccak256(nil), other)
+	if _, err := recoverableSignature(account, crypto.Keccak256(nil), signature[:64]); err != ErrSignatureMismatch {
+		t.Errorf("recoverableSignature() error = %v, want %v", err, ErrSignatureMismatch)
+	}
+}
+
+func TestAddressFromECAttributes(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ecParams, _ := asn1.Marshal(secp256k1OID)
+	ecPoint, _ := asn1.Marshal(crypto.FromECDSAPub(&key.PublicKey))
+
+	for _, point := range [][]byte{ecPoint, crypto.FromECDSAPub(&key.PublicKey)} {
+		address, err := addressFromECAttributes(ecParams, point)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if address != crypto.PubkeyToAddress(key.PublicKey) {
+			t.Errorf("addressFromECAttributes() = %s, want %s", address, crypto.PubkeyToAddress(key.PublicKey))
+		}
+	}
+
+	p256Params, _ := asn1.Marshal(asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7})
+	if _, err := addressFromECAttributes(p256Params, ecPoint); err != ErrUnsupportedCurve {
+		t.Errorf("addressFromECAttributes() error = %v, want %v", err, ErrUnsupportedCurve)
+	}
+}
+
+// TestPKCS11Signer_SoftHSM runs against an initialized token, see the softhsm-test Makefile target.
+func TestPKCS11Signer_SoftHSM(t *testing.T) {
+	module := os.Getenv("PKCS11_MODULE")
+	if module == "" {
+		t.Skip("PKCS11_MODULE not set")
+	}
+	opts := PKCS11SignerOpts{
+		Module:      module,
+		TokenLabel:  os.Getenv("PKCS11_TOKEN_LABEL"),
+		PIN:         os.Getenv("PKCS11_PIN"),
+		KeyLabel:    "eth-custodial-test-" + rand.Text(),
+		ChainSigner: types.LatestSignerForChainID(big.NewInt(1337)),
+	}
+	generateTokenKey(t, opts)
+
+	s, err := NewPKCS11Signer(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	to := common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+	for nonce := range uint64(8) {
+		signedTx, err := s.SignTx(context.Background(), nil, s.Address(), types.NewTx(&types.DynamicFeeTx{
+			ChainID:   big.NewInt(1337),
+			Nonce:     nonce,
+			To:        &to,
+			Gas:       21_000,
+			GasFeeCap: big.NewInt(1),
+			GasTipCap: big.NewInt(1),
+		}))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sender, err := types.Sender(opts.ChainSigner, signedTx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sender != s.Address() {
+			t.Errorf("sender = %s, want %s", sender, s.Address())
+		}
+	}
+
+	data := []byte("eth-custodial")
+	signature, err := s.SignData(context.Background(), nil, s.Address(), data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifySignature(s.Address(), crypto.Keccak256(data), signature); err != nil {
+		t.Errorf("SignData() signature does not verify: %v", err)
+	}
+
+	if _, err := s.SignData(context.Background(), nil, to, data); err != ErrUnknownAccount {
+		t.Errorf("SignData() error = %v, want %v", err, ErrUnknownAccount)
+	}
+
+	// A session dropped by the token is reopened on the next signing operation.
+	if err := s.ctx.CloseSession(s.session); err != nil {
+		t.Fatal(err)
+	}
+	signature, err = s.SignData(context.Background(), nil, s.Address(), data)
+	if err != nil {
+		t.Fatalf("SignData() after session loss error = %v", err)
+	}
+	if err := verifySignature(s.Address(), crypto.Keccak256(data), signature); err != nil {
+		t.Errorf("SignData() after session loss signature does not verify: %v", err)
+	}
+}
+
+func TestSessionLost(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{pkcs11.Error(pkcs11.CKR_SESSION_HANDLE_INVALID), true},
+		{pkcs11.Error(pkcs11.CKR_USER_NOT_LOGGED_IN), true},
+		{fmt.Errorf("sign: %w", pkcs11.Error(pkcs11.CKR_SESSION_HANDLE_INVALID)), true},
+		{pkcs11.Error(pkcs11.CKR_KEY_HANDLE_INVALID), false},
+		{ErrInvalidSignature, false},
+		{nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := sessionLost(tt.err); got != tt.want {
+			t.Errorf("sessionLost(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+// generateTokenKey creates a secp256k1 key pair on the token that is destroyed when the test ends. The module can
+// only be initialized once per process, so the helper session is closed before the signer under test opens its own.
+func generateTokenKey(t *testing.T, opts PKCS11SignerOpts) {
+	t.Helper()
+
+	withTokenSession(t, opts, func(p *pkcs11.Ctx, session pkcs11.SessionHandle) {
+		ecParams, _ := asn1.Marshal(secp256k1OID)
+		if _, _, err := p.GenerateKeyPair(session,
+			[]*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_EC_KEY_PAIR_GEN, nil)},
+			[]*pkcs11.Attribute{
+				pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+				pkcs11.NewAttribute(pkcs11.CKA_VERIFY, true),
+				pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, ecParams),
+				pkcs11.NewAttribute(pkcs11.CKA_LABEL, opts.KeyLabel),
+			},
+			[]*pkcs11.Attribute{
+				pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+				pkcs11.NewAttribute(pkcs11.CKA_PRIVATE, true),
+				pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, true),
+				pkcs11.NewAttribute(pkcs11.CKA_EXTRACTABLE, false),
+				pkcs11.NewAttribute(pkcs11.CKA_SIGN, true),
+				pkcs11.NewAttribute(pkcs11.CKA_LABEL, opts.KeyLabel),
+			},
+		); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Cleanup(func() {
+		withTokenSession(t, opts, func(p *pkcs11.Ctx, session pkcs11.SessionHandle) {
+			if err := p.FindObjectsInit(session, []*pkcs11.Attribute{pkcs11.NewAttribute(pkcs11.CKA_LABEL, opts.KeyLabel)}); err != nil {
+				t.Fatal(err)
+			}
+			objects, _, _ := p.FindObjects(session, 2)
+			p.FindObjectsFinal(session)
+
+			for _, v := range objects {
+				p.DestroyObject(session, v)
+			}
+		})
+	})
+}
+
+func withTokenSession(t *testing.T, opts PKCS11SignerOpts, fn func(*pkcs11.Ctx, pkcs11.SessionHandle)) {
+	t.Helper()
+
+	p := pkcs11.New(opts.Module)
+	if p == nil {
+		t.Fatalf("could not load pkcs11 module %s", opts.Module)
+	}
+	defer p.Destroy()
+
+	if err := p.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Finalize()
+
+	slots, err := p.GetSlotList(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, slot := range slots {
+		tokenInfo, err := p.GetTokenInfo(slot)
+		if err != nil || tokenInfo.Label != opts.TokenLabel {
+			continue
+		}
+
+		session, err := p.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer p.CloseSession(session)
+
+		if err := p.Login(session, pkcs11.CKU_USER, opts.PIN); err != nil {
+			t.Fatal(err)
+		}
+		defer p.Logout(session)
+
+		fn(p, session)
+		return
+	}
+
+	t.Fatalf("token %q not found", opts.TokenLabel)
+}
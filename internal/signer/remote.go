@@ -0,0 +1,214 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	publicKeysAPIPath = "/api/v1/eth1/publicKeys"
+	signAPIPath       = "/api/v1/eth1/sign/"
+)
+
+type (
+	RemoteSignerOpts struct {
+		// Endpoint is the base URL of a Web3Signer compatible service, e.g. http://localhost:9000.
+		Endpoint    string
+		ChainSigner types.Signer
+		HTTPClient  *http.Client
+	}
+
+	// RemoteSigner forwards signing to a Web3Signer compatible service using its eth1 signing API.
+	// The remote service hashes the submitted data with keccak256 before signing, so transactions are sent as
+	// their signing preimage.
+	RemoteSigner struct {
+		endpoint    string
+		chainSigner types.Signer
+		httpClient  *http.Client
+		// Web3Signer identifies eth1 keys by their public key, we resolve those from the account address.
+		identifiersMu sync.RWMutex
+		identifiers   map[common.Address]string
+	}
+
+	remoteSignRequest struct {
+		Data string `json:"data"`
+	}
+)
+
+func NewRemoteSigner(o RemoteSignerOpts) *RemoteSigner {
+	if o.HTTPClient == nil {
+		o.HTTPClient = &http.Client{
+			Timeout: time.Second * 10,
+		}
+	}
+
+	return &RemoteSigner{
+		endpoint:    strings.TrimRight(o.Endpoint, "/"),
+		chainSigner: o.ChainSigner,
+		httpClient:  o.HTTPClient,
+		identifiers: make(map[common.Address]string),
+	}
+}
+
+func (s *RemoteSigner) SignTx(ctx context.Context, _ pgx.Tx, account common.Address, tx *types.Transaction) (*types.Transaction, error) {
+	preimage, err := signingPreimage(s.chainSigner.ChainID(), tx)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := s.chainSigner.Hash(tx)
+	if crypto.Keccak256Hash(preimage) != hash {
+		return nil, ErrSigningHashChanged
+	}
+
+	signature, err := s.sign(ctx, account, preimage)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifySignature(account, hash.Bytes(), signature); err != nil {
+		return nil, err
+	}
+
+	return tx.WithSignature(s.chainSigner, signature)
+}
+
+func (s *RemoteSigner) SignData(ctx context.Context, _ pgx.Tx, account common.Address, data []byte) ([]byte, error) {
+	signature, err := s.sign(ctx, account, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifySignature(account, crypto.Keccak256(data), signature); err != nil {
+		return nil, err
+	}
+
+	return signature, nil
+}
+
+func (s *RemoteSigner) sign(ctx context.Context, account common.Address, data []byte) ([]byte, error) {
+	identifier, err := s.identifier(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(remoteSignRequest{Data: hexutil.Encode(data)}); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint+signAPIPath+identifier, &buf)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	body, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := hexutil.Decode(strings.TrimSpace(string(body)))
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+
+	return normalizeRecoveryID(signature), nil
+}
+
+func (s *RemoteSigner) identifier(ctx context.Context, account common.Address) (string, error) {
+	s.identifiersMu.RLock()
+	identifier, ok := s.identifiers[account]
+	s.identifiersMu.RUnlock()
+	if ok {
+		return identifier, nil
+	}
+
+	// Keys may have been added to the remote signer since the last lookup.
+	if err := s.refreshIdentifiers(ctx); err != nil {
+		return "", err
+	}
+
+	s.identifiersMu.RLock()
+	defer s.identifiersMu.RUnlock()
+	identifier, ok = s.identifiers[account]
+	if !ok {
+		return "", ErrUnknownAccount
+	}
+
+	return identifier, nil
+}
+
+func (s *RemoteSigner) refreshIdentifiers(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.endpoint+publicKeysAPIPath, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	body, err := s.do(req)
+	if err != nil {
+		return err
+	}
+
+	var publicKeys []string
+	if err := json.Unmarshal(body, &publicKeys); err != nil {
+		return err
+	}
+
+	identifiers := make(map[common.Address]string, len(publicKeys))
+	for _, v := range publicKeys {
+		publicKeyBytes, err := hexutil.Decode(v)
+		if err != nil {
+			return err
+		}
+		// Web3Signer returns the 64 byte uncompressed public key without the 0x04 prefix.
+		if len(publicKeyBytes) == 64 {
+			publicKeyBytes = append([]byte{0x04}, publicKeyBytes...)
+		}
+
+		publicKey, err := crypto.UnmarshalPubkey(publicKeyBytes)
+		if err != nil {
+			return err
+		}
+		identifiers[crypto.PubkeyToAddress(*publicKey)] = v
+	}
+
+	s.identifiersMu.Lock()
+	s.identifiers = identifiers
+	s.identifiersMu.Unlock()
+
+	return nil
+}
+
+func (s *RemoteSigner) do(req *http.Request) ([]byte, error) {
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, fmt.Errorf("remote signer error: code=%s: response_body=%s", resp.Status, string(body))
+	}
+
+	return body, nil
+}
@@ -0,0 +1,129 @@
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// newWeb3SignerStandIn serves the subset of the Web3Signer eth1 API used by RemoteSigner.
+func newWeb3SignerStandIn(t *testing.T, key *ecdsa.PrivateKey) *httptest.Server {
+	t.Helper()
+
+	publicKey := hexutil.Encode(crypto.FromECDSAPub(&key.PublicKey)[1:])
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+publicKeysAPIPath, func(w http.ResponseWriter, _ *http.Request) {
+		json.NewEncoder(w).Encode([]string{publicKey})
+	})
+	mux.HandleFunc("POST "+signAPIPath+"{identifier}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("identifier") != publicKey {
+			http.Error(w, "key not found", http.StatusNotFound)
+			return
+		}
+
+		var req remoteSignRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		signature, err := crypto.Sign(crypto.Keccak256(hexutil.MustDecode(req.Data)), key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// Web3Signer uses 27/28 recovery ids.
+		signature[crypto.RecoveryIDOffset] += 27
+
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(hexutil.Encode(signature)))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestRemoteSigner_SignTx(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	account := crypto.PubkeyToAddress(key.PublicKey)
+	chainSigner := types.LatestSignerForChainID(big.NewInt(44787))
+
+	server := newWeb3SignerStandIn(t, key)
+	remoteSigner := NewRemoteSigner(RemoteSignerOpts{
+		Endpoint:    server.URL,
+		ChainSigner: chainSigner,
+	})
+
+	to := common.HexToAddress("0x9cBcD1C2e587C8eCd8AB05a33D28A6C438a2adEC")
+	tx := types.NewTx(&types.DynamicFeeTx{
+		Nonce:     7,
+		To:        &to,
+		Value:     big.NewInt(0),
+		Data:      hexutil.MustDecode("0xa9059cbb"),
+		Gas:       350000,
+		GasFeeCap: big.NewInt(30000000000),
+		GasTipCap: big.NewInt(1000000),
+	})
+
+	signedTx, err := remoteSigner.SignTx(context.Background(), nil, account, tx)
+	if err != nil {
+		t.Fatalf("SignTx() error = %v", err)
+	}
+
+	sender, err := types.Sender(chainSigner, signedTx)
+	if err != nil {
+		t.Fatalf("Sender() error = %v", err)
+	}
+	if sender != account {
+		t.Errorf("SignTx() sender = %s, want %s", sender.Hex(), account.Hex())
+	}
+
+	if _, err := remoteSigner.SignTx(context.Background(), nil, to, tx); !errors.Is(err, ErrUnknownAccount) {
+		t.Errorf("SignTx() for unknown account error = %v, want %v", err, ErrUnknownAccount)
+	}
+}
+
+func TestRemoteSigner_SignData(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	account := crypto.PubkeyToAddress(key.PublicKey)
+
+	server := newWeb3SignerStandIn(t, key)
+	remoteSigner := NewRemoteSigner(RemoteSignerOpts{
+		Endpoint:    strings.TrimSuffix(server.URL, "/") + "/",
+		ChainSigner: types.LatestSignerForChainID(big.NewInt(44787)),
+	})
+
+	data := []byte("eth-custodial")
+	signature, err := remoteSigner.SignData(context.Background(), nil, account, data)
+	if err != nil {
+		t.Fatalf("SignData() error = %v", err)
+	}
+
+	publicKey, err := crypto.SigToPub(crypto.Keccak256(data), signature)
+	if err != nil {
+		t.Fatalf("SigToPub() error = %v", err)
+	}
+	if crypto.PubkeyToAddress(*publicKey) != account {
+		t.Errorf("SignData() recovered a different account")
+	}
+}
@@ -0,0 +1,39 @@
+package signer
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/jackc/pgx/v5"
+)
+
+// RoutingSigner sends signing requests for accounts held by a dedicated signer, e.g. an HSM backed system signer, to
+// that signer and everything else to the fallback.
+type RoutingSigner struct {
+	routes   map[common.Address]Signer
+	fallback Signer
+}
+
+func NewRoutingSigner(fallback Signer, routes map[common.Address]Signer) *RoutingSigner {
+	return &RoutingSigner{
+		routes:   routes,
+		fallback: fallback,
+	}
+}
+
+func (s *RoutingSigner) SignTx(ctx context.Context, dbTx pgx.Tx, account common.Address, tx *types.Transaction) (*types.Transaction, error) {
+	return s.route(account).SignTx(ctx, dbTx, account, tx)
+}
+
+func (s *RoutingSigner) SignData(ctx context.Context, dbTx pgx.Tx, account common.Address, data []byte) ([]byte, error) {
+	return s.route(account).SignData(ctx, dbTx, account, data)
+}
+
+func (s *RoutingSigner) route(account common.Address) Signer {
+	if signer, ok := s.routes[account]; ok {
+		return signer
+	}
+
+	return s.fallback
+}
@@ -0,0 +1,84 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/jackc/pgx/v5"
+)
+
+// Signer signs on behalf of custodial accounts without handing key material to the caller.
+//
+// The optional pgx.Tx lets database backed implementations read keys within the caller's transaction instead of
+// acquiring a second connection from the pool. Other implementations ignore it.
+type Signer interface {
+	// SignTx signs an unsigned transaction for account.
+	SignTx(context.Context, pgx.Tx, common.Address, *types.Transaction) (*types.Transaction, error)
+	// SignData signs keccak256(data) for account and returns a 65 byte [R || S || V] signature where V is 0 or 1.
+	SignData(context.Context, pgx.Tx, common.Address, []byte) ([]byte, error)
+}
+
+var (
+	ErrUnknownAccount     = errors.New("signer: unknown account")
+	ErrUnsupportedTxType  = errors.New("signer: unsupported transaction type")
+	ErrInvalidSignature   = errors.New("signer: invalid signature")
+	ErrSignatureMismatch  = errors.New("signer: signature does not recover to the signing account")
+	ErrSigningHashChanged = errors.New("signer: signing preimage does not match the chain signer hash")
+)
+
+// signingPreimage returns the exact bytes whose keccak256 hash is the transaction signing hash. Signers that only
+// accept data to hash themselves (e.g. Web3Signer eth1/sign) need the preimage rather than the final hash.
+// eth-custodial only ever signs dynamic fee transactions.
+func signingPreimage(chainID *big.Int, tx *types.Transaction) ([]byte, error) {
+	if tx.Type() != types.DynamicFeeTxType {
+		return nil, ErrUnsupportedTxType
+	}
+
+	payload, err := rlp.EncodeToBytes([]any{
+		chainID,
+		tx.Nonce(),
+		tx.GasTipCap(),
+		tx.GasFeeCap(),
+		tx.Gas(),
+		tx.To(),
+		tx.Value(),
+		tx.Data(),
+		tx.AccessList(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{types.DynamicFeeTxType}, payload...), nil
+}
+
+// verifySignature ensures a signature produced by an external backend actually belongs to account.
+func verifySignature(account common.Address, hash []byte, signature []byte) error {
+	if len(signature) != crypto.SignatureLength {
+		return ErrInvalidSignature
+	}
+
+	publicKey, err := crypto.SigToPub(hash, signature)
+	if err != nil {
+		return err
+	}
+
+	if crypto.PubkeyToAddress(*publicKey) != account {
+		return ErrSignatureMismatch
+	}
+
+	return nil
+}
+
+// normalizeRecoveryID converts a 27/28 recovery id into the 0/1 form expected by go-ethereum.
+func normalizeRecoveryID(signature []byte) []byte {
+	if len(signature) == crypto.SignatureLength && signature[crypto.RecoveryIDOffset] >= 27 {
+		signature[crypto.RecoveryIDOffset] -= 27
+	}
+	return signature
+}
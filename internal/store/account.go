@@ -0,0 +1,126 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+)
+
+type (
+	AccountStatusChange struct {
+		ID         uint64    `db:"id" json:"id"`
+		PublicKey  string    `db:"public_key" json:"publicKey"`
+		FromStatus string    `db:"from_status" json:"fromStatus"`
+		ToStatus   string    `db:"to_status" json:"toStatus"`
+		Reason     string    `db:"reason" json:"reason"`
+		ChangedBy  string    `db:"changed_by" json:"changedBy"`
+		CreatedAt  time.Time `db:"created_at" json:"createdAt"`
+	}
+
+	QueuedJob struct {
+		ID         int64  `db:"id"`
+		TrackingID string `db:"tracking_id"`
+	}
+)
+
+const (
+	ACCOUNT_PENDING string = "PENDING"
+	ACCOUNT_ACTIVE  string = "ACTIVE"
+	ACCOUNT_FROZEN  string = "FROZEN"
+	ACCOUNT_CLOSED  string = "CLOSED"
+)
+
+var ErrInvalidStatusTransition = errors.New("store: invalid account status transition")
+
+// accountStatusTransitions lists the allowed manual status changes. PENDING accounts only become ACTIVE through on
+// chain registration and CLOSED is final.
+var accountStatusTransitions = map[string][]string{
+	ACCOUNT_PENDING: {ACCOUNT_FROZEN, ACCOUNT_CLOSED},
+	ACCOUNT_ACTIVE:  {ACCOUNT_FROZEN, ACCOUNT_CLOSED},
+	ACCOUNT_FROZEN:  {ACCOUNT_ACTIVE, ACCOUNT_CLOSED},
+}
+
+func ValidAccountStatusTransition(from string, to string) bool {
+	for _, v := range accountStatusTransitions[from] {
+		if v == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (pg *Pg) GetAccountStatus(ctx context.Context, tx pgx.Tx, publicKey string) (string, error) {
+	var status string
+
+	if err := tx.QueryRow(
+		ctx,
+		pg.queries.GetAccountStatus,
+		publicKey,
+	).Scan(&status); err != nil {
+		return "", err
+	}
+
+	return status, nil
+}
+
+// UpdateAccountStatus moves an account from change.FromStatus to change.ToStatus. ErrInvalidStatusTransition is
+// returned if the transition is not allowed or the account is no longer in change.FromStatus.
+func (pg *Pg) UpdateAccountStatus(ctx context.Context, tx pgx.Tx, change AccountStatusChange) error {
+	if !ValidAccountStatusTransition(change.FromStatus, change.ToStatus) {
+		return ErrInvalidStatusTransition
+	}
+
+	var id uint64
+	if err := tx.QueryRow(
+		ctx,
+		pg.queries.UpdateAccountStatus,
+		change.PublicKey,
+		change.FromStatus,
+		change.ToStatus,
+		change.Reason,
+		change.ChangedBy,
+	).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrInvalidStatusTransition
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (pg *Pg) GetAccountStatusChanges(ctx context.Context, tx pgx.Tx, publicKey string) ([]*AccountStatusChange, error) {
+	var changes []*AccountStatusChange
+
+	if err := pgxscan.Select(ctx, tx, &changes, pg.queries.GetAccountStatusChanges, publicKey); err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+func (pg *Pg) GetQueuedJobsByAccount(ctx context.Context, tx pgx.Tx, publicKey string) ([]*QueuedJob, error) {
+	var jobs []*QueuedJob
+
+	if err := pgxscan.Select(ctx, tx, &jobs, pg.queries.GetQueuedJobsByAccount, publicKey); err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+// CancelPendingDispatchByAccount marks all signed but not yet dispatched transactions of an account as
+// ACCOUNT_INACTIVE and returns their tracking ids.
+func (pg *Pg) CancelPendingDispatchByAccount(ctx context.Context, tx pgx.Tx, publicKey string) ([]string, error) {
+	var trackingIDs []string
+
+	if err := pgxscan.Select(ctx, tx, &trackingIDs, pg.queries.CancelPendingDispatch, publicKey); err != nil {
+		return nil, err
+	}
+
+	return trackingIDs, nil
+}
@@ -4,15 +4,65 @@ import (
 	"context"
 	"time"
 
+	"github.com/georgysavva/scany/v2/pgxscan"
 	"github.com/jackc/pgx/v5"
 )
 
 type DispatchTx struct {
-	ID        uint64    `db:"id" json:"id"`
-	OTXID     uint64    `db:"otx_id" json:"otxId"`
-	Status    string    `db:"status" json:"status"`
-	CreatedAt time.Time `db:"created_at" json:"createdAt"`
-	UpdatedAt time.Time `db:"updated_at" json:"updatedAt"`
+	ID          uint64    `db:"id" json:"id"`
+	OTXID       uint64    `db:"otx_id" json:"otxId"`
+	Status      string    `db:"status" json:"status"`
+	BlockNumber *uint64   `db:"block_number" json:"blockNumber"`
+	BlockHash   *string   `db:"block_hash" json:"blockHash"`
+	CreatedAt   time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+// UnconfirmedOTX is a mined OTX awaiting the confirmation depth, or a REVERTED OTX whose receipt is yet to be stored.
+// BlockHash is nil if the receipt's block was not recorded when it was marked mined.
+type UnconfirmedOTX struct {
+	ID          uint64  `db:"id"`
+	TrackingID  string  `db:"tracking_id"`
+	RawTx       string  `db:"raw_tx"`
+	TxHash      string  `db:"tx_hash"`
+	Status      string  `db:"status"`
+	BlockNumber *uint64 `db:"block_number"`
+	BlockHash   *string `db:"block_hash"`
+}
+
+// DispatchState is the dispatch status of an OTX and whether another OTX replaced it at its nonce.
+type DispatchState struct {
+	Status   string `db:"status"`
+	Replaced bool   `db:"replaced"`
+}
+
+// DispatchDependency is the dispatch status of an OTX next to that of its predecessor. PredecessorStatus is taken from
+// the effective OTX at the predecessor's nonce so that a replaced predecessor doesn't hold back its successor.
+type DispatchDependency struct {
+	Status            string  `db:"status"`
+	PredecessorStatus *string `db:"predecessor_status"`
+}
+
+// DispatchAttempt is a single broadcast of an OTX. Attempts are never updated, the latest outcome is projected onto
+// the dispatch row.
+type DispatchAttempt struct {
+	ID          uint64    `db:"id" json:"id"`
+	OTXID       uint64    `db:"otx_id" json:"otxId"`
+	Source      string    `db:"source" json:"source"`
+	TxHash      string    `db:"tx_hash" json:"txHash"`
+	RawTx       string    `db:"raw_tx" json:"rawTx"`
+	GasFeeCap   string    `db:"gas_fee_cap" json:"gasFeeCap"`
+	GasTipCap   string    `db:"gas_tip_cap" json:"gasTipCap"`
+	RPCEndpoint string    `db:"rpc_endpoint" json:"rpcEndpoint"`
+	Status      string    `db:"status" json:"status"`
+	RPCError    string    `db:"rpc_error" json:"rpcError,omitempty"`
+	CreatedAt   time.Time `db:"created_at" json:"createdAt"`
+}
+
+// ResolvedOTX is an OTX that can no longer be mined because another OTX with the same nonce was.
+type ResolvedOTX struct {
+	TrackingID string `db:"tracking_id"`
+	Status     string `db:"status"`
 }
 
 const (
@@ -27,8 +77,31 @@ const (
 	NETWORK_ERROR           string = "NETWORK_ERROR"
 	EXTERNAL_DISPATCH       string = "EXTERNAL_DISPATCH"
 	UNKNOWN_RPC_ERROR       string = "UNKNOWN_ERROR"
+	ACCOUNT_INACTIVE        string = "ACCOUNT_INACTIVE"
+	CANCELLED               string = "CANCELLED"
+	REPLACED                string = "REPLACED"
+	CONFIRMED               string = "CONFIRMED"
+	ABORTED                 string = "ABORTED"
+	ALREADY_KNOWN           string = "ALREADY_KNOWN"
+	INTRINSIC_GAS_TOO_LOW   string = "INTRINSIC_GAS_TOO_LOW"
+	GAS_LIMIT_EXCEEDED      string = "GAS_LIMIT_EXCEEDED"
+	TXPOOL_FULL             string = "TXPOOL_FULL"
+	BASE_FEE_TOO_LOW        string = "BASE_FEE_TOO_LOW"
+	FEE_CURRENCY_ERROR      string = "FEE_CURRENCY_ERROR"
+	SIMULATION_FAILED       string = "SIMULATION_FAILED"
 )
 
+// FinalDispatchStatus reports whether an OTX in status is settled and will not be dispatched again.
+// UpdateDispatchTxStatus leaves OTXs in a final status unchanged, only a receipt moves them.
+func FinalDispatchStatus(status string) bool {
+	switch status {
+	case SUCCESS, CONFIRMED, REVERTED, CANCELLED, REPLACED, EXTERNAL_DISPATCH, ACCOUNT_INACTIVE, ABORTED:
+		return true
+	}
+
+	return false
+}
+
 func (pg *Pg) InsertDispatchTx(ctx context.Context, tx pgx.Tx, dispatchTx DispatchTx) error {
 	_, err := tx.Exec(
 		ctx,
@@ -56,3 +129,110 @@ func (pg *Pg) UpdateDispatchTxStatus(ctx context.Context, tx pgx.Tx, dispatchTx
 
 	return nil
 }
+
+func (pg *Pg) UpdateDispatchTxReceipt(ctx context.Context, tx pgx.Tx, dispatchTx DispatchTx) error {
+	_, err := tx.Exec(
+		ctx,
+		pg.queries.UpdateDispatchTxReceipt,
+		dispatchTx.Status,
+		dispatchTx.BlockNumber,
+		dispatchTx.BlockHash,
+		dispatchTx.OTXID,
+	)
+	return err
+}
+
+func (pg *Pg) GetUnconfirmedOTX(ctx context.Context, tx pgx.Tx, limit int) ([]*UnconfirmedOTX, error) {
+	var unconfirmed []*UnconfirmedOTX
+
+	if err := pgxscan.Select(ctx, tx, &unconfirmed, pg.queries.GetUnconfirmedOTX, limit); err != nil {
+		return nil, err
+	}
+
+	return unconfirmed, nil
+}
+
+func (pg *Pg) GetDispatchState(ctx context.Context, tx pgx.Tx, otxID uint64) (*DispatchState, error) {
+	var state DispatchState
+
+	if err := pgxscan.Get(ctx, tx, &state, pg.queries.GetDispatchState, otxID); err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+func (pg *Pg) GetDispatchDependency(ctx context.Context, tx pgx.Tx, otxID uint64, predecessorOTXID uint64) (*DispatchDependency, error) {
+	var dependency DispatchDependency
+
+	if err := pgxscan.Get(ctx, tx, &dependency, pg.queries.GetDispatchDependency, otxID, predecessorOTXID); err != nil {
+		return nil, err
+	}
+
+	return &dependency, nil
+}
+
+// GetPredecessorOTXID returns the id of the OTX preceding otxID in its multi transaction flow, or 0 if there is none.
+func (pg *Pg) GetPredecessorOTXID(ctx context.Context, tx pgx.Tx, otxID uint64) (uint64, error) {
+	var predecessorOTXID uint64
+
+	if err := tx.QueryRow(
+		ctx,
+		pg.queries.GetPredecessorOTXID,
+		otxID,
+	).Scan(&predecessorOTXID); err != nil {
+		return 0, err
+	}
+
+	return predecessorOTXID, nil
+}
+
+// AbortSuccessors marks the PENDING OTXs that follow otxID within its tracking id as ABORTED and returns their ids.
+func (pg *Pg) AbortSuccessors(ctx context.Context, tx pgx.Tx, otxID uint64) ([]uint64, error) {
+	var aborted []uint64
+
+	if err := pgxscan.Select(ctx, tx, &aborted, pg.queries.AbortSuccessors, otxID); err != nil {
+		return nil, err
+	}
+
+	return aborted, nil
+}
+
+func (pg *Pg) InsertDispatchAttempt(ctx context.Context, tx pgx.Tx, dispatchAttempt DispatchAttempt) error {
+	_, err := tx.Exec(
+		ctx,
+		pg.queries.InsertDispatchAttempt,
+		dispatchAttempt.OTXID,
+		dispatchAttempt.Source,
+		dispatchAttempt.TxHash,
+		dispatchAttempt.RawTx,
+		dispatchAttempt.GasFeeCap,
+		dispatchAttempt.GasTipCap,
+		dispatchAttempt.RPCEndpoint,
+		dispatchAttempt.Status,
+		dispatchAttempt.RPCError,
+	)
+	return err
+}
+
+func (pg *Pg) GetDispatchAttemptsByTrackingID(ctx context.Context, tx pgx.Tx, trackingID string) ([]*DispatchAttempt, error) {
+	var dispatchAttempts []*DispatchAttempt
+
+	if err := pgxscan.Select(ctx, tx, &dispatchAttempts, pg.queries.GetDispatchAttempts, trackingID); err != nil {
+		return nil, err
+	}
+
+	return dispatchAttempts, nil
+}
+
+// ResolveReplacements is called once an OTX is mined. All other OTXs of the same signer and nonce are marked
+// CANCELLED or REPLACED, those belonging to another tracking id are returned so that their status can be published.
+func (pg *Pg) ResolveReplacements(ctx context.Context, tx pgx.Tx, otxID uint64) ([]*ResolvedOTX, error) {
+	var resolved []*ResolvedOTX
+
+	if err := pgxscan.Select(ctx, tx, &resolved, pg.queries.ResolveReplacements, otxID); err != nil {
+		return nil, err
+	}
+
+	return resolved, nil
+}
@@ -2,19 +2,91 @@ package store
 
 import (
 	"context"
+	"encoding/hex"
 	"errors"
 
 	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/grassrootseconomics/eth-custodial/internal/envelope"
 	"github.com/grassrootseconomics/eth-custodial/internal/keypair"
 	"github.com/jackc/pgx/v5"
 )
 
+// sealedKey is the at rest representation of a keystore row.
+type sealedKey struct {
+	ID         uint64 `db:"id"`
+	Public     string `db:"public_key"`
+	Private    string `db:"private_key"`
+	DataKey    string `db:"data_key"`
+	KeyVersion int    `db:"key_version"`
+	// DerivationIndex is set for accounts derived from the HD seed.
+	DerivationIndex *int64 `db:"derivation_index"`
+}
+
+type sealedHDSeed struct {
+	Seed       string `db:"seed"`
+	DataKey    string `db:"data_key"`
+	KeyVersion int    `db:"key_version"`
+}
+
+const hdSeedAdditionalData = "hd_seed"
+
+var (
+	ErrKeyringNotLoaded   = errors.New("store: private key is encrypted but no keyring is loaded")
+	ErrDerivationMismatch = errors.New("store: derived account does not match the keystore, the hd seed may have changed")
+	ErrExternalKey        = errors.New("store: private key is held by an external signer")
+)
+
+// CreateKeyPair creates a new inactive account, either as an independent random key or derived from the HD seed
+// when HD derivation is enabled. Only the public key is returned.
+func (pg *Pg) CreateKeyPair(ctx context.Context, tx pgx.Tx) (string, error) {
+	if !pg.hdDerivation {
+		generatedKeyPair, err := keypair.GenerateKeyPair()
+		if err != nil {
+			return "", err
+		}
+
+		if err := pg.InsertKeyPair(ctx, tx, generatedKeyPair); err != nil {
+			return "", err
+		}
+
+		return generatedKeyPair.Public, nil
+	}
+
+	var index int64
+	if err := tx.QueryRow(ctx, pg.queries.NextDerivationIndex).Scan(&index); err != nil {
+		return "", err
+	}
+
+	derivedKeyPair, err := pg.deriveKeyPair(ctx, tx, index)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := tx.Exec(
+		ctx,
+		pg.queries.InsertDerivedKeyPair,
+		derivedKeyPair.Public,
+		index,
+	); err != nil {
+		return "", err
+	}
+
+	return derivedKeyPair.Public, nil
+}
+
 func (pg *Pg) InsertKeyPair(ctx context.Context, tx pgx.Tx, keypair keypair.Key) error {
-	_, err := tx.Exec(
+	sealed, err := pg.sealPrivateKey(keypair)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
 		ctx,
 		pg.queries.InsertKeyPair,
 		keypair.Public,
-		keypair.Private,
+		sealed.Ciphertext,
+		sealed.DataKey,
+		sealed.Version,
 	)
 	if err != nil {
 		return err
@@ -37,18 +109,39 @@ func (pg *Pg) ActivateKeyPair(ctx context.Context, tx pgx.Tx, publicKey string)
 }
 
 func (pg *Pg) LoadPrivateKey(ctx context.Context, tx pgx.Tx, publicKey string) (keypair.Key, error) {
-	var keypair keypair.Key
+	var sealedKey sealedKey
 
 	row, err := tx.Query(ctx, pg.queries.LoadKey, publicKey)
 	if err != nil {
-		return keypair, err
+		return keypair.Key{}, err
+	}
+
+	if err := pgxscan.ScanOne(&sealedKey, row); err != nil {
+		return keypair.Key{}, err
+	}
+
+	if err := pg.logKeyAccess(ctx, sealedKey.Public); err != nil {
+		return keypair.Key{}, err
 	}
 
-	if err := pgxscan.ScanOne(&keypair, row); err != nil {
-		return keypair, err
+	if sealedKey.DerivationIndex != nil {
+		derivedKeyPair, err := pg.deriveKeyPair(ctx, tx, *sealedKey.DerivationIndex)
+		if err != nil {
+			return keypair.Key{}, err
+		}
+
+		if derivedKeyPair.Public != sealedKey.Public {
+			return keypair.Key{}, ErrDerivationMismatch
+		}
+
+		return derivedKeyPair, nil
 	}
 
-	return keypair, nil
+	if sealedKey.Private == "" {
+		return keypair.Key{}, ErrExternalKey
+	}
+
+	return pg.openPrivateKey(sealedKey)
 }
 
 func (pg *Pg) CheckKeypair(ctx context.Context, tx pgx.Tx, publicKey string) (bool, error) {
@@ -68,23 +161,99 @@ func (pg *Pg) CheckKeypair(ctx context.Context, tx pgx.Tx, publicKey string) (bo
 	return active, nil
 }
 
+func (pg *Pg) KeyPairExists(ctx context.Context, tx pgx.Tx, publicKey string) (bool, error) {
+	var exists bool
+
+	if err := tx.QueryRow(
+		ctx,
+		pg.queries.KeyPairExists,
+		publicKey,
+	).Scan(&exists); err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
 func (pg *Pg) LoadMasterSignerKey(ctx context.Context, tx pgx.Tx) (keypair.Key, error) {
-	var masterKeypair keypair.Key
+	var sealedKey sealedKey
 
 	row, err := tx.Query(ctx, pg.queries.LoadMasterKey)
 	if err != nil {
-		return masterKeypair, err
+		return keypair.Key{}, err
 	}
 
-	if err := pgxscan.ScanOne(&masterKeypair, row); err != nil {
-		return masterKeypair, err
+	if err := pgxscan.ScanOne(&sealedKey, row); err != nil {
+		return keypair.Key{}, err
+	}
+
+	if err := pg.logKeyAccess(ctx, sealedKey.Public); err != nil {
+		return keypair.Key{}, err
+	}
+
+	return pg.openPrivateKey(sealedKey)
+}
+
+func (pg *Pg) LoadMasterSignerAddress(ctx context.Context, tx pgx.Tx) (string, error) {
+	var publicKey string
+
+	if err := tx.QueryRow(ctx, pg.queries.LoadMasterAddress).Scan(&publicKey); err != nil {
+		return "", err
+	}
+
+	return publicKey, nil
+}
+
+// RekeyKeyPairs re-encrypts up to limit keys that are either still in plaintext or sealed with an older KEK
+// version. Rows are locked with SKIP LOCKED so that it can run alongside a live service and in parallel.
+// The HD seed, if present, is re-encrypted as well. Derived accounts hold no private key and are skipped.
+func (pg *Pg) RekeyKeyPairs(ctx context.Context, tx pgx.Tx, limit int) (int, error) {
+	if pg.keyring == nil {
+		return 0, ErrKeyringNotLoaded
+	}
+
+	if err := pg.rekeyHDSeed(ctx, tx); err != nil {
+		return 0, err
+	}
+
+	var sealedKeys []*sealedKey
+
+	if err := pgxscan.Select(ctx, tx, &sealedKeys, pg.queries.GetKeysForRekey, pg.keyring.CurrentVersion(), limit); err != nil {
+		return 0, err
+	}
+
+	for _, v := range sealedKeys {
+		if err := pg.logKeyAccess(ctx, v.Public); err != nil {
+			return 0, err
+		}
+
+		key, err := pg.openPrivateKey(*v)
+		if err != nil {
+			return 0, err
+		}
+
+		sealed, err := pg.sealPrivateKey(key)
+		if err != nil {
+			return 0, err
+		}
+
+		if _, err := tx.Exec(
+			ctx,
+			pg.queries.UpdateSealedKey,
+			v.ID,
+			sealed.Ciphertext,
+			sealed.DataKey,
+			sealed.Version,
+		); err != nil {
+			return 0, err
+		}
 	}
 
-	return masterKeypair, nil
+	return len(sealedKeys), nil
 }
 
 func (pg *Pg) bootstrapMasterSigner(ctx context.Context, tx pgx.Tx) error {
-	_, err := pg.LoadMasterSignerKey(ctx, tx)
+	_, err := pg.LoadMasterSignerAddress(ctx, tx)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			masterKeyPair, err := keypair.GenerateKeyPair()
@@ -92,11 +261,18 @@ func (pg *Pg) bootstrapMasterSigner(ctx context.Context, tx pgx.Tx) error {
 				return err
 			}
 
+			sealed, err := pg.sealPrivateKey(masterKeyPair)
+			if err != nil {
+				return err
+			}
+
 			_, err = tx.Exec(
 				ctx,
 				pg.queries.BootstrapMasterKey,
 				masterKeyPair.Public,
-				masterKeyPair.Private,
+				sealed.Ciphertext,
+				sealed.DataKey,
+				sealed.Version,
 			)
 			if err != nil {
 				return err
@@ -108,3 +284,148 @@ func (pg *Pg) bootstrapMasterSigner(ctx context.Context, tx pgx.Tx) error {
 
 	return nil
 }
+
+// sealPrivateKey falls back to plaintext storage when no keyring is configured to remain compatible with
+// deployments that have not yet provisioned a KEK.
+func (pg *Pg) sealPrivateKey(key keypair.Key) (envelope.Sealed, error) {
+	if pg.keyring == nil {
+		return envelope.Sealed{
+			Ciphertext: key.Private,
+			Version:    envelope.PlaintextVersion,
+		}, nil
+	}
+
+	return pg.keyring.Seal([]byte(key.Private), []byte(key.Public))
+}
+
+func (pg *Pg) openPrivateKey(sealedKey sealedKey) (keypair.Key, error) {
+	if sealedKey.KeyVersion == envelope.PlaintextVersion {
+		return keypair.Key{
+			Public:  sealedKey.Public,
+			Private: sealedKey.Private,
+		}, nil
+	}
+
+	if pg.keyring == nil {
+		return keypair.Key{}, ErrKeyringNotLoaded
+	}
+
+	privateKey, err := pg.keyring.Open(envelope.Sealed{
+		Ciphertext: sealedKey.Private,
+		DataKey:    sealedKey.DataKey,
+		Version:    sealedKey.KeyVersion,
+	}, []byte(sealedKey.Public))
+	if err != nil {
+		return keypair.Key{}, err
+	}
+
+	return keypair.Key{
+		Public:  sealedKey.Public,
+		Private: string(privateKey),
+	}, nil
+}
+
+func (pg *Pg) bootstrapHDSeed(ctx context.Context, tx pgx.Tx) error {
+	_, err := pg.loadHDSeed(ctx, tx)
+	if err == nil || !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+
+	seed, err := keypair.GenerateSeed()
+	if err != nil {
+		return err
+	}
+	defer clear(seed)
+
+	sealed, err := pg.sealPrivateKey(keypair.Key{
+		Public:  hdSeedAdditionalData,
+		Private: hex.EncodeToString(seed),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		ctx,
+		pg.queries.BootstrapHDSeed,
+		sealed.Ciphertext,
+		sealed.DataKey,
+		sealed.Version,
+	)
+	if err != nil {
+		return err
+	}
+	pg.logg.Info("generated new hd seed, make sure it is included in backups")
+
+	return nil
+}
+
+func (pg *Pg) rekeyHDSeed(ctx context.Context, tx pgx.Tx) error {
+	var sealedSeed sealedHDSeed
+
+	if err := pgxscan.Get(ctx, tx, &sealedSeed, pg.queries.LoadHDSeed); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	if sealedSeed.KeyVersion == pg.keyring.CurrentVersion() {
+		return nil
+	}
+
+	seed, err := pg.openPrivateKey(sealedKey{
+		Public:     hdSeedAdditionalData,
+		Private:    sealedSeed.Seed,
+		DataKey:    sealedSeed.DataKey,
+		KeyVersion: sealedSeed.KeyVersion,
+	})
+	if err != nil {
+		return err
+	}
+
+	sealed, err := pg.sealPrivateKey(seed)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		ctx,
+		pg.queries.UpdateHDSeed,
+		sealed.Ciphertext,
+		sealed.DataKey,
+		sealed.Version,
+	)
+	return err
+}
+
+func (pg *Pg) loadHDSeed(ctx context.Context, tx pgx.Tx) ([]byte, error) {
+	var sealedSeed sealedHDSeed
+
+	if err := pgxscan.Get(ctx, tx, &sealedSeed, pg.queries.LoadHDSeed); err != nil {
+		return nil, err
+	}
+
+	// The seed shares the keystore envelope format with the public key slot holding a fixed additional data value.
+	seed, err := pg.openPrivateKey(sealedKey{
+		Public:     hdSeedAdditionalData,
+		Private:    sealedSeed.Seed,
+		DataKey:    sealedSeed.DataKey,
+		KeyVersion: sealedSeed.KeyVersion,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return hex.DecodeString(seed.Private)
+}
+
+func (pg *Pg) deriveKeyPair(ctx context.Context, tx pgx.Tx, index int64) (keypair.Key, error) {
+	seed, err := pg.loadHDSeed(ctx, tx)
+	if err != nil {
+		return keypair.Key{}, err
+	}
+	defer clear(seed)
+
+	return keypair.DeriveKeyPair(seed, uint32(index))
+}
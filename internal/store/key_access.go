@@ -0,0 +1,160 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+)
+
+type (
+	// KeyAccess describes who is loading a private key. It is carried in the context by the API and worker middleware.
+	KeyAccess struct {
+		Caller     string
+		TrackingID string
+		Subject    string
+	}
+
+	KeyAccessLog struct {
+		ID         uint64    `db:"id" json:"id"`
+		PublicKey  string    `db:"public_key" json:"publicKey"`
+		Caller     string    `db:"caller" json:"caller"`
+		TrackingID string    `db:"tracking_id" json:"trackingId"`
+		Subject    string    `db:"subject" json:"subject"`
+		CreatedAt  time.Time `db:"created_at" json:"createdAt"`
+		// Seq, PrevHash and Hash are nil until the row is sealed into the hash chain.
+		Seq      *uint64 `db:"seq" json:"seq"`
+		PrevHash *string `db:"prev_hash" json:"prevHash"`
+		Hash     *string `db:"hash" json:"hash"`
+	}
+
+	keyAccessCtxKey struct{}
+)
+
+const unknownKeyAccessCaller = "unknown"
+
+func WithKeyAccess(ctx context.Context, keyAccess KeyAccess) context.Context {
+	return context.WithValue(ctx, keyAccessCtxKey{}, keyAccess)
+}
+
+func KeyAccessFromContext(ctx context.Context) KeyAccess {
+	keyAccess, _ := ctx.Value(keyAccessCtxKey{}).(KeyAccess)
+	if keyAccess.Caller == "" {
+		keyAccess.Caller = unknownKeyAccessCaller
+	}
+
+	return keyAccess
+}
+
+// ChainHash returns the hash of the row at seq linked to prevHash. The genesis row links to an empty prevHash.
+func (l *KeyAccessLog) ChainHash(seq uint64, prevHash string) string {
+	h := sha256.Sum256(fmt.Appendf(nil,
+		"%d|%s|%d|%s|%s|%s|%s|%d",
+		seq,
+		prevHash,
+		l.ID,
+		l.PublicKey,
+		l.Caller,
+		l.TrackingID,
+		l.Subject,
+		l.CreatedAt.UnixMicro(),
+	))
+
+	return hex.EncodeToString(h[:])
+}
+
+// logKeyAccess records a key load outside the caller's transaction. The row is committed before the key material is
+// returned, so a load is logged even if the work that needed it rolls back. The write goes through the dedicated key
+// access pool: callers hold a main pool connection for their transaction, taking a second one from the same pool could
+// exhaust it once every connection is held by a caller. A failed write must fail the load.
+func (pg *Pg) logKeyAccess(ctx context.Context, publicKey string) error {
+	keyAccess := KeyAccessFromContext(ctx)
+
+	_, err := pg.keyAccessDB.Exec(
+		ctx,
+		pg.queries.InsertKeyAccessLog,
+		publicKey,
+		keyAccess.Caller,
+		keyAccess.TrackingID,
+		keyAccess.Subject,
+	)
+	return err
+}
+
+// SealKeyAccessLog appends up to limit committed rows to the hash chain in id order. Rows from transactions that commit
+// late are sealed on a later run, so chain order follows seq rather than id.
+func (pg *Pg) SealKeyAccessLog(ctx context.Context, tx pgx.Tx, limit int) (int, error) {
+	if _, err := tx.Exec(ctx, pg.queries.LockKeyAccessLog); err != nil {
+		return 0, err
+	}
+
+	var (
+		seq      uint64
+		prevHash string
+	)
+	if err := tx.QueryRow(ctx, pg.queries.GetLastSealedKeyAccess).Scan(&seq, &prevHash); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return 0, err
+	}
+
+	var unsealed []*KeyAccessLog
+	if err := pgxscan.Select(ctx, tx, &unsealed, pg.queries.GetUnsealedKeyAccess, limit); err != nil {
+		return 0, err
+	}
+
+	for _, v := range unsealed {
+		seq++
+		hash := v.ChainHash(seq, prevHash)
+
+		if _, err := tx.Exec(ctx, pg.queries.SealKeyAccessLog, v.ID, seq, prevHash, hash); err != nil {
+			return 0, err
+		}
+		prevHash = hash
+	}
+
+	return len(unsealed), nil
+}
+
+func (pg *Pg) GetSealedKeyAccessLog(ctx context.Context, tx pgx.Tx, afterSeq uint64, limit int) ([]*KeyAccessLog, error) {
+	var keyAccessLog []*KeyAccessLog
+
+	if err := pgxscan.Select(ctx, tx, &keyAccessLog, pg.queries.GetSealedKeyAccess, afterSeq, limit); err != nil {
+		return nil, err
+	}
+
+	return keyAccessLog, nil
+}
+
+func (pg *Pg) GetKeyAccessLogByAccount(ctx context.Context, tx pgx.Tx, publicKey string, limit int) ([]*KeyAccessLog, error) {
+	var keyAccessLog []*KeyAccessLog
+
+	if err := pgxscan.Select(ctx, tx, &keyAccessLog, pg.queries.KeyAccessByAccount, publicKey, limit); err != nil {
+		return nil, err
+	}
+
+	return keyAccessLog, nil
+}
+
+func (pg *Pg) GetKeyAccessLogByAccountNext(ctx context.Context, tx pgx.Tx, publicKey string, cursor int, limit int) ([]*KeyAccessLog, error) {
+	var keyAccessLog []*KeyAccessLog
+
+	if err := pgxscan.Select(ctx, tx, &keyAccessLog, pg.queries.KeyAccessByAccountNext, publicKey, cursor, limit); err != nil {
+		return nil, err
+	}
+
+	return keyAccessLog, nil
+}
+
+func (pg *Pg) GetKeyAccessLogByAccountPrevious(ctx context.Context, tx pgx.Tx, publicKey string, cursor int, limit int) ([]*KeyAccessLog, error) {
+	var keyAccessLog []*KeyAccessLog
+
+	if err := pgxscan.Select(ctx, tx, &keyAccessLog, pg.queries.KeyAccessByAccountPrev, publicKey, cursor, limit); err != nil {
+		return nil, err
+	}
+
+	return keyAccessLog, nil
+}
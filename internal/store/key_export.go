@@ -0,0 +1,28 @@
+package store
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type KeyExport struct {
+	PublicKey  string
+	ExportedBy string
+	Frozen     bool
+}
+
+func (pg *Pg) InsertKeyExport(ctx context.Context, tx pgx.Tx, keyExport KeyExport) error {
+	_, err := tx.Exec(
+		ctx,
+		pg.queries.InsertKeyExport,
+		keyExport.PublicKey,
+		keyExport.ExportedBy,
+		keyExport.Frozen,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -20,6 +20,21 @@ func (pg *Pg) PeekNonce(ctx context.Context, tx pgx.Tx, publicKey string) (uint6
 	return nonce, nil
 }
 
+// GetNextNonce returns the nonce the noncestore hands out next, i.e. the number of nonces acquired by publicKey.
+func (pg *Pg) GetNextNonce(ctx context.Context, tx pgx.Tx, publicKey string) (uint64, error) {
+	var nextNonce uint64
+
+	if err := tx.QueryRow(
+		ctx,
+		pg.queries.GetNextNonce,
+		publicKey,
+	).Scan(&nextNonce); err != nil {
+		return 0, err
+	}
+
+	return nextNonce, nil
+}
+
 func (pg *Pg) AcquireNonce(ctx context.Context, tx pgx.Tx, publicKey string) (uint64, error) {
 	var nonce uint64
 
@@ -0,0 +1,94 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+)
+
+type (
+	NonceCheckAccount struct {
+		PublicKey string `db:"public_key"`
+		NextNonce uint64 `db:"next_nonce"`
+	}
+
+	NonceGap struct {
+		ID            uint64    `db:"id" json:"id"`
+		PublicKey     string    `db:"public_key" json:"publicKey"`
+		Kind          string    `db:"kind" json:"kind"`
+		Nonce         uint64    `db:"nonce" json:"nonce"`
+		InternalNonce uint64    `db:"internal_nonce" json:"internalNonce"`
+		NetworkNonce  uint64    `db:"network_nonce" json:"networkNonce"`
+		Repaired      bool      `db:"repaired" json:"repaired"`
+		RepairOTXID   *uint64   `db:"repair_otx_id" json:"repairOtxId"`
+		Error         string    `db:"error" json:"error"`
+		CreatedAt     time.Time `db:"created_at" json:"createdAt"`
+	}
+)
+
+const (
+	// NONCE_MISSING_OTX is a nonce below the internal next nonce that no OTX uses. The network stalls at it.
+	NONCE_MISSING_OTX string = "MISSING_OTX"
+	// NONCE_BEHIND is an internal next nonce below the network nonce, the next OTX would be rejected as nonce too low.
+	NONCE_BEHIND string = "NONCE_BEHIND"
+)
+
+// RaiseAccountNonce sets the next nonce only if it is currently lower.
+func (pg *Pg) RaiseAccountNonce(ctx context.Context, tx pgx.Tx, publicKey string, nonce uint64) error {
+	_, err := tx.Exec(
+		ctx,
+		pg.queries.RaiseAccountNonce,
+		publicKey,
+		nonce,
+	)
+	return err
+}
+
+func (pg *Pg) GetNonceCheckAccounts(ctx context.Context, tx pgx.Tx, updatedSince time.Time, limit int) ([]*NonceCheckAccount, error) {
+	var accounts []*NonceCheckAccount
+
+	if err := pgxscan.Select(ctx, tx, &accounts, pg.queries.GetNonceCheckAccounts, updatedSince, limit); err != nil {
+		return nil, err
+	}
+
+	return accounts, nil
+}
+
+// GetNonceGaps returns the nonces from networkNonce up to the internal next nonce that have no OTX.
+func (pg *Pg) GetNonceGaps(ctx context.Context, tx pgx.Tx, publicKey string, networkNonce uint64, limit int) ([]uint64, error) {
+	var gaps []uint64
+
+	if err := pgxscan.Select(ctx, tx, &gaps, pg.queries.GetNonceGaps, publicKey, networkNonce, limit); err != nil {
+		return nil, err
+	}
+
+	return gaps, nil
+}
+
+func (pg *Pg) InsertNonceGap(ctx context.Context, tx pgx.Tx, nonceGap NonceGap) error {
+	_, err := tx.Exec(
+		ctx,
+		pg.queries.InsertNonceGap,
+		nonceGap.PublicKey,
+		nonceGap.Kind,
+		nonceGap.Nonce,
+		nonceGap.InternalNonce,
+		nonceGap.NetworkNonce,
+		nonceGap.Repaired,
+		nonceGap.RepairOTXID,
+		nonceGap.Error,
+	)
+	return err
+}
+
+func (pg *Pg) GetNonceGapsReport(ctx context.Context, tx pgx.Tx, limit int) ([]*NonceGap, error) {
+	var nonceGaps []*NonceGap
+
+	if err := pgxscan.Select(ctx, tx, &nonceGaps, pg.queries.GetNonceGapsReport, limit); err != nil {
+		return nil, err
+	}
+
+	return nonceGaps, nil
+}
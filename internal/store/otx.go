@@ -17,6 +17,9 @@ type OTX struct {
 	TxHash         string    `db:"tx_hash" json:"txHash"`
 	Nonce          uint64    `db:"nonce" json:"nonce"`
 	Replaced       bool      `db:"replaced" json:"replaced"`
+	ReplacesOTXID  *uint64   `db:"replaces_otx_id" json:"replacesOtxId,omitempty"`
+	GasLimit       uint64    `db:"gas_limit" json:"gasLimit,omitempty"`
+	GasLimitSource string    `db:"gas_limit_source" json:"gasLimitSource,omitempty"`
 	CreatedAt      time.Time `db:"created_at" json:"createdAt"`
 	UpdatedAt      time.Time `db:"updated_at" json:"updatedAt"`
 	DispatchStatus string    `db:"status" json:"status"`
@@ -45,8 +48,17 @@ const (
 	SET_QUOTER              string = "SET_QUOTER"
 	DEMURRAGE_TOKEN_DEPLOY  string = "DEMURRAGE_TOKEN_DEPLOY"
 	EXPIRING_TOKEN_DEPLOY   string = "EXPIRING_TOKEN_DEPLOY"
+	NONCE_GAP_FILL          string = "NONCE_GAP_FILL"
+	CANCEL                  string = "CANCEL"
 )
 
+const (
+	GAS_LIMIT_ESTIMATED string = "ESTIMATED"
+	GAS_LIMIT_CACHED    string = "CACHED"
+	GAS_LIMIT_FALLBACK  string = "FALLBACK"
+)
+
+// InsertOTX attributes the OTX to the JWT subject carried in ctx, see WithKeyAccess.
 func (pg *Pg) InsertOTX(ctx context.Context, tx pgx.Tx, otx OTX) (uint64, error) {
 	var id uint64
 
@@ -59,6 +71,10 @@ func (pg *Pg) InsertOTX(ctx context.Context, tx pgx.Tx, otx OTX) (uint64, error)
 		otx.RawTx,
 		otx.TxHash,
 		otx.Nonce,
+		otx.ReplacesOTXID,
+		KeyAccessFromContext(ctx).Subject,
+		otx.GasLimit,
+		otx.GasLimitSource,
 	).Scan(&id); err != nil {
 		return id, err
 	}
@@ -121,12 +137,28 @@ func (pg *Pg) GetOTXByAccountPrevious(ctx context.Context, tx pgx.Tx, publicKey
 	return otx, nil
 }
 
-func (pg *Pg) GetFailedOTX(ctx context.Context, tx pgx.Tx) ([]*OTX, error) {
+func (pg *Pg) GetNonFinalOTX(ctx context.Context, tx pgx.Tx, cursor uint64, limit int) ([]*OTX, error) {
 	var otx []*OTX
 
-	if err := pgxscan.Select(ctx, tx, &otx, pg.queries.GetFailedOTX); err != nil {
+	if err := pgxscan.Select(ctx, tx, &otx, pg.queries.GetNonFinalOTX, cursor, limit); err != nil {
 		return nil, err
 	}
 
 	return otx, nil
 }
+
+func (pg *Pg) MarkOTXReplaced(ctx context.Context, tx pgx.Tx, otxID uint64) error {
+	_, err := tx.Exec(ctx, pg.queries.MarkOTXReplaced, otxID)
+	return err
+}
+
+// GetDeployNonces returns the nonces of the successful contract deploys signed by publicKey in ascending order.
+func (pg *Pg) GetDeployNonces(ctx context.Context, tx pgx.Tx, publicKey string) ([]uint64, error) {
+	var nonces []uint64
+
+	if err := pgxscan.Select(ctx, tx, &nonces, pg.queries.GetDeployNonces, publicKey); err != nil {
+		return nil, err
+	}
+
+	return nonces, nil
+}
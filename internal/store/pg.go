@@ -7,6 +7,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/grassrootseconomics/eth-custodial/internal/envelope"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jackc/tern/v2/migrate"
 	"github.com/knadh/goyesql/v2"
@@ -16,14 +17,59 @@ type (
 	Queries struct {
 		InsertKeyPair           string `query:"insert-keypair"`
 		ActivateKeyPair         string `query:"activate-keypair"`
+		ActivateSystemKeyPair   string `query:"activate-system-keypair"`
 		LoadKey                 string `query:"load-key"`
+		InsertExternalKeyPair   string `query:"insert-external-keypair"`
+		InsertDerivedKeyPair    string `query:"insert-derived-keypair"`
+		NextDerivationIndex     string `query:"next-derivation-index"`
+		LoadHDSeed              string `query:"load-hd-seed"`
+		BootstrapHDSeed         string `query:"bootstrap-hd-seed"`
+		UpdateHDSeed            string `query:"update-hd-seed"`
 		CheckKeypair            string `query:"check-keypair"`
+		KeyPairExists           string `query:"keypair-exists"`
+		GetAccountStatus        string `query:"get-account-status"`
+		UpdateAccountStatus     string `query:"update-account-status"`
+		GetAccountStatusChanges string `query:"get-account-status-changes"`
+		GetQueuedJobsByAccount  string `query:"get-queued-jobs-by-account"`
+		CancelPendingDispatch   string `query:"cancel-pending-dispatch-by-account"`
+		InsertKeyExport         string `query:"insert-key-export"`
 		LoadMasterKey           string `query:"load-master-key"`
+		LoadMasterAddress       string `query:"load-master-address"`
 		BootstrapMasterKey      string `query:"bootstrap-master-key"`
+		GetSystemSigners        string `query:"get-system-signers"`
+		NextSystemSignerRR      string `query:"next-system-signer-round-robin"`
+		NextSystemSignerLP      string `query:"next-system-signer-least-pending"`
+		AddSystemSigner         string `query:"add-system-signer"`
+		EnableSystemSigner      string `query:"enable-system-signer"`
+		RetireSystemSigner      string `query:"retire-system-signer"`
+		BeginMasterRotation     string `query:"begin-master-signer-rotation"`
+		SwitchMasterRotation    string `query:"switch-master-signer-rotation"`
+		CompleteMasterRotation  string `query:"complete-master-signer-rotation"`
+		GetPendingRotation      string `query:"get-pending-master-signer-rotation"`
+		InsertKeyAccessLog      string `query:"insert-key-access-log"`
+		LockKeyAccessLog        string `query:"lock-key-access-log"`
+		GetLastSealedKeyAccess  string `query:"get-last-sealed-key-access-log"`
+		GetUnsealedKeyAccess    string `query:"get-unsealed-key-access-log"`
+		SealKeyAccessLog        string `query:"seal-key-access-log"`
+		GetSealedKeyAccess      string `query:"get-sealed-key-access-log"`
+		KeyAccessByAccount      string `query:"get-key-access-log-by-account"`
+		KeyAccessByAccountNext  string `query:"get-key-access-log-by-account-next"`
+		KeyAccessByAccountPrev  string `query:"get-key-access-log-by-account-previous"`
+		GetKeysForRekey         string `query:"get-keys-for-rekey"`
+		UpdateSealedKey         string `query:"update-sealed-key"`
 		PeekNonce               string `query:"peek-nonce"`
 		AcquireNonce            string `query:"acquire-nonce"`
+		GetNextNonce            string `query:"get-next-nonce"`
 		SetAcccountNonce        string `query:"set-account-nonce"`
+		GetNonceCheckAccounts   string `query:"get-nonce-check-accounts"`
+		GetNonceGaps            string `query:"get-nonce-gaps"`
+		RaiseAccountNonce       string `query:"raise-account-nonce"`
+		InsertNonceGap          string `query:"insert-nonce-gap"`
+		GetNonceGapsReport      string `query:"get-nonce-gaps-report"`
 		InsertOTX               string `query:"insert-otx"`
+		ResolveReplacements     string `query:"resolve-replacements"`
+		MarkOTXReplaced         string `query:"mark-otx-replaced"`
+		GetDeployNonces         string `query:"get-deploy-nonces"`
 		GetOTXByTxHash          string `query:"get-otx-by-tx-hash"`
 		GetOTXByTrackingID      string `query:"get-otx-by-tracking-id"`
 		GetOTXByAccount         string `query:"get-otx-by-account"`
@@ -31,7 +77,23 @@ type (
 		GetOTXByAccountPrevious string `query:"get-otx-by-account-previous"`
 		InsertDispatchTx        string `query:"insert-dispatch-tx"`
 		UpdateDispatchTxStatus  string `query:"update-dispatch-tx-status"`
-		GetFailedOTX            string `query:"get-failed-otx"`
+		UpdateDispatchTxReceipt string `query:"update-dispatch-tx-receipt"`
+		GetDispatchState        string `query:"get-dispatch-state"`
+		GetDispatchDependency   string `query:"get-dispatch-dependency"`
+		GetPredecessorOTXID     string `query:"get-predecessor-otx-id"`
+		AbortSuccessors         string `query:"abort-successors"`
+		GetUnconfirmedOTX       string `query:"get-unconfirmed-otx"`
+		GetNonFinalOTX          string `query:"get-non-final-otx"`
+		InsertDispatchAttempt   string `query:"insert-dispatch-attempt"`
+		UpsertReceipt           string `query:"upsert-receipt"`
+		DeleteReceipt           string `query:"delete-receipt"`
+		GetReceipts             string `query:"get-receipts-by-tracking-id"`
+		GetFeeReport            string `query:"get-fee-report"`
+		GetDispatchAttempts     string `query:"get-dispatch-attempts-by-tracking-id"`
+		InsertRetrierAction     string `query:"insert-retrier-action"`
+		GetRetrierActions       string `query:"get-retrier-actions-by-tracking-id"`
+		InsertSimulationFailure string `query:"insert-simulation-failure"`
+		GetSimulationFailures   string `query:"get-simulation-failures-by-tracking-id"`
 	}
 
 	PgOpts struct {
@@ -39,15 +101,26 @@ type (
 		DSN                  string
 		MigrationsFolderPath string
 		QueriesFolderPath    string
+		Keyring              *envelope.Keyring
+		// HDDerivation derives new accounts from a single HD seed instead of generating independent keys.
+		HDDerivation bool
 	}
 
 	Pg struct {
-		logg    *slog.Logger
-		db      *pgxpool.Pool
-		queries *Queries
+		logg *slog.Logger
+		db   *pgxpool.Pool
+		// keyAccessDB only writes the key access log, see logKeyAccess.
+		keyAccessDB  *pgxpool.Pool
+		queries      *Queries
+		keyring      *envelope.Keyring
+		hdDerivation bool
 	}
 )
 
+// keyAccessPoolSize bounds the connections of the key access log pool. Each write holds a connection for a single
+// insert and never waits on the main pool, so key loads queue briefly under contention but cannot deadlock.
+const keyAccessPoolSize = 4
+
 func NewPgStore(o PgOpts) (Store, error) {
 	parsedConfig, err := pgxpool.ParseConfig(o.DSN)
 	if err != nil {
@@ -59,6 +132,13 @@ func NewPgStore(o PgOpts) (Store, error) {
 		return nil, err
 	}
 
+	keyAccessConfig := parsedConfig.Copy()
+	keyAccessConfig.MaxConns = keyAccessPoolSize
+	keyAccessPool, err := pgxpool.NewWithConfig(context.Background(), keyAccessConfig)
+	if err != nil {
+		return nil, err
+	}
+
 	queries, err := loadQueries(o.QueriesFolderPath)
 	if err != nil {
 		return nil, err
@@ -69,10 +149,17 @@ func NewPgStore(o PgOpts) (Store, error) {
 	}
 	o.Logg.Info("migrations ran successfully")
 
+	if o.Keyring == nil {
+		o.Logg.Warn("no keystore keyring loaded, new private keys will be stored in plaintext")
+	}
+
 	return &Pg{
-		logg:    o.Logg,
-		db:      dbPool,
-		queries: queries,
+		logg:         o.Logg,
+		db:           dbPool,
+		keyAccessDB:  keyAccessPool,
+		queries:      queries,
+		keyring:      o.Keyring,
+		hdDerivation: o.HDDerivation,
 	}, nil
 }
 
@@ -92,7 +179,15 @@ func (s *Pg) Bootstrap() error {
 		}
 	}()
 
-	return s.bootstrapMasterSigner(ctx, tx)
+	if err = s.bootstrapMasterSigner(ctx, tx); err != nil {
+		return err
+	}
+
+	if s.hdDerivation {
+		err = s.bootstrapHDSeed(ctx, tx)
+	}
+
+	return err
 }
 
 func (s *Pg) Pool() *pgxpool.Pool {
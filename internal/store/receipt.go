@@ -0,0 +1,121 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+)
+
+type (
+	// Receipt is the stored receipt of a mined OTX. Fee is gas used times the effective gas price plus the L1 data fee
+	// of OP stack chains.
+	Receipt struct {
+		OTXID             uint64          `db:"otx_id" json:"otxId"`
+		TxHash            string          `db:"tx_hash" json:"txHash"`
+		BlockNumber       uint64          `db:"block_number" json:"blockNumber"`
+		BlockHash         string          `db:"block_hash" json:"blockHash"`
+		Success           bool            `db:"success" json:"success"`
+		GasUsed           uint64          `db:"gas_used" json:"gasUsed"`
+		EffectiveGasPrice string          `db:"effective_gas_price" json:"effectiveGasPrice"`
+		L1Fee             string          `db:"l1_fee" json:"l1Fee"`
+		Fee               string          `db:"fee" json:"fee"`
+		ContractAddress   *string         `db:"contract_address" json:"contractAddress,omitempty"`
+		Logs              json.RawMessage `db:"logs" json:"logs"`
+		CreatedAt         time.Time       `db:"created_at" json:"createdAt"`
+	}
+
+	// FeeReport is the native gas spent by a signer account for a JWT subject and otx type.
+	FeeReport struct {
+		Account string `db:"account" json:"account"`
+		Subject string `db:"subject" json:"subject"`
+		OTXType string `db:"otx_type" json:"otxType"`
+		TxCount uint64 `db:"tx_count" json:"txCount"`
+		GasUsed string `db:"gas_used" json:"gasUsed"`
+		L1Fees  string `db:"l1_fees" json:"l1Fees"`
+		Fees    string `db:"fees" json:"fees"`
+	}
+)
+
+// NewReceipt converts a chain receipt of the OTX along with its L1 data fee, which is nil on chains without one. The fee
+// is computed on insert.
+func NewReceipt(otxID uint64, receipt *types.Receipt, l1Fee *big.Int) (Receipt, error) {
+	logs, err := json.Marshal(receipt.Logs)
+	if err != nil {
+		return Receipt{}, err
+	}
+
+	r := Receipt{
+		OTXID:       otxID,
+		TxHash:      receipt.TxHash.Hex(),
+		BlockNumber: receipt.BlockNumber.Uint64(),
+		BlockHash:   receipt.BlockHash.Hex(),
+		Success:     receipt.Status == types.ReceiptStatusSuccessful,
+		GasUsed:     receipt.GasUsed,
+		Logs:        logs,
+	}
+	if receipt.EffectiveGasPrice != nil {
+		r.EffectiveGasPrice = receipt.EffectiveGasPrice.String()
+	} else {
+		r.EffectiveGasPrice = "0"
+	}
+	if l1Fee != nil {
+		r.L1Fee = l1Fee.String()
+	} else {
+		r.L1Fee = "0"
+	}
+	if receipt.ContractAddress != (common.Address{}) {
+		contractAddress := receipt.ContractAddress.Hex()
+		r.ContractAddress = &contractAddress
+	}
+
+	return r, nil
+}
+
+func (pg *Pg) UpsertReceipt(ctx context.Context, tx pgx.Tx, receipt Receipt) error {
+	_, err := tx.Exec(
+		ctx,
+		pg.queries.UpsertReceipt,
+		receipt.OTXID,
+		receipt.TxHash,
+		receipt.BlockNumber,
+		receipt.BlockHash,
+		receipt.Success,
+		receipt.GasUsed,
+		receipt.EffectiveGasPrice,
+		receipt.ContractAddress,
+		receipt.Logs,
+		receipt.L1Fee,
+	)
+	return err
+}
+
+func (pg *Pg) DeleteReceipt(ctx context.Context, tx pgx.Tx, otxID uint64) error {
+	_, err := tx.Exec(ctx, pg.queries.DeleteReceipt, otxID)
+	return err
+}
+
+func (pg *Pg) GetReceiptsByTrackingID(ctx context.Context, tx pgx.Tx, trackingID string) ([]*Receipt, error) {
+	var receipts []*Receipt
+
+	if err := pgxscan.Select(ctx, tx, &receipts, pg.queries.GetReceipts, trackingID); err != nil {
+		return nil, err
+	}
+
+	return receipts, nil
+}
+
+func (pg *Pg) GetFeeReport(ctx context.Context, tx pgx.Tx, from time.Time, to time.Time) ([]*FeeReport, error) {
+	var feeReport []*FeeReport
+
+	if err := pgxscan.Select(ctx, tx, &feeReport, pg.queries.GetFeeReport, from, to); err != nil {
+		return nil, err
+	}
+
+	return feeReport, nil
+}
@@ -0,0 +1,52 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+)
+
+type RetrierAction struct {
+	ID             uint64    `db:"id" json:"id"`
+	OTXID          uint64    `db:"otx_id" json:"otxId"`
+	Attempt        int       `db:"attempt" json:"attempt"`
+	DispatchStatus string    `db:"dispatch_status" json:"dispatchStatus"`
+	Action         string    `db:"action" json:"action"`
+	Detail         string    `db:"detail" json:"detail"`
+	CreatedAt      time.Time `db:"created_at" json:"createdAt"`
+}
+
+const (
+	RETRY_GAS_REFILL_REQUESTED string = "GAS_REFILL_REQUESTED"
+	RETRY_AWAITING_GAS_REFILL  string = "AWAITING_GAS_REFILL"
+	RETRY_REDISPATCHED         string = "REDISPATCHED"
+	RETRY_FEE_BUMPED           string = "FEE_BUMPED"
+	RETRY_RECEIPT_RECONCILED   string = "RECEIPT_RECONCILED"
+	RETRY_NONCE_CONSUMED       string = "NONCE_CONSUMED"
+	RETRY_GAVE_UP              string = "GAVE_UP"
+)
+
+func (pg *Pg) InsertRetrierAction(ctx context.Context, tx pgx.Tx, retrierAction RetrierAction) error {
+	_, err := tx.Exec(
+		ctx,
+		pg.queries.InsertRetrierAction,
+		retrierAction.OTXID,
+		retrierAction.Attempt,
+		retrierAction.DispatchStatus,
+		retrierAction.Action,
+		retrierAction.Detail,
+	)
+	return err
+}
+
+func (pg *Pg) GetRetrierActionsByTrackingID(ctx context.Context, tx pgx.Tx, trackingID string) ([]*RetrierAction, error) {
+	var retrierActions []*RetrierAction
+
+	if err := pgxscan.Select(ctx, tx, &retrierActions, pg.queries.GetRetrierActions, trackingID); err != nil {
+		return nil, err
+	}
+
+	return retrierActions, nil
+}
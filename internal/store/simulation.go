@@ -0,0 +1,45 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+)
+
+// SimulationFailure is a transaction that reverted when simulated before signing, it was never signed or dispatched.
+type SimulationFailure struct {
+	ID              uint64    `db:"id" json:"id"`
+	TrackingID      string    `db:"tracking_id" json:"trackingId"`
+	OTXType         string    `db:"otx_type" json:"otxType"`
+	SignerAccount   string    `db:"signer_account" json:"signerAccount"`
+	ContractAddress string    `db:"contract_address" json:"contractAddress"`
+	Input           string    `db:"input" json:"input"`
+	RevertReason    string    `db:"revert_reason" json:"revertReason"`
+	CreatedAt       time.Time `db:"created_at" json:"createdAt"`
+}
+
+func (pg *Pg) InsertSimulationFailure(ctx context.Context, tx pgx.Tx, simulationFailure SimulationFailure) error {
+	_, err := tx.Exec(
+		ctx,
+		pg.queries.InsertSimulationFailure,
+		simulationFailure.TrackingID,
+		simulationFailure.OTXType,
+		simulationFailure.SignerAccount,
+		simulationFailure.ContractAddress,
+		simulationFailure.Input,
+		simulationFailure.RevertReason,
+	)
+	return err
+}
+
+func (pg *Pg) GetSimulationFailuresByTrackingID(ctx context.Context, tx pgx.Tx, trackingID string) ([]*SimulationFailure, error) {
+	var simulationFailures []*SimulationFailure
+
+	if err := pgxscan.Select(ctx, tx, &simulationFailures, pg.queries.GetSimulationFailures, trackingID); err != nil {
+		return nil, err
+	}
+
+	return simulationFailures, nil
+}
@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"time"
 
 	"github.com/grassrootseconomics/eth-custodial/internal/keypair"
 	"github.com/jackc/pgx/v5"
@@ -15,12 +16,47 @@ type Store interface {
 	InsertKeyPair(context.Context, pgx.Tx, keypair.Key) error
 	ActivateKeyPair(context.Context, pgx.Tx, string) error
 	CheckKeypair(context.Context, pgx.Tx, string) (bool, error)
+	KeyPairExists(context.Context, pgx.Tx, string) (bool, error)
+	CreateKeyPair(context.Context, pgx.Tx) (string, error)
 	LoadPrivateKey(context.Context, pgx.Tx, string) (keypair.Key, error)
 	LoadMasterSignerKey(context.Context, pgx.Tx) (keypair.Key, error)
+	LoadMasterSignerAddress(context.Context, pgx.Tx) (string, error)
+	RekeyKeyPairs(context.Context, pgx.Tx, int) (int, error)
+	// System signers
+	GetSystemSigners(context.Context, pgx.Tx) ([]*SystemSigner, error)
+	NextSystemSigner(context.Context, pgx.Tx, string) (string, error)
+	AddSystemSigner(context.Context, pgx.Tx, keypair.Key) error
+	AddExternalSystemSigner(context.Context, pgx.Tx, string) error
+	EnableSystemSigner(context.Context, pgx.Tx, string) error
+	RetireSystemSigner(context.Context, pgx.Tx, string) error
+	BeginMasterSignerRotation(context.Context, pgx.Tx, string, keypair.Key) (uint64, error)
+	SwitchMasterSignerRotation(context.Context, pgx.Tx, uint64) error
+	CompleteMasterSignerRotation(context.Context, pgx.Tx, uint64) error
+	GetPendingMasterSignerRotation(context.Context, pgx.Tx) (*MasterSignerRotation, error)
+	// Account lifecycle
+	GetAccountStatus(context.Context, pgx.Tx, string) (string, error)
+	UpdateAccountStatus(context.Context, pgx.Tx, AccountStatusChange) error
+	GetAccountStatusChanges(context.Context, pgx.Tx, string) ([]*AccountStatusChange, error)
+	GetQueuedJobsByAccount(context.Context, pgx.Tx, string) ([]*QueuedJob, error)
+	CancelPendingDispatchByAccount(context.Context, pgx.Tx, string) ([]string, error)
+	// Key access log
+	GetKeyAccessLogByAccount(context.Context, pgx.Tx, string, int) ([]*KeyAccessLog, error)
+	GetKeyAccessLogByAccountNext(context.Context, pgx.Tx, string, int, int) ([]*KeyAccessLog, error)
+	GetKeyAccessLogByAccountPrevious(context.Context, pgx.Tx, string, int, int) ([]*KeyAccessLog, error)
+	GetSealedKeyAccessLog(context.Context, pgx.Tx, uint64, int) ([]*KeyAccessLog, error)
+	SealKeyAccessLog(context.Context, pgx.Tx, int) (int, error)
+	// Key export
+	InsertKeyExport(context.Context, pgx.Tx, KeyExport) error
 	// Nonce
 	PeekNonce(context.Context, pgx.Tx, string) (uint64, error)
 	AcquireNonce(context.Context, pgx.Tx, string) (uint64, error)
+	GetNextNonce(context.Context, pgx.Tx, string) (uint64, error)
 	SetAccountNonce(context.Context, pgx.Tx, string, uint64) error
+	RaiseAccountNonce(context.Context, pgx.Tx, string, uint64) error
+	GetNonceCheckAccounts(context.Context, pgx.Tx, time.Time, int) ([]*NonceCheckAccount, error)
+	GetNonceGaps(context.Context, pgx.Tx, string, uint64, int) ([]uint64, error)
+	InsertNonceGap(context.Context, pgx.Tx, NonceGap) error
+	GetNonceGapsReport(context.Context, pgx.Tx, int) ([]*NonceGap, error)
 	// OTX
 	InsertOTX(context.Context, pgx.Tx, OTX) (uint64, error)
 	GetOTXByTxHash(context.Context, pgx.Tx, string) (OTX, error)
@@ -28,8 +64,27 @@ type Store interface {
 	GetOTXByAccount(context.Context, pgx.Tx, string, int) ([]*OTX, error)
 	GetOTXByAccountNext(context.Context, pgx.Tx, string, int, int) ([]*OTX, error)
 	GetOTXByAccountPrevious(context.Context, pgx.Tx, string, int, int) ([]*OTX, error)
-	GetFailedOTX(context.Context, pgx.Tx) ([]*OTX, error)
+	GetNonFinalOTX(context.Context, pgx.Tx, uint64, int) ([]*OTX, error)
+	MarkOTXReplaced(context.Context, pgx.Tx, uint64) error
+	GetDeployNonces(context.Context, pgx.Tx, string) ([]uint64, error)
 	// Dispatch
 	InsertDispatchTx(context.Context, pgx.Tx, DispatchTx) error
 	UpdateDispatchTxStatus(context.Context, pgx.Tx, DispatchTx) error
+	UpdateDispatchTxReceipt(context.Context, pgx.Tx, DispatchTx) error
+	GetUnconfirmedOTX(context.Context, pgx.Tx, int) ([]*UnconfirmedOTX, error)
+	GetDispatchState(context.Context, pgx.Tx, uint64) (*DispatchState, error)
+	GetDispatchDependency(context.Context, pgx.Tx, uint64, uint64) (*DispatchDependency, error)
+	GetPredecessorOTXID(context.Context, pgx.Tx, uint64) (uint64, error)
+	AbortSuccessors(context.Context, pgx.Tx, uint64) ([]uint64, error)
+	ResolveReplacements(context.Context, pgx.Tx, uint64) ([]*ResolvedOTX, error)
+	UpsertReceipt(context.Context, pgx.Tx, Receipt) error
+	DeleteReceipt(context.Context, pgx.Tx, uint64) error
+	GetReceiptsByTrackingID(context.Context, pgx.Tx, string) ([]*Receipt, error)
+	GetFeeReport(context.Context, pgx.Tx, time.Time, time.Time) ([]*FeeReport, error)
+	InsertDispatchAttempt(context.Context, pgx.Tx, DispatchAttempt) error
+	GetDispatchAttemptsByTrackingID(context.Context, pgx.Tx, string) ([]*DispatchAttempt, error)
+	InsertRetrierAction(context.Context, pgx.Tx, RetrierAction) error
+	GetRetrierActionsByTrackingID(context.Context, pgx.Tx, string) ([]*RetrierAction, error)
+	InsertSimulationFailure(context.Context, pgx.Tx, SimulationFailure) error
+	GetSimulationFailuresByTrackingID(context.Context, pgx.Tx, string) ([]*SimulationFailure, error)
 }
@@ -0,0 +1,191 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/grassrootseconomics/eth-custodial/internal/keypair"
+	"github.com/jackc/pgx/v5"
+)
+
+type SystemSigner struct {
+	PublicKey string    `db:"public_key" json:"publicKey"`
+	Enabled   bool      `db:"enabled" json:"enabled"`
+	Retired   bool      `db:"retired" json:"retired"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+}
+
+const (
+	SystemSignerRoundRobin   string = "round_robin"
+	SystemSignerLeastPending string = "least_pending"
+)
+
+var (
+	ErrNoSystemSigner      = errors.New("store: no active system signer available")
+	ErrLastSystemSigner    = errors.New("store: cannot retire the last active system signer")
+	ErrSystemSignerRetired = errors.New("store: system signer does not exist or is already retired")
+	ErrSystemSignerEnabled = errors.New("store: system signer does not exist, is retired or is already enabled")
+)
+
+func (pg *Pg) GetSystemSigners(ctx context.Context, tx pgx.Tx) ([]*SystemSigner, error) {
+	var systemSigners []*SystemSigner
+
+	if err := pgxscan.Select(ctx, tx, &systemSigners, pg.queries.GetSystemSigners); err != nil {
+		return nil, err
+	}
+
+	return systemSigners, nil
+}
+
+// NextSystemSigner assigns an enabled system signer for new work using either the round_robin or least_pending
+// strategy. Work that spans multiple transactions must reuse the returned signer.
+func (pg *Pg) NextSystemSigner(ctx context.Context, tx pgx.Tx, strategy string) (string, error) {
+	var query string
+
+	switch strategy {
+	case SystemSignerRoundRobin, "":
+		query = pg.queries.NextSystemSignerRR
+	case SystemSignerLeastPending:
+		query = pg.queries.NextSystemSignerLP
+	default:
+		return "", fmt.Errorf("store: unknown system signer strategy %q", strategy)
+	}
+
+	var publicKey string
+	if err := tx.QueryRow(ctx, query).Scan(&publicKey); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrNoSystemSigner
+		}
+		return "", err
+	}
+
+	return publicKey, nil
+}
+
+// AddSystemSigner saves a new key as an active account and adds it to the system signer pool. The signer receives no
+// work until EnableSystemSigner runs.
+func (pg *Pg) AddSystemSigner(ctx context.Context, tx pgx.Tx, key keypair.Key) error {
+	if err := pg.InsertKeyPair(ctx, tx, key); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, pg.queries.ActivateSystemKeyPair, key.Public); err != nil {
+		return err
+	}
+
+	var id uint64
+	return tx.QueryRow(ctx, pg.queries.AddSystemSigner, key.Public).Scan(&id)
+}
+
+// AddExternalSystemSigner adds an account whose key is held by an external signer such as an HSM to the system
+// signer pool. Only its address is saved. Like AddSystemSigner the signer starts disabled.
+func (pg *Pg) AddExternalSystemSigner(ctx context.Context, tx pgx.Tx, publicKey string) error {
+	var id uint64
+	if err := tx.QueryRow(ctx, pg.queries.InsertExternalKeyPair, publicKey).Scan(&id); err != nil {
+		return err
+	}
+
+	return tx.QueryRow(ctx, pg.queries.AddSystemSigner, publicKey).Scan(&id)
+}
+
+// EnableSystemSigner lets a disabled system signer receive work. Callers must check that it is funded and allowed on
+// the gas faucet and custodial registration proxy first.
+func (pg *Pg) EnableSystemSigner(ctx context.Context, tx pgx.Tx, publicKey string) error {
+	var id uint64
+	if err := tx.QueryRow(ctx, pg.queries.EnableSystemSigner, publicKey).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrSystemSignerEnabled
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (pg *Pg) RetireSystemSigner(ctx context.Context, tx pgx.Tx, publicKey string) error {
+	systemSigners, err := pg.GetSystemSigners(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	var (
+		active        int
+		retiresActive bool
+	)
+	for _, v := range systemSigners {
+		if v.Enabled && !v.Retired {
+			active++
+			retiresActive = retiresActive || v.PublicKey == publicKey
+		}
+	}
+	if retiresActive && active <= 1 {
+		return ErrLastSystemSigner
+	}
+
+	var id uint64
+	if err := tx.QueryRow(ctx, pg.queries.RetireSystemSigner, publicKey).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrSystemSignerRetired
+		}
+		return err
+	}
+
+	return nil
+}
+
+type MasterSignerRotation struct {
+	ID           uint64    `db:"id" json:"id"`
+	OldPublicKey string    `db:"old_public_key" json:"oldPublicKey"`
+	NewPublicKey string    `db:"new_public_key" json:"newPublicKey"`
+	Switched     bool      `db:"switched" json:"switched"`
+	CreatedAt    time.Time `db:"created_at" json:"createdAt"`
+}
+
+// BeginMasterSignerRotation saves the new key as an active account outside the system signer pool. The old system
+// signer keeps receiving work until SwitchMasterSignerRotation runs.
+func (pg *Pg) BeginMasterSignerRotation(ctx context.Context, tx pgx.Tx, oldPublicKey string, newKeyPair keypair.Key) (uint64, error) {
+	if err := pg.InsertKeyPair(ctx, tx, newKeyPair); err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(ctx, pg.queries.ActivateSystemKeyPair, newKeyPair.Public); err != nil {
+		return 0, err
+	}
+
+	var id uint64
+	if err := tx.QueryRow(ctx, pg.queries.BeginMasterRotation, oldPublicKey, newKeyPair.Public).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrSystemSignerRetired
+		}
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// SwitchMasterSignerRotation retires the old system signer and adds the new key to the system signer pool in the same
+// step so that the pool is never left without a signer. Callers must check that the new key is funded and allowed on
+// the gas faucet and custodial registration proxy first. The old key keeps its active status so that its pending
+// transactions can still be resigned.
+func (pg *Pg) SwitchMasterSignerRotation(ctx context.Context, tx pgx.Tx, rotationID uint64) error {
+	var id uint64
+	return tx.QueryRow(ctx, pg.queries.SwitchMasterRotation, rotationID).Scan(&id)
+}
+
+func (pg *Pg) CompleteMasterSignerRotation(ctx context.Context, tx pgx.Tx, rotationID uint64) error {
+	var id uint64
+	return tx.QueryRow(ctx, pg.queries.CompleteMasterRotation, rotationID).Scan(&id)
+}
+
+func (pg *Pg) GetPendingMasterSignerRotation(ctx context.Context, tx pgx.Tx) (*MasterSignerRotation, error) {
+	var rotation MasterSignerRotation
+
+	if err := pgxscan.Get(ctx, tx, &rotation, pg.queries.GetPendingRotation); err != nil {
+		return nil, err
+	}
+
+	return &rotation, nil
+}
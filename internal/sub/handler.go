@@ -6,6 +6,7 @@ import (
 	"errors"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/grassrootseconomics/eth-custodial/internal/multirpc"
 	"github.com/grassrootseconomics/eth-custodial/internal/store"
 	custodialEvent "github.com/grassrootseconomics/eth-custodial/pkg/event"
 	"github.com/grassrootseconomics/eth-tracker/pkg/event"
@@ -39,7 +40,8 @@ func (s *Sub) processEvent(ctx context.Context, msgSubject string, msg []byte) e
 	}
 
 	updateDispatchStatus := store.DispatchTx{
-		OTXID: otx.ID,
+		OTXID:       otx.ID,
+		BlockNumber: &chainEvent.Block,
 	}
 
 	if chainEvent.Success {
@@ -54,7 +56,27 @@ func (s *Sub) processEvent(ctx context.Context, msgSubject string, msg []byte) e
 		updateDispatchStatus.Status = store.REVERTED
 	}
 
-	if err := s.store.UpdateDispatchTxStatus(ctx, tx, updateDispatchStatus); err != nil {
+	// Best effort, the confirmation job stores the receipt of mined OTXs that are missing one
+	var receipt *multirpc.Receipt
+	if err := s.rpc.CallCtx(
+		ctx,
+		multirpc.TxReceipt(common.HexToHash(chainEvent.TxHash)).Returns(&receipt),
+	); err != nil {
+		s.logg.Warn("sub: could not fetch receipt", "tx_hash", chainEvent.TxHash, "error", err)
+	} else {
+		blockHash := receipt.BlockHash.Hex()
+		updateDispatchStatus.BlockHash = &blockHash
+
+		r, err := store.NewReceipt(otx.ID, receipt.Receipt, receipt.L1Fee)
+		if err != nil {
+			return err
+		}
+		if err := s.store.UpsertReceipt(ctx, tx, r); err != nil {
+			return err
+		}
+	}
+
+	if err := s.store.UpdateDispatchTxReceipt(ctx, tx, updateDispatchStatus); err != nil {
 		return err
 	}
 
@@ -63,6 +85,17 @@ func (s *Sub) processEvent(ctx context.Context, msgSubject string, msg []byte) e
 		Status:     updateDispatchStatus.Status,
 	})
 
+	resolved, err := s.store.ResolveReplacements(ctx, tx, otx.ID)
+	if err != nil {
+		return err
+	}
+	for _, v := range resolved {
+		s.pub.Send(ctx, custodialEvent.Event{
+			TrackingID: v.TrackingID,
+			Status:     v.Status,
+		})
+	}
+
 	// Divvi refferal submission
 	// Best effort, no error checking here
 	if s.activateDivviSubmissions {
@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/grassrootseconomics/eth-custodial/internal/multirpc"
 	"github.com/grassrootseconomics/eth-custodial/internal/pub"
 	"github.com/grassrootseconomics/eth-custodial/internal/store"
 	"github.com/grassrootseconomics/ethutils"
@@ -20,6 +21,7 @@ type (
 		ConsumerID               string
 		Pub                      *pub.Pub
 		Provider                 *ethutils.Provider
+		RPC                      *multirpc.Client
 		Logg                     *slog.Logger
 	}
 
@@ -30,6 +32,7 @@ type (
 		jsIter                   jetstream.MessagesContext
 		pub                      *pub.Pub
 		provider                 *ethutils.Provider
+		rpc                      *multirpc.Client
 		logg                     *slog.Logger
 	}
 )
@@ -73,6 +76,7 @@ func NewSub(o SubObts) (*Sub, error) {
 		pub:                      o.Pub,
 		logg:                     o.Logg,
 		provider:                 o.Provider,
+		rpc:                      o.RPC,
 	}, nil
 }
 
@@ -0,0 +1,80 @@
+package util
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/grassrootseconomics/eth-custodial/internal/envelope"
+	"github.com/knadh/koanf/v2"
+)
+
+// LoadKeyring builds the keystore keyring from the KEKs set in keystore.keks and optionally keystore.kek_file.
+// A nil keyring is returned when no KEK is configured at all.
+func LoadKeyring(ko *koanf.Koanf) (*envelope.Keyring, error) {
+	keks := make(map[int][]byte)
+
+	for version, kekHex := range ko.StringMap("keystore.keks") {
+		if err := addKEK(keks, version, kekHex); err != nil {
+			return nil, err
+		}
+	}
+
+	if kekFile := ko.String("keystore.kek_file"); kekFile != "" {
+		if err := loadKEKFile(keks, kekFile); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(keks) == 0 {
+		return nil, nil
+	}
+
+	return envelope.NewKeyring(ko.MustInt("keystore.kek_version"), keks)
+}
+
+// loadKEKFile reads one <version>=<hex kek> pair per line. Empty lines and lines starting with # are ignored.
+func loadKEKFile(keks map[int][]byte, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		version, kekHex, ok := strings.Cut(line, "=")
+		if !ok {
+			return fmt.Errorf("malformed kek file line %q", line)
+		}
+
+		if err := addKEK(keks, version, kekHex); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+func addKEK(keks map[int][]byte, version string, kekHex string) error {
+	v, err := strconv.Atoi(strings.TrimSpace(version))
+	if err != nil {
+		return fmt.Errorf("invalid kek version %q: %w", version, err)
+	}
+
+	kek, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(kekHex), "0x"))
+	if err != nil {
+		return fmt.Errorf("invalid kek for version %d: %w", v, err)
+	}
+
+	keks[v] = kek
+	return nil
+}
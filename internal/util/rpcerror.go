@@ -0,0 +1,20 @@
+package util
+
+import (
+	"github.com/grassrootseconomics/eth-custodial/internal/rpcerror"
+	"github.com/knadh/koanf/v2"
+)
+
+// LoadRPCErrorClassifier builds the classifier for rpc_errors.flavour with the patterns of rpc_errors.patterns checked
+// first.
+func LoadRPCErrorClassifier(ko *koanf.Koanf) (*rpcerror.Classifier, error) {
+	var extra []rpcerror.Pattern
+	for _, p := range ko.Slices("rpc_errors.patterns") {
+		extra = append(extra, rpcerror.Pattern{
+			Match:  p.String("match"),
+			Status: p.String("status"),
+		})
+	}
+
+	return rpcerror.New(ko.String("rpc_errors.flavour"), extra)
+}
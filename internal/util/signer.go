@@ -0,0 +1,78 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/grassrootseconomics/eth-custodial/internal/signer"
+	"github.com/grassrootseconomics/eth-custodial/internal/store"
+	"github.com/knadh/koanf/v2"
+)
+
+// LoadSigner builds the transaction signer backend selected by signer.backend. If signer.pkcs11 is configured, signing
+// for the HSM account is routed to the token and everything else to the backend.
+func LoadSigner(ko *koanf.Koanf, store store.Store, chainSigner types.Signer) (signer.Signer, error) {
+	base, err := loadBaseSigner(ko, store, chainSigner)
+	if err != nil {
+		return nil, err
+	}
+
+	hsm, err := LoadPKCS11Signer(ko, chainSigner)
+	if err != nil {
+		return nil, err
+	}
+	if hsm == nil {
+		return base, nil
+	}
+
+	return signer.NewRoutingSigner(base, map[common.Address]signer.Signer{
+		hsm.Address(): hsm,
+	}), nil
+}
+
+// LoadPKCS11Signer opens the token configured in signer.pkcs11. It returns nil if no module is set.
+func LoadPKCS11Signer(ko *koanf.Koanf, chainSigner types.Signer) (*signer.PKCS11Signer, error) {
+	module := ko.String("signer.pkcs11.module")
+	if module == "" {
+		return nil, nil
+	}
+
+	pin, err := os.ReadFile(ko.MustString("signer.pkcs11.pin_file"))
+	if err != nil {
+		return nil, err
+	}
+
+	return signer.NewPKCS11Signer(signer.PKCS11SignerOpts{
+		Module:      module,
+		TokenLabel:  ko.MustString("signer.pkcs11.token_label"),
+		PIN:         strings.TrimSpace(string(pin)),
+		KeyLabel:    ko.MustString("signer.pkcs11.key_label"),
+		ChainSigner: chainSigner,
+	})
+}
+
+func loadBaseSigner(ko *koanf.Koanf, store store.Store, chainSigner types.Signer) (signer.Signer, error) {
+	switch backend := ko.String("signer.backend"); backend {
+	case "", "keystore":
+		return signer.NewKeystoreSigner(signer.KeystoreSignerOpts{
+			Store:       store,
+			ChainSigner: chainSigner,
+		}), nil
+	case "keystore_dir":
+		return signer.NewKeystoreDirSigner(signer.KeystoreDirSignerOpts{
+			Dir:          ko.MustString("signer.keystore_dir"),
+			PasswordFile: ko.MustString("signer.password_file"),
+			ChainSigner:  chainSigner,
+		})
+	case "remote":
+		return signer.NewRemoteSigner(signer.RemoteSignerOpts{
+			Endpoint:    ko.MustString("signer.remote_endpoint"),
+			ChainSigner: chainSigner,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown signer backend %q", backend)
+	}
+}
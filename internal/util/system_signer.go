@@ -0,0 +1,59 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/grassrootseconomics/ethutils"
+	"github.com/knadh/koanf/v2"
+	"github.com/lmittmann/w3"
+	"github.com/lmittmann/w3/module/eth"
+)
+
+var isWriterFunc = w3.MustNewFunc("isWriter(address)", "bool")
+
+// CheckSystemSigner verifies on chain that a system signer holds at least workers.system_signer_min_balance and is an
+// allowed writer on the custodial registration proxy and the gas faucet. Signers failing the check would have every
+// registration and gas refill they sign reverted.
+func CheckSystemSigner(ctx context.Context, ko *koanf.Koanf, address common.Address) error {
+	minBalance, ok := new(big.Int).SetString(ko.String("workers.system_signer_min_balance"), 10)
+	if !ok {
+		return fmt.Errorf("invalid workers.system_signer_min_balance %q", ko.String("workers.system_signer_min_balance"))
+	}
+
+	chainProvider := ethutils.NewProvider(ko.MustString("chain.rpc_endpoint"), ko.MustInt64("chain.id"))
+	defer chainProvider.Client.Close()
+
+	registry, err := chainProvider.RegistryMap(ctx, ethutils.HexToAddress(ko.MustString("chain.ge_registry")))
+	if err != nil {
+		return err
+	}
+
+	var (
+		balance         *big.Int
+		proxyWriter     bool
+		gasFaucetWriter bool
+	)
+	if err := chainProvider.Client.CallCtx(
+		ctx,
+		eth.Balance(address, nil).Returns(&balance),
+		eth.CallFunc(registry[ethutils.CustodialProxy], isWriterFunc, address).Returns(&proxyWriter),
+		eth.CallFunc(registry[ethutils.GasFaucet], isWriterFunc, address).Returns(&gasFaucetWriter),
+	); err != nil {
+		return err
+	}
+
+	if balance.Cmp(minBalance) < 0 {
+		return fmt.Errorf("system signer %s balance %s is below the minimum %s", address.Hex(), balance.String(), minBalance.String())
+	}
+	if !proxyWriter {
+		return fmt.Errorf("system signer %s is not allowed on the custodial registration proxy %s", address.Hex(), registry[ethutils.CustodialProxy].Hex())
+	}
+	if !gasFaucetWriter {
+		return fmt.Errorf("system signer %s is not allowed on the gas faucet %s", address.Hex(), registry[ethutils.GasFaucet].Hex())
+	}
+
+	return nil
+}
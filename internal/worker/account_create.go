@@ -5,8 +5,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
-	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/grassrootseconomics/eth-custodial/internal/keypair"
+	"github.com/grassrootseconomics/eth-custodial/internal/gas"
 	"github.com/grassrootseconomics/eth-custodial/internal/store"
 	"github.com/grassrootseconomics/eth-custodial/pkg/event"
 	"github.com/grassrootseconomics/ethutils"
@@ -15,8 +14,9 @@ import (
 
 type (
 	AccountCreateArgs struct {
-		TrackingID string      `json:"trackingId"`
-		KeyPair    keypair.Key `json:"keypair"`
+		TrackingID string `json:"trackingId"`
+		// PublicKey refers to an inactive keystore entry saved together with the job.
+		PublicKey string `json:"publicKey"`
 	}
 
 	AccountCreateWorker struct {
@@ -37,41 +37,37 @@ func (w *AccountCreateWorker) Work(ctx context.Context, job *river.Job[AccountCr
 	}
 	defer tx.Rollback(ctx)
 
-	if err := w.wc.store.InsertKeyPair(ctx, tx, job.Args.KeyPair); err != nil {
-		return err
-	}
-
-	systemKeypair, err := w.wc.store.LoadMasterSignerKey(ctx, tx)
+	systemAddress, err := w.wc.assignSystemSigner(ctx, tx)
 	if err != nil {
 		return err
 	}
 
-	privateKey, err := crypto.HexToECDSA(systemKeypair.Private)
+	nonce, err := w.wc.store.AcquireNonce(ctx, tx, systemAddress)
 	if err != nil {
 		return err
 	}
 
-	nonce, err := w.wc.store.AcquireNonce(ctx, tx, systemKeypair.Public)
+	input, err := Abi[Register].EncodeArgs(ethutils.HexToAddress(job.Args.PublicKey))
 	if err != nil {
 		return err
 	}
 
-	input, err := Abi[Register].EncodeArgs(ethutils.HexToAddress(job.Args.KeyPair.Public))
+	gasSettings, err := w.wc.gasOracle.GetSettings(gas.DefaultFeeClass(store.ACCOUNT_REGISTER))
 	if err != nil {
 		return err
 	}
 
-	gasSettings, err := w.wc.gasOracle.GetSettings()
+	gasLimit, err := w.wc.gasLimitEstimator.Estimate(ctx, store.ACCOUNT_REGISTER, ethutils.HexToAddress(systemAddress), w.custodialRegistrationProxy, input, nil)
 	if err != nil {
 		return err
 	}
 
-	builtTx, err := w.wc.chainProvider.SignContractExecutionTx(privateKey, ethutils.ContractExecutionTxOpts{
+	builtTx, err := w.wc.signContractExecutionTx(ctx, tx, systemAddress, ethutils.ContractExecutionTxOpts{
 		ContractAddress: w.custodialRegistrationProxy,
-		InputData:       addDivviRefferalTag(w.wc.chainProvider, input, ethutils.HexToAddress(job.Args.KeyPair.Public)),
+		InputData:       addDivviRefferalTag(w.wc.chainProvider, input, ethutils.HexToAddress(job.Args.PublicKey)),
 		GasFeeCap:       gasSettings.GasFeeCap,
 		GasTipCap:       gasSettings.GasTipCap,
-		GasLimit:        gasSettings.GasLimit,
+		GasLimit:        gasLimit.Limit,
 		Nonce:           nonce,
 	})
 	if err != nil {
@@ -86,12 +82,14 @@ func (w *AccountCreateWorker) Work(ctx context.Context, job *river.Job[AccountCr
 	rawTxHex := hexutil.Encode(rawTx)
 
 	otxID, err := w.wc.store.InsertOTX(ctx, tx, store.OTX{
-		TrackingID:    job.Args.TrackingID,
-		OTXType:       store.ACCOUNT_REGISTER,
-		SignerAccount: systemKeypair.Public,
-		RawTx:         rawTxHex,
-		TxHash:        builtTx.Hash().Hex(),
-		Nonce:         nonce,
+		TrackingID:     job.Args.TrackingID,
+		OTXType:        store.ACCOUNT_REGISTER,
+		SignerAccount:  systemAddress,
+		RawTx:          rawTxHex,
+		TxHash:         builtTx.Hash().Hex(),
+		Nonce:          nonce,
+		GasLimit:       gasLimit.Limit,
+		GasLimitSource: gasLimit.Source,
 	})
 	if err != nil {
 		return err
@@ -0,0 +1,71 @@
+package worker
+
+import (
+	"context"
+
+	"github.com/grassrootseconomics/eth-custodial/internal/store"
+	"github.com/grassrootseconomics/eth-custodial/pkg/event"
+	"github.com/riverqueue/river"
+)
+
+type (
+	AccountDeactivateArgs struct {
+		PublicKey string `json:"publicKey"`
+	}
+
+	AccountDeactivateWorker struct {
+		river.WorkerDefaults[AccountDeactivateArgs]
+		wc *WorkerContainer
+	}
+)
+
+const AccountDeactivateID = "ACCOUNT_DEACTIVATE"
+
+func (AccountDeactivateArgs) Kind() string { return AccountDeactivateID }
+
+// Work cancels all queued jobs and undispatched transactions of an account that was frozen or closed. Jobs that slip
+// through are refused at signing time.
+func (w *AccountDeactivateWorker) Work(ctx context.Context, job *river.Job[AccountDeactivateArgs]) error {
+	tx, err := w.wc.store.Pool().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	queuedJobs, err := w.wc.store.GetQueuedJobsByAccount(ctx, tx, job.Args.PublicKey)
+	if err != nil {
+		return err
+	}
+
+	trackingIDs := make(map[string]struct{})
+	for _, v := range queuedJobs {
+		if _, err := w.wc.queueClient.JobCancelTx(ctx, tx, v.ID); err != nil {
+			return err
+		}
+		if v.TrackingID != "" {
+			trackingIDs[v.TrackingID] = struct{}{}
+		}
+	}
+
+	cancelledTrackingIDs, err := w.wc.store.CancelPendingDispatchByAccount(ctx, tx, job.Args.PublicKey)
+	if err != nil {
+		return err
+	}
+	for _, v := range cancelledTrackingIDs {
+		trackingIDs[v] = struct{}{}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	w.wc.logg.Info("cancelled queued work for inactive account", "account", job.Args.PublicKey, "jobs", len(queuedJobs), "otxs", len(cancelledTrackingIDs))
+
+	for trackingID := range trackingIDs {
+		w.wc.pub.Send(ctx, event.Event{
+			TrackingID: trackingID,
+			Status:     store.ACCOUNT_INACTIVE,
+		})
+	}
+
+	return nil
+}
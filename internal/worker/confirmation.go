@@ -0,0 +1,217 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/grassrootseconomics/eth-custodial/internal/multirpc"
+	"github.com/grassrootseconomics/eth-custodial/internal/store"
+	"github.com/grassrootseconomics/eth-custodial/pkg/event"
+	"github.com/jackc/pgx/v5"
+	"github.com/lmittmann/w3"
+	"github.com/lmittmann/w3/module/eth"
+	"github.com/lmittmann/w3/w3types"
+	"github.com/riverqueue/river"
+)
+
+type (
+	ConfirmationArgs struct{}
+
+	ConfirmationWorker struct {
+		river.WorkerDefaults[ConfirmationArgs]
+		wc *WorkerContainer
+	}
+)
+
+const (
+	ConfirmationID = "CONFIRMATION"
+
+	confirmationBatchSize = 250
+)
+
+func (ConfirmationArgs) Kind() string { return ConfirmationID }
+
+// Work re-checks the receipts of mined OTXs. Once the receipt is deep enough the OTX is CONFIRMED. A receipt that
+// disappeared or moved to another block means the chain reorganized, the OTX is moved back to IN_NETWORK and
+// re-dispatched. A disappeared receipt is only trusted once a second node confirms it. REVERTED OTXs missing a
+// receipt, e.g. because the node lagged when the event arrived, only get their receipt stored.
+func (w *ConfirmationWorker) Work(ctx context.Context, _ *river.Job[ConfirmationArgs]) error {
+	tx, err := w.wc.store.Pool().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	unconfirmed, err := w.wc.store.GetUnconfirmedOTX(ctx, tx, confirmationBatchSize)
+	if err != nil {
+		return err
+	}
+
+	if len(unconfirmed) < 1 {
+		return nil
+	}
+
+	var head *big.Int
+	calls := make([]w3types.RPCCaller, len(unconfirmed)+1)
+	receipts := make([]*multirpc.Receipt, len(unconfirmed))
+
+	calls[0] = eth.BlockNumber().Returns(&head)
+	for i, v := range unconfirmed {
+		calls[i+1] = multirpc.TxReceipt(common.HexToHash(v.TxHash)).Returns(&receipts[i])
+	}
+
+	callErrs := make(w3.CallErrors, len(calls))
+	endpoint, err := w.wc.rpc.CallCtxEndpoint(ctx, calls...)
+	if err != nil && !errors.As(err, &callErrs) {
+		return err
+	}
+	if callErrs[0] != nil {
+		return callErrs[0]
+	}
+
+	var missing []*store.UnconfirmedOTX
+	for i, v := range unconfirmed {
+		receiptErr := callErrs[i+1]
+
+		switch {
+		case receiptErr != nil && v.Status == store.REVERTED:
+			w.wc.logg.Warn("confirmation: failed to backfill reverted receipt", "otx_id", v.ID, "error", receiptErr)
+		case isNotFound(receiptErr):
+			missing = append(missing, v)
+		case receiptErr != nil:
+			w.wc.logg.Warn("confirmation: failed to fetch receipt", "otx_id", v.ID, "error", receiptErr)
+		case v.Status == store.REVERTED:
+			if err := w.wc.storeReceipt(ctx, tx, v.ID, receipts[i]); err != nil {
+				return err
+			}
+		default:
+			if err := w.check(ctx, tx, v, receipts[i], head.Uint64()); err != nil {
+				return err
+			}
+		}
+	}
+
+	reorged, err := w.confirmReorged(ctx, endpoint, missing)
+	if err != nil {
+		return err
+	}
+	for _, v := range reorged {
+		if err := w.reorged(ctx, tx, v); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// confirmReorged re-fetches the receipts that endpoint no longer has from another node, so that a single node that is
+// out of sync can't trigger a re-dispatch. Only OTXs without a receipt on both nodes are returned, the others are
+// checked again on the next run.
+func (w *ConfirmationWorker) confirmReorged(ctx context.Context, endpoint string, missing []*store.UnconfirmedOTX) ([]*store.UnconfirmedOTX, error) {
+	if len(missing) < 1 {
+		return nil, nil
+	}
+
+	calls := make([]w3types.RPCCaller, len(missing))
+	receipts := make([]*types.Receipt, len(missing))
+	for i, v := range missing {
+		calls[i] = eth.TxReceipt(common.HexToHash(v.TxHash)).Returns(&receipts[i])
+	}
+
+	callErrs := make(w3.CallErrors, len(calls))
+	if err := w.wc.rpc.CallCtxExcept(ctx, endpoint, calls...); err != nil && !errors.As(err, &callErrs) {
+		return nil, err
+	}
+
+	var reorged []*store.UnconfirmedOTX
+	for i, v := range missing {
+		if isNotFound(callErrs[i]) {
+			reorged = append(reorged, v)
+			continue
+		}
+		w.wc.logg.Warn("confirmation: receipt missing on one node only, not re-dispatching", "otx_id", v.ID, "endpoint", endpoint, "error", callErrs[i])
+	}
+
+	return reorged, nil
+}
+
+func (w *ConfirmationWorker) check(ctx context.Context, tx pgx.Tx, otx *store.UnconfirmedOTX, receipt *multirpc.Receipt, head uint64) error {
+	blockNumber := receipt.BlockNumber.Uint64()
+	blockHash := receipt.BlockHash.Hex()
+
+	updateDispatchStatus := store.DispatchTx{
+		OTXID:       otx.ID,
+		Status:      store.SUCCESS,
+		BlockNumber: &blockNumber,
+		BlockHash:   &blockHash,
+	}
+
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		// Re-included in another block where it reverted
+		updateDispatchStatus.Status = store.REVERTED
+	} else if head >= blockNumber+w.wc.confirmations {
+		updateDispatchStatus.Status = store.CONFIRMED
+	} else if otx.BlockHash != nil && *otx.BlockHash == blockHash {
+		return nil
+	}
+
+	if otx.BlockHash != nil && *otx.BlockHash != blockHash {
+		w.wc.logg.Warn("confirmation: otx moved to another block", "otx_id", otx.ID, "old_block_hash", *otx.BlockHash, "block_hash", blockHash)
+	}
+
+	if err := w.wc.store.UpdateDispatchTxReceipt(ctx, tx, updateDispatchStatus); err != nil {
+		return err
+	}
+
+	if err := w.wc.storeReceipt(ctx, tx, otx.ID, receipt); err != nil {
+		return err
+	}
+
+	if updateDispatchStatus.Status != store.SUCCESS {
+		w.wc.pub.Send(ctx, event.Event{
+			TrackingID: otx.TrackingID,
+			Status:     updateDispatchStatus.Status,
+		})
+	}
+
+	return nil
+}
+
+func (w *ConfirmationWorker) reorged(ctx context.Context, tx pgx.Tx, otx *store.UnconfirmedOTX) error {
+	w.wc.logg.Warn("confirmation: receipt no longer canonical, re-dispatching", "otx_id", otx.ID, "tx_hash", otx.TxHash)
+
+	if err := w.wc.store.UpdateDispatchTxReceipt(ctx, tx, store.DispatchTx{
+		OTXID:  otx.ID,
+		Status: store.IN_NETWORK,
+	}); err != nil {
+		return err
+	}
+
+	if err := w.wc.store.DeleteReceipt(ctx, tx, otx.ID); err != nil {
+		return err
+	}
+
+	w.wc.pub.Send(ctx, event.Event{
+		TrackingID: otx.TrackingID,
+		Status:     store.IN_NETWORK,
+	})
+
+	_, err := w.wc.queueClient.InsertTx(ctx, tx, DispatchArgs{
+		TrackingID: otx.TrackingID,
+		OTXID:      otx.ID,
+		RawTx:      otx.RawTx,
+	}, nil)
+	return err
+}
+
+func (wc *WorkerContainer) storeReceipt(ctx context.Context, tx pgx.Tx, otxID uint64, receipt *multirpc.Receipt) error {
+	r, err := store.NewReceipt(otxID, receipt.Receipt, receipt.L1Fee)
+	if err != nil {
+		return err
+	}
+
+	return wc.store.UpsertReceipt(ctx, tx, r)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/grassrootseconomics/eth-custodial/internal/gas"
 	"github.com/grassrootseconomics/eth-custodial/internal/store"
 	"github.com/grassrootseconomics/eth-custodial/pkg/event"
 	"github.com/grassrootseconomics/ethutils"
@@ -27,6 +28,7 @@ type DemurrageTokenDeployArgs struct {
 	SinkAddress     string `json:"sinkAddress"`
 	DemurrageRate   string `json:"demurrageRate"`
 	DemurragePeriod string `json:"demurragePeriod"`
+	FeeClass        string `json:"feeClass,omitempty"`
 }
 
 type DemurrageTokenDeployWorker struct {
@@ -44,22 +46,17 @@ func (w *DemurrageTokenDeployWorker) Work(ctx context.Context, job *river.Job[De
 	}
 	defer tx.Rollback(ctx)
 
-	systemKeypair, err := w.wc.store.LoadMasterSignerKey(ctx, tx)
+	systemAddress, err := w.wc.assignSystemSigner(ctx, tx)
 	if err != nil {
 		return err
 	}
 
-	privateKey, err := crypto.HexToECDSA(systemKeypair.Private)
+	gasSettings, err := w.wc.gasOracle.GetSettings(gas.ResolveFeeClass(job.Args.FeeClass, store.DEMURRAGE_TOKEN_DEPLOY))
 	if err != nil {
 		return err
 	}
 
-	gasSettings, err := w.wc.gasOracle.GetSettings()
-	if err != nil {
-		return err
-	}
-
-	nonce, err := w.wc.store.AcquireNonce(ctx, tx, systemKeypair.Public)
+	nonce, err := w.wc.store.AcquireNonce(ctx, tx, systemAddress)
 	if err != nil {
 		return err
 	}
@@ -89,8 +86,8 @@ func (w *DemurrageTokenDeployWorker) Work(ctx context.Context, job *river.Job[De
 	if err != nil {
 	}
 
-	builtContractDeployTx, err := w.wc.chainProvider.SignContractPublishTx(privateKey, ethutils.ContractPublishTxOpts{
-		ContractByteCode: addDivviRefferalTag(w.wc.chainProvider, byteCode, ethutils.HexToAddress(systemKeypair.Public)),
+	builtContractDeployTx, err := w.wc.signContractPublishTx(ctx, tx, systemAddress, ethutils.ContractPublishTxOpts{
+		ContractByteCode: addDivviRefferalTag(w.wc.chainProvider, byteCode, ethutils.HexToAddress(systemAddress)),
 		GasFeeCap:        gasSettings.GasFeeCap,
 		GasTipCap:        gasSettings.GasTipCap,
 		GasLimit:         contract.MaxGasLimit(),
@@ -110,7 +107,7 @@ func (w *DemurrageTokenDeployWorker) Work(ctx context.Context, job *river.Job[De
 	deployContractOTXID, err := w.wc.store.InsertOTX(ctx, tx, store.OTX{
 		TrackingID:    job.Args.TrackingID,
 		OTXType:       store.DEMURRAGE_TOKEN_DEPLOY,
-		SignerAccount: systemKeypair.Public,
+		SignerAccount: systemAddress,
 		RawTx:         rawContractDeployTxHex,
 		TxHash:        builtContractDeployTx.Hash().Hex(),
 		Nonce:         nonce,
@@ -130,24 +127,29 @@ func (w *DemurrageTokenDeployWorker) Work(ctx context.Context, job *river.Job[De
 		Status:     store.PENDING,
 	})
 
-	contractAddress := crypto.CreateAddress(common.HexToAddress(systemKeypair.Public), nonce)
+	contractAddress := crypto.CreateAddress(common.HexToAddress(systemAddress), nonce)
 
 	addData, err := Abi[Add].EncodeArgs(contractAddress)
 	if err != nil {
 		return err
 	}
 
-	addNonce, err := w.wc.store.AcquireNonce(ctx, tx, systemKeypair.Public)
+	addNonce, err := w.wc.store.AcquireNonce(ctx, tx, systemAddress)
 	if err != nil {
 		return err
 	}
 
-	builtAddTx, err := w.wc.chainProvider.SignContractExecutionTx(privateKey, ethutils.ContractExecutionTxOpts{
+	addGasLimit, err := w.wc.gasLimitEstimator.Estimate(ctx, store.TOKEN_INDEX_ADD, ethutils.HexToAddress(systemAddress), w.tokenIndex, addData, nil)
+	if err != nil {
+		return err
+	}
+
+	builtAddTx, err := w.wc.signContractExecutionTx(ctx, tx, systemAddress, ethutils.ContractExecutionTxOpts{
 		ContractAddress: w.tokenIndex,
-		InputData:       addDivviRefferalTag(w.wc.chainProvider, addData, ethutils.HexToAddress(systemKeypair.Public)),
+		InputData:       addDivviRefferalTag(w.wc.chainProvider, addData, ethutils.HexToAddress(systemAddress)),
 		GasFeeCap:       gasSettings.GasFeeCap,
 		GasTipCap:       gasSettings.GasTipCap,
-		GasLimit:        gasSettings.GasLimit,
+		GasLimit:        addGasLimit.Limit,
 		Nonce:           addNonce,
 	})
 	if err != nil {
@@ -162,12 +164,14 @@ func (w *DemurrageTokenDeployWorker) Work(ctx context.Context, job *river.Job[De
 	rawAddTxHex := hexutil.Encode(rawAddTx)
 
 	addOTXID, err := w.wc.store.InsertOTX(ctx, tx, store.OTX{
-		TrackingID:    job.Args.TrackingID,
-		OTXType:       store.TOKEN_INDEX_ADD,
-		SignerAccount: systemKeypair.Public,
-		RawTx:         rawAddTxHex,
-		TxHash:        builtAddTx.Hash().Hex(),
-		Nonce:         addNonce,
+		TrackingID:     job.Args.TrackingID,
+		OTXType:        store.TOKEN_INDEX_ADD,
+		SignerAccount:  systemAddress,
+		RawTx:          rawAddTxHex,
+		TxHash:         builtAddTx.Hash().Hex(),
+		Nonce:          addNonce,
+		GasLimit:       addGasLimit.Limit,
+		GasLimitSource: addGasLimit.Source,
 	})
 	if err != nil {
 		return err
@@ -193,17 +197,22 @@ func (w *DemurrageTokenDeployWorker) Work(ctx context.Context, job *river.Job[De
 		return err
 	}
 
-	mintToNonce, err := w.wc.store.AcquireNonce(ctx, tx, systemKeypair.Public)
+	mintToNonce, err := w.wc.store.AcquireNonce(ctx, tx, systemAddress)
+	if err != nil {
+		return err
+	}
+
+	mintToGasLimit, err := w.wc.gasLimitEstimator.Estimate(ctx, store.TOKEN_TRANSFER, ethutils.HexToAddress(systemAddress), contractAddress, mintToData, nil)
 	if err != nil {
 		return err
 	}
 
-	builtMintToTx, err := w.wc.chainProvider.SignContractExecutionTx(privateKey, ethutils.ContractExecutionTxOpts{
+	builtMintToTx, err := w.wc.signContractExecutionTx(ctx, tx, systemAddress, ethutils.ContractExecutionTxOpts{
 		ContractAddress: contractAddress,
-		InputData:       addDivviRefferalTag(w.wc.chainProvider, mintToData, ethutils.HexToAddress(systemKeypair.Public)),
+		InputData:       addDivviRefferalTag(w.wc.chainProvider, mintToData, ethutils.HexToAddress(systemAddress)),
 		GasFeeCap:       gasSettings.GasFeeCap,
 		GasTipCap:       gasSettings.GasTipCap,
-		GasLimit:        gasSettings.GasLimit,
+		GasLimit:        mintToGasLimit.Limit,
 		Nonce:           mintToNonce,
 	})
 	if err != nil {
@@ -218,12 +227,14 @@ func (w *DemurrageTokenDeployWorker) Work(ctx context.Context, job *river.Job[De
 	rawMintToTxHex := hexutil.Encode(rawMintToTx)
 
 	mintToOTXID, err := w.wc.store.InsertOTX(ctx, tx, store.OTX{
-		TrackingID:    job.Args.TrackingID,
-		OTXType:       store.TOKEN_TRANSFER,
-		SignerAccount: systemKeypair.Public,
-		RawTx:         rawMintToTxHex,
-		TxHash:        builtMintToTx.Hash().Hex(),
-		Nonce:         mintToNonce,
+		TrackingID:     job.Args.TrackingID,
+		OTXType:        store.TOKEN_TRANSFER,
+		SignerAccount:  systemAddress,
+		RawTx:          rawMintToTxHex,
+		TxHash:         builtMintToTx.Hash().Hex(),
+		Nonce:          mintToNonce,
+		GasLimit:       mintToGasLimit.Limit,
+		GasLimitSource: mintToGasLimit.Source,
 	})
 	if err != nil {
 		return err
@@ -243,17 +254,22 @@ func (w *DemurrageTokenDeployWorker) Work(ctx context.Context, job *river.Job[De
 		return err
 	}
 
-	transferOwnershipNonce, err := w.wc.store.AcquireNonce(ctx, tx, systemKeypair.Public)
+	transferOwnershipNonce, err := w.wc.store.AcquireNonce(ctx, tx, systemAddress)
 	if err != nil {
 		return err
 	}
 
-	builtTransferOwnershipTx, err := w.wc.chainProvider.SignContractExecutionTx(privateKey, ethutils.ContractExecutionTxOpts{
+	transferOwnershipGasLimit, err := w.wc.gasLimitEstimator.Estimate(ctx, store.TRANSFER_OWNERSHIP, ethutils.HexToAddress(systemAddress), contractAddress, transferOwnershipData, nil)
+	if err != nil {
+		return err
+	}
+
+	builtTransferOwnershipTx, err := w.wc.signContractExecutionTx(ctx, tx, systemAddress, ethutils.ContractExecutionTxOpts{
 		ContractAddress: contractAddress,
-		InputData:       addDivviRefferalTag(w.wc.chainProvider, transferOwnershipData, ethutils.HexToAddress(systemKeypair.Public)),
+		InputData:       addDivviRefferalTag(w.wc.chainProvider, transferOwnershipData, ethutils.HexToAddress(systemAddress)),
 		GasFeeCap:       gasSettings.GasFeeCap,
 		GasTipCap:       gasSettings.GasTipCap,
-		GasLimit:        gasSettings.GasLimit,
+		GasLimit:        transferOwnershipGasLimit.Limit,
 		Nonce:           transferOwnershipNonce,
 	})
 	if err != nil {
@@ -268,12 +284,14 @@ func (w *DemurrageTokenDeployWorker) Work(ctx context.Context, job *river.Job[De
 	rawTransferOwnershipTxHex := hexutil.Encode(rawTransferOwnershipTx)
 
 	transferOwnershipOTXID, err := w.wc.store.InsertOTX(ctx, tx, store.OTX{
-		TrackingID:    job.Args.TrackingID,
-		OTXType:       store.TRANSFER_OWNERSHIP,
-		SignerAccount: systemKeypair.Public,
-		RawTx:         rawTransferOwnershipTxHex,
-		TxHash:        builtTransferOwnershipTx.Hash().Hex(),
-		Nonce:         transferOwnershipNonce,
+		TrackingID:     job.Args.TrackingID,
+		OTXType:        store.TRANSFER_OWNERSHIP,
+		SignerAccount:  systemAddress,
+		RawTx:          rawTransferOwnershipTxHex,
+		TxHash:         builtTransferOwnershipTx.Hash().Hex(),
+		Nonce:          transferOwnershipNonce,
+		GasLimit:       transferOwnershipGasLimit.Limit,
+		GasLimitSource: transferOwnershipGasLimit.Source,
 	})
 	if err != nil {
 		return err
@@ -299,9 +317,10 @@ func (w *DemurrageTokenDeployWorker) Work(ctx context.Context, job *river.Job[De
 		},
 		{
 			Args: DispatchArgs{
-				TrackingID: job.Args.TrackingID,
-				OTXID:      addOTXID,
-				RawTx:      rawAddTxHex,
+				TrackingID:       job.Args.TrackingID,
+				OTXID:            addOTXID,
+				RawTx:            rawAddTxHex,
+				PredecessorOTXID: deployContractOTXID,
 			},
 			InsertOpts: &river.InsertOpts{
 				Priority: 2,
@@ -309,9 +328,10 @@ func (w *DemurrageTokenDeployWorker) Work(ctx context.Context, job *river.Job[De
 		},
 		{
 			Args: DispatchArgs{
-				TrackingID: job.Args.TrackingID,
-				OTXID:      mintToOTXID,
-				RawTx:      rawMintToTxHex,
+				TrackingID:       job.Args.TrackingID,
+				OTXID:            mintToOTXID,
+				RawTx:            rawMintToTxHex,
+				PredecessorOTXID: addOTXID,
 			},
 			InsertOpts: &river.InsertOpts{
 				Priority: 3,
@@ -319,9 +339,10 @@ func (w *DemurrageTokenDeployWorker) Work(ctx context.Context, job *river.Job[De
 		},
 		{
 			Args: DispatchArgs{
-				TrackingID: job.Args.TrackingID,
-				OTXID:      transferOwnershipOTXID,
-				RawTx:      rawTransferOwnershipTxHex,
+				TrackingID:       job.Args.TrackingID,
+				OTXID:            transferOwnershipOTXID,
+				RawTx:            rawTransferOwnershipTxHex,
+				PredecessorOTXID: mintToOTXID,
 			},
 			InsertOpts: &river.InsertOpts{
 				Priority: 4,
@@ -2,15 +2,14 @@ package worker
 
 import (
 	"context"
-	"errors"
+	"fmt"
+	"time"
 
-	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
-	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/grassrootseconomics/eth-custodial/internal/rpcerror"
 	"github.com/grassrootseconomics/eth-custodial/internal/store"
 	"github.com/grassrootseconomics/eth-custodial/pkg/event"
-	"github.com/lmittmann/w3"
-	"github.com/lmittmann/w3/module/eth"
+	"github.com/jackc/pgx/v5"
 	"github.com/riverqueue/river"
 )
 
@@ -19,6 +18,11 @@ type (
 		TrackingID string `json:"trackingId"`
 		OTXID      uint64 `json:"otxId"`
 		RawTx      string `json:"rawTx"`
+		// RetryAttempt counts the retrier remediations that led to this dispatch.
+		RetryAttempt int `json:"retryAttempt,omitempty"`
+		// PredecessorOTXID is the previous step of a multi transaction flow. This OTX is only dispatched once the
+		// predecessor is in the network.
+		PredecessorOTXID uint64 `json:"predecessorOtxId,omitempty"`
 	}
 
 	DisptachWorker struct {
@@ -27,7 +31,17 @@ type (
 	}
 )
 
-const DispatchID = "DISPATCH"
+const (
+	DispatchID = "DISPATCH"
+
+	predecessorSnooze = 2 * time.Second
+	// predecessorRemediationSnooze paces steps waiting on a predecessor that the retrier is remediating, its first
+	// attempt runs after retrierBaseBackoff.
+	predecessorRemediationSnooze = 30 * time.Second
+	// predecessorMaxWait bounds how long a step waits on a predecessor that is never dispatched, e.g. its dispatch
+	// job exhausted its attempts on network errors.
+	predecessorMaxWait = 30 * time.Minute
+)
 
 func (DispatchArgs) Kind() string { return DispatchID }
 
@@ -38,33 +52,44 @@ func (w *DisptachWorker) Work(ctx context.Context, job *river.Job[DispatchArgs])
 	}
 	defer tx.Rollback(ctx)
 
+	// A replaced OTX or one that already settled, e.g. mined after a speed-up or aborted with its flow, must not be
+	// broadcast again or have its status overwritten.
+	state, err := w.wc.store.GetDispatchState(ctx, tx, job.Args.OTXID)
+	if err != nil {
+		return err
+	}
+	if state.Replaced || store.FinalDispatchStatus(state.Status) {
+		return river.JobCancel(fmt.Errorf("dispatch: otx %d is %s, replaced: %t", job.Args.OTXID, state.Status, state.Replaced))
+	}
+
+	if job.Args.PredecessorOTXID > 0 {
+		if err := w.awaitPredecessor(ctx, tx, job); err != nil {
+			return err
+		}
+	}
+
 	rawTx, err := hexutil.Decode(job.Args.RawTx)
 	if err != nil {
 		return err
 	}
 
+	endpoint, sendErr := w.wc.broadcast(ctx, rawTx)
+	w.wc.recordDispatchAttempt(ctx, tx, job.Args.OTXID, attemptSourceDispatch, endpoint, rawTx, sendErr)
+
 	updateTxStatus := store.DispatchTx{
 		OTXID:  job.Args.OTXID,
-		Status: store.UNKNOWN_RPC_ERROR,
-	}
-	if err := w.sendRawTx(ctx, rawTx); err != nil {
-		dispatchErr, ok := err.(*DispatchError)
-		if ok {
-			if dispatchErr.Err == ErrNetwork {
-				updateTxStatus.Status = store.NETWORK_ERROR
-				w.wc.logg.Error("network related dispatch error", "original_error", dispatchErr.OriginalErr)
+		Status: store.IN_NETWORK,
+	}
+	if sendErr != nil {
+		dispatchErr, ok := sendErr.(*DispatchError)
+		if ok && dispatchErr.Class.Action == rpcerror.ActionInNetwork {
+			w.wc.logg.Debug("dispatch: node already has the transaction", "otx_id", job.Args.OTXID, "status", dispatchErr.Class.Status)
+		} else if ok {
+			updateTxStatus.Status = dispatchErr.Class.Status
+			if dispatchErr.Class.Action == rpcerror.ActionRetry {
+				w.wc.logg.Error("transient dispatch error", "status", dispatchErr.Class.Status, "original_error", dispatchErr.OriginalErr)
 			} else {
-				w.wc.logg.Error("chain related dispatch error", "error", dispatchErr.Err, "original_error", dispatchErr.OriginalErr)
-				switch dispatchErr.Err {
-				case ErrGasPriceTooLow:
-					updateTxStatus.Status = store.LOW_GAS_PRICE
-				case ErrInsufficientGas:
-					updateTxStatus.Status = store.NO_GAS
-				case ErrNonceTooLow:
-					updateTxStatus.Status = store.LOW_NONCE
-				case ErrReplacementTxUnderpriced:
-					updateTxStatus.Status = store.REPLACEMENT_UNDERPRICED
-				}
+				w.wc.logg.Error("chain related dispatch error", "status", dispatchErr.Class.Status, "original_error", dispatchErr.OriginalErr)
 			}
 
 			if err := w.wc.store.UpdateDispatchTxStatus(ctx, tx, updateTxStatus); err != nil {
@@ -75,6 +100,13 @@ func (w *DisptachWorker) Work(ctx context.Context, job *river.Job[DispatchArgs])
 				Status:     updateTxStatus.Status,
 			})
 
+			// Steps after a remediable error wait for the retrier, which aborts them if it gives up.
+			if dispatchErr.Class.Action == rpcerror.ActionFail {
+				if err := w.wc.abortSuccessors(ctx, tx, job.Args.TrackingID, job.Args.OTXID); err != nil {
+					return err
+				}
+			}
+
 			// Commit the status update before cancelling the job or returning an error.
 			// Without this, the deferred tx.Rollback() discards the status change,
 			// leaving the dispatch stuck in PENDING with no recovery path.
@@ -82,29 +114,34 @@ func (w *DisptachWorker) Work(ctx context.Context, job *river.Job[DispatchArgs])
 				return err
 			}
 
-			if dispatchErr.Err == ErrNetwork {
-				// Network errors are transient, so we can keep retrying up to the limit
+			switch dispatchErr.Class.Action {
+			case rpcerror.ActionRetry:
+				// Network errors and a full pool are transient, so we can keep retrying up to the limit
 				return dispatchErr
+			case rpcerror.ActionFail:
+				// Nothing the retrier can do, the OTX has to be cancelled or re-signed
+				return river.JobCancel(dispatchErr)
 			}
 
-			_, err := w.wc.queueClient.Insert(ctx, RetrierArgs{
+			if err := w.wc.scheduleRetrier(ctx, RetrierArgs{
 				TrackingID: job.Args.TrackingID,
-			}, &river.InsertOpts{
-				// TODO: Prevent cascading failures
-				MaxAttempts: 1,
-			})
-			if err != nil {
+				OTXID:      job.Args.OTXID,
+				Attempt:    job.Args.RetryAttempt,
+			}, updateTxStatus.Status); err != nil {
 				return err
 			}
 
 			// Retry attempt has been deffered to retrier, permanantly cancel this job
 			return river.JobCancel(dispatchErr)
+		} else {
+			w.wc.logg.Error("unknown dispatch error", "error", sendErr)
+			// Keep the recorded attempt, the job is retried.
+			if err := tx.Commit(ctx); err != nil {
+				return err
+			}
+			return sendErr
 		}
-
-		w.wc.logg.Error("unknown dispatch error", "error", err)
-		return err
 	}
-	updateTxStatus.Status = store.IN_NETWORK
 
 	if err := w.wc.store.UpdateDispatchTxStatus(ctx, tx, updateTxStatus); err != nil {
 		return err
@@ -117,24 +154,71 @@ func (w *DisptachWorker) Work(ctx context.Context, job *river.Job[DispatchArgs])
 	return tx.Commit(ctx)
 }
 
-func (w *DisptachWorker) sendRawTx(ctx context.Context, rawTx []byte) error {
-	var txHash common.Hash
-	sendRawTxCall := eth.SendRawTx(rawTx).Returns(&txHash)
-
-	var errs w3.CallErrors
-	if err := w.wc.chainProvider.Client.CallCtx(ctx, sendRawTxCall); errors.As(err, &errs) {
-		jsonErr, ok := errs[0].(rpc.Error)
-		if ok {
-			if jsonRPCError := handleJSONRPCError(jsonErr.Error()); jsonRPCError != nil {
-				return &DispatchError{
-					Err:         jsonRPCError,
-					OriginalErr: jsonErr,
-				}
-			}
+// awaitPredecessor returns nil once the predecessor reached the network. While it is still being dispatched or remediated
+// by the retrier the job is snoozed, if it failed or was mined but reverted this OTX and the rest of the flow are
+// aborted and the job is cancelled.
+func (w *DisptachWorker) awaitPredecessor(ctx context.Context, tx pgx.Tx, job *river.Job[DispatchArgs]) error {
+	dependency, err := w.wc.store.GetDispatchDependency(ctx, tx, job.Args.OTXID, job.Args.PredecessorOTXID)
+	if err != nil {
+		return err
+	}
+
+	if dependency.PredecessorStatus == nil {
+		return fmt.Errorf("dispatch: predecessor otx %d not found", job.Args.PredecessorOTXID)
+	}
+
+	switch *dependency.PredecessorStatus {
+	case store.IN_NETWORK, store.SUCCESS, store.CONFIRMED, store.REPLACED, store.EXTERNAL_DISPATCH:
+		return nil
+	case store.PENDING, store.NETWORK_ERROR, store.TXPOOL_FULL:
+		if time.Since(job.CreatedAt) < predecessorMaxWait {
+			return river.JobSnooze(predecessorSnooze)
 		}
-	} else if err != nil {
-		return handleNetworkError(err)
+	default:
+		// The retrier aborts this OTX once it gives up on the predecessor.
+		switch rpcerror.StatusAction(*dependency.PredecessorStatus) {
+		case rpcerror.ActionBumpFees, rpcerror.ActionRefillGas, rpcerror.ActionReconcileNonce:
+			return river.JobSnooze(predecessorRemediationSnooze)
+		}
+	}
+
+	w.wc.logg.Warn("dispatch: predecessor failed, aborting", "otx_id", job.Args.OTXID, "predecessor_otx_id", job.Args.PredecessorOTXID, "predecessor_status", *dependency.PredecessorStatus)
+	if err := w.wc.store.UpdateDispatchTxStatus(ctx, tx, store.DispatchTx{
+		OTXID:  job.Args.OTXID,
+		Status: store.ABORTED,
+	}); err != nil {
+		return err
+	}
+	w.wc.pub.Send(ctx, event.Event{
+		TrackingID: job.Args.TrackingID,
+		Status:     store.ABORTED,
+	})
+	if err := w.wc.abortSuccessors(ctx, tx, job.Args.TrackingID, job.Args.OTXID); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
 	}
 
+	return river.JobCancel(fmt.Errorf("dispatch: predecessor otx %d is %s", job.Args.PredecessorOTXID, *dependency.PredecessorStatus))
+}
+
+// abortSuccessors halts the remaining steps of a flow after otxID failed. Their nonces are left to the nonce gap check.
+func (wc *WorkerContainer) abortSuccessors(ctx context.Context, tx pgx.Tx, trackingID string, otxID uint64) error {
+	aborted, err := wc.store.AbortSuccessors(ctx, tx, otxID)
+	if err != nil {
+		return err
+	}
+
+	if len(aborted) < 1 {
+		return nil
+	}
+
+	wc.logg.Warn("dispatch: aborted remaining steps", "tracking_id", trackingID, "otx_id", otxID, "aborted", aborted)
+	wc.pub.Send(ctx, event.Event{
+		TrackingID: trackingID,
+		Status:     store.ABORTED,
+	})
+
 	return nil
 }
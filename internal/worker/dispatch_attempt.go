@@ -0,0 +1,76 @@
+package worker
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/grassrootseconomics/eth-custodial/internal/store"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	attemptSourceDispatch = "DISPATCH"
+	attemptSourceUnlocker = "UNLOCKER"
+)
+
+// attemptStatus is the outcome of a single broadcast. Unlike the dispatch status it keeps ALREADY_KNOWN.
+func attemptStatus(sendErr error) string {
+	if sendErr == nil {
+		return store.IN_NETWORK
+	}
+
+	var dispatchErr *DispatchError
+	if !errors.As(sendErr, &dispatchErr) {
+		return store.UNKNOWN_RPC_ERROR
+	}
+
+	return dispatchErr.Class.Status
+}
+
+// recordDispatchAttempt appends a broadcast of rawTx to the dispatch history within the caller's transaction, callers
+// commit it on every outcome of the broadcast. The insert runs in a savepoint, failing to record is logged and never
+// fails the dispatch.
+func (wc *WorkerContainer) recordDispatchAttempt(ctx context.Context, tx pgx.Tx, otxID uint64, source string, endpoint string, rawTx []byte, sendErr error) {
+	signedTx := new(types.Transaction)
+	if err := signedTx.UnmarshalBinary(rawTx); err != nil {
+		wc.logg.Error("could not decode dispatch attempt", "otx_id", otxID, "error", err)
+		return
+	}
+
+	dispatchAttempt := store.DispatchAttempt{
+		OTXID:       otxID,
+		Source:      source,
+		TxHash:      signedTx.Hash().Hex(),
+		RawTx:       hexutil.Encode(rawTx),
+		GasFeeCap:   signedTx.GasFeeCap().String(),
+		GasTipCap:   signedTx.GasTipCap().String(),
+		RPCEndpoint: endpoint,
+		Status:      attemptStatus(sendErr),
+	}
+	if sendErr != nil {
+		var dispatchErr *DispatchError
+		if errors.As(sendErr, &dispatchErr) && dispatchErr.OriginalErr != nil {
+			dispatchAttempt.RPCError = dispatchErr.OriginalErr.Error()
+		} else {
+			dispatchAttempt.RPCError = sendErr.Error()
+		}
+	}
+
+	savepoint, err := tx.Begin(ctx)
+	if err != nil {
+		wc.logg.Error("could not record dispatch attempt", "otx_id", otxID, "error", err)
+		return
+	}
+	defer savepoint.Rollback(ctx)
+
+	if err := wc.store.InsertDispatchAttempt(ctx, savepoint, dispatchAttempt); err != nil {
+		wc.logg.Error("could not record dispatch attempt", "otx_id", otxID, "error", err)
+		return
+	}
+
+	if err := savepoint.Commit(ctx); err != nil {
+		wc.logg.Error("could not record dispatch attempt", "otx_id", otxID, "error", err)
+	}
+}
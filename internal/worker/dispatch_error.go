@@ -4,58 +4,53 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"net"
-	"net/url"
-	"strings"
-)
 
-var (
-	ErrInsufficientGas          = errors.New("eth-custodial: insufficient gas")
-	ErrGasPriceTooLow           = errors.New("eth-custodial: gas price too low")
-	ErrNonceTooLow              = errors.New("eth-custodial: nonce too low")
-	ErrReplacementTxUnderpriced = errors.New("eth-custodial: replacement tx underpriced")
-	ErrNetwork                  = errors.New("eth-custodial: network related error")
+	"github.com/grassrootseconomics/eth-custodial/internal/rpcerror"
+	"github.com/lmittmann/w3"
 )
 
+// DispatchError is a broadcast the node rejected or that never reached a node.
 type DispatchError struct {
-	Err         error
+	Class       rpcerror.Class
 	OriginalErr error
 }
 
 func (e *DispatchError) Error() string {
-	return fmt.Sprintf("%v (original rpc error: %v)", e.Err, e.OriginalErr)
+	return fmt.Sprintf("eth-custodial: dispatch %s (original rpc error: %v)", e.Class.Status, e.OriginalErr)
 }
 
 func (e *DispatchError) Unwrap() error {
-	return e.Err
+	return e.OriginalErr
 }
 
-func handleJSONRPCError(errMsg string) error {
-	switch {
-	case strings.Contains(errMsg, "insufficient funds for gas"):
-		return ErrInsufficientGas
-	case strings.Contains(errMsg, "replacement transaction underpriced"):
-		return ErrReplacementTxUnderpriced
-	case strings.Contains(errMsg, "transaction underpriced"), strings.Contains(errMsg, "gas fee cap is below the minimum base fee"):
-		return ErrGasPriceTooLow
-	case strings.Contains(errMsg, "nonce too low"):
-		return ErrNonceTooLow
-	default:
-		return nil
-	}
-}
+// broadcast sends rawTx to every healthy node. Rejections and network errors are returned as a classified
+// DispatchError together with the endpoint the result came from.
+func (wc *WorkerContainer) broadcast(ctx context.Context, rawTx []byte) (string, error) {
+	var callErrs w3.CallErrors
 
-func handleNetworkError(err error) error {
-	if err == nil {
-		return nil
+	endpoint, err := wc.rpc.Broadcast(ctx, rawTx)
+	if errors.As(err, &callErrs) {
+		return endpoint, &DispatchError{
+			Class:       wc.rpcErrors.Classify(callErrs[0].Error()),
+			OriginalErr: callErrs[0],
+		}
+	} else if err != nil {
+		return endpoint, handleNetworkError(err)
 	}
 
-	var netErr net.Error
-	var urlErr *url.Error
+	return endpoint, nil
+}
+
+// inNetwork reports whether a broadcast left the transaction with the node, either accepted or already known.
+func inNetwork(err error) bool {
+	var dispatchErr *DispatchError
+	return err == nil || errors.As(err, &dispatchErr) && dispatchErr.Class.Action == rpcerror.ActionInNetwork
+}
 
-	if errors.As(err, &netErr) || errors.As(err, &urlErr) || strings.Contains(err.Error(), "timeout") || errors.Is(err, context.DeadlineExceeded) {
+func handleNetworkError(err error) error {
+	if rpcerror.IsNetworkError(err) {
 		return &DispatchError{
-			Err:         ErrNetwork,
+			Class:       rpcerror.Network,
 			OriginalErr: err,
 		}
 	}
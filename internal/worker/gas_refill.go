@@ -7,7 +7,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
-	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/grassrootseconomics/eth-custodial/internal/gas"
 	"github.com/grassrootseconomics/eth-custodial/internal/store"
 	"github.com/grassrootseconomics/eth-custodial/pkg/event"
 	"github.com/grassrootseconomics/ethutils"
@@ -44,7 +44,7 @@ func (w *GasRefillWorker) Work(ctx context.Context, job *river.Job[GasRefillArgs
 		checkStatus bool
 	)
 
-	if err := w.wc.chainProvider.Client.CallCtx(
+	if err := w.wc.rpc.CallCtx(
 		ctx,
 		eth.CallFunc(
 			w.gasFaucet,
@@ -60,7 +60,7 @@ func (w *GasRefillWorker) Work(ctx context.Context, job *river.Job[GasRefillArgs
 		return nil
 	}
 
-	if err := w.wc.chainProvider.Client.CallCtx(
+	if err := w.wc.rpc.CallCtx(
 		ctx,
 		eth.CallFunc(
 			w.gasFaucet,
@@ -76,37 +76,37 @@ func (w *GasRefillWorker) Work(ctx context.Context, job *river.Job[GasRefillArgs
 		return nil
 	}
 
-	systemKeypair, err := w.wc.store.LoadMasterSignerKey(ctx, tx)
+	systemAddress, err := w.wc.assignSystemSigner(ctx, tx)
 	if err != nil {
 		return err
 	}
 
-	privateKey, err := crypto.HexToECDSA(systemKeypair.Private)
+	nonce, err := w.wc.store.AcquireNonce(ctx, tx, systemAddress)
 	if err != nil {
 		return err
 	}
 
-	nonce, err := w.wc.store.AcquireNonce(ctx, tx, systemKeypair.Public)
+	input, err := Abi[GiveTo].EncodeArgs(w3.A(job.Args.Address))
 	if err != nil {
 		return err
 	}
 
-	input, err := Abi[GiveTo].EncodeArgs(w3.A(job.Args.Address))
+	gasSettings, err := w.wc.gasOracle.GetSettings(gas.DefaultFeeClass(store.GAS_REFILL))
 	if err != nil {
 		return err
 	}
 
-	gasSettings, err := w.wc.gasOracle.GetSettings()
+	gasLimit, err := w.wc.gasLimitEstimator.Estimate(ctx, store.GAS_REFILL, ethutils.HexToAddress(systemAddress), w.gasFaucet, input, nil)
 	if err != nil {
 		return err
 	}
 
-	builtTx, err := w.wc.chainProvider.SignContractExecutionTx(privateKey, ethutils.ContractExecutionTxOpts{
+	builtTx, err := w.wc.signContractExecutionTx(ctx, tx, systemAddress, ethutils.ContractExecutionTxOpts{
 		ContractAddress: w.gasFaucet,
-		InputData:       addDivviRefferalTag(w.wc.chainProvider, input, ethutils.HexToAddress(systemKeypair.Public)),
+		InputData:       addDivviRefferalTag(w.wc.chainProvider, input, ethutils.HexToAddress(systemAddress)),
 		GasFeeCap:       gasSettings.GasFeeCap,
 		GasTipCap:       gasSettings.GasTipCap,
-		GasLimit:        gasSettings.GasLimit,
+		GasLimit:        gasLimit.Limit,
 		Nonce:           nonce,
 	})
 	if err != nil {
@@ -121,12 +121,14 @@ func (w *GasRefillWorker) Work(ctx context.Context, job *river.Job[GasRefillArgs
 	rawTxHex := hexutil.Encode(rawTx)
 
 	otxID, err := w.wc.store.InsertOTX(ctx, tx, store.OTX{
-		TrackingID:    job.Args.TrackingID,
-		OTXType:       store.GAS_REFILL,
-		SignerAccount: systemKeypair.Public,
-		RawTx:         rawTxHex,
-		TxHash:        builtTx.Hash().Hex(),
-		Nonce:         nonce,
+		TrackingID:     job.Args.TrackingID,
+		OTXType:        store.GAS_REFILL,
+		SignerAccount:  systemAddress,
+		RawTx:          rawTxHex,
+		TxHash:         builtTx.Hash().Hex(),
+		Nonce:          nonce,
+		GasLimit:       gasLimit.Limit,
+		GasLimitSource: gasLimit.Source,
 	})
 	if err != nil {
 		return err
@@ -6,7 +6,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/grassrootseconomics/eth-custodial/internal/gas"
 	"github.com/grassrootseconomics/eth-custodial/internal/store"
 	"github.com/grassrootseconomics/eth-custodial/pkg/event"
 	"github.com/grassrootseconomics/ethutils"
@@ -37,13 +37,19 @@ func (w *GenericSignWorker) Work(ctx context.Context, job *river.Job[GenericSign
 	}
 	defer tx.Rollback(ctx)
 
-	keypair, err := w.wc.store.LoadPrivateKey(ctx, tx, job.Args.From)
+	value, err := StringToBigInt(job.Args.Value, false)
 	if err != nil {
 		return err
 	}
 
-	privateKey, err := crypto.HexToECDSA(keypair.Private)
-	if err != nil {
+	to := ethutils.HexToAddress(job.Args.To)
+	data := common.FromHex(job.Args.Data)
+
+	if err := w.wc.preflight(ctx, tx, job.Args.TrackingID, store.GENERIC_SIGN, job.Args.From, simulationCall{
+		To:    to,
+		Input: data,
+		Value: value,
+	}); err != nil {
 		return err
 	}
 
@@ -52,24 +58,22 @@ func (w *GenericSignWorker) Work(ctx context.Context, job *river.Job[GenericSign
 		return err
 	}
 
-	value, err := StringToBigInt(job.Args.Value, false)
+	gasSettings, err := w.wc.gasOracle.GetSettings(gas.DefaultFeeClass(store.GENERIC_SIGN))
 	if err != nil {
 		return err
 	}
 
-	to := ethutils.HexToAddress(job.Args.To)
-
-	gasSettings, err := w.wc.gasOracle.GetSettings()
+	gasLimit, err := w.wc.gasLimitEstimator.Estimate(ctx, store.GENERIC_SIGN, ethutils.HexToAddress(job.Args.From), to, data, value)
 	if err != nil {
 		return err
 	}
 
-	builtTx, err := types.SignNewTx(privateKey, w.wc.chainProvider.Signer, &types.DynamicFeeTx{
+	builtTx, err := w.wc.signTx(ctx, tx, job.Args.From, &types.DynamicFeeTx{
 		Value:     value,
 		To:        &to,
 		Nonce:     nonce,
-		Data:      common.FromHex(job.Args.Data),
-		Gas:       gasSettings.GasLimit,
+		Data:      data,
+		Gas:       gasLimit.Limit,
 		GasFeeCap: gasSettings.GasFeeCap,
 		GasTipCap: gasSettings.GasTipCap,
 	})
@@ -85,12 +89,14 @@ func (w *GenericSignWorker) Work(ctx context.Context, job *river.Job[GenericSign
 	rawTxHex := hexutil.Encode(rawTx)
 
 	otxID, err := w.wc.store.InsertOTX(ctx, tx, store.OTX{
-		TrackingID:    job.Args.TrackingID,
-		OTXType:       store.GENERIC_SIGN,
-		SignerAccount: job.Args.From,
-		RawTx:         rawTxHex,
-		TxHash:        builtTx.Hash().Hex(),
-		Nonce:         nonce,
+		TrackingID:     job.Args.TrackingID,
+		OTXType:        store.GENERIC_SIGN,
+		SignerAccount:  job.Args.From,
+		RawTx:          rawTxHex,
+		TxHash:         builtTx.Hash().Hex(),
+		Nonce:          nonce,
+		GasLimit:       gasLimit.Limit,
+		GasLimitSource: gasLimit.Source,
 	})
 	if err != nil {
 		return err
@@ -7,8 +7,10 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/grassrootseconomics/eth-custodial/internal/multirpc"
 	"github.com/grassrootseconomics/eth-custodial/internal/store"
 	custodialEvent "github.com/grassrootseconomics/eth-custodial/pkg/event"
+	"github.com/jackc/pgx/v5"
 	"github.com/lmittmann/w3"
 	"github.com/lmittmann/w3/module/eth"
 	"github.com/lmittmann/w3/w3types"
@@ -24,75 +26,189 @@ type (
 	}
 )
 
-const DispatchHealthCheckID = "DISPATCH_HEALTHCHECK"
+const (
+	DispatchHealthCheckID = "DISPATCH_HEALTHCHECK"
+
+	healthCheckPageSize = 100
+	// droppedTxMaxAge is how long a transaction unknown to the node is rebroadcast as is. Past it, the nonce sequence of
+	// the account is left to the unlocker which can re-sign.
+	droppedTxMaxAge = 30 * time.Minute
+)
 
 func (DispatchHealthCheckArgs) Kind() string { return DispatchHealthCheckID }
 
+// Work pages through every OTX that isn't mined yet. Mined ones are marked SUCCESS or REVERTED from their receipt,
+// ones the node has dropped are rebroadcast.
 func (w *DispatchHealthCheckWorker) Work(ctx context.Context, _ *river.Job[DispatchHealthCheckArgs]) error {
+	var cursor uint64
+
+	for {
+		otx, err := w.checkPage(ctx, cursor)
+		if err != nil {
+			return err
+		}
+
+		if len(otx) < healthCheckPageSize {
+			return nil
+		}
+		cursor = otx[len(otx)-1].ID
+	}
+}
+
+func (w *DispatchHealthCheckWorker) checkPage(ctx context.Context, cursor uint64) ([]*store.OTX, error) {
 	tx, err := w.wc.store.Pool().Begin(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer tx.Rollback(ctx)
 
-	otx, err := w.wc.store.GetFailedOTX(ctx, tx)
+	otx, err := w.wc.store.GetNonFinalOTX(ctx, tx, cursor, healthCheckPageSize)
 	if err != nil {
-		return err
-	}
-
-	if len(otx) < 1 {
-		return nil
+		return nil, err
 	}
 
 	var txsToCheck []*store.OTX
 	for _, v := range otx {
-		if v.DispatchStatus == store.IN_NETWORK && time.Since(v.UpdatedAt) > time.Minute {
+		// Give the dispatch and the tracker a chance to update the status first
+		if time.Since(v.UpdatedAt) > time.Minute {
 			txsToCheck = append(txsToCheck, v)
 		}
 	}
 
-	if len(txsToCheck) > 0 {
-		calls := make([]w3types.RPCCaller, len(txsToCheck))
-		receipts := make([]*types.Receipt, len(txsToCheck))
+	if len(txsToCheck) < 1 {
+		return otx, tx.Commit(ctx)
+	}
 
-		for i, v := range txsToCheck {
-			calls[i] = eth.TxReceipt(common.HexToHash(v.TxHash)).Returns(&receipts[i])
-		}
-		w.wc.logg.Debug("calls size", "size", len(calls))
-
-		var batchErr w3.CallErrors
-		if err := w.wc.chainProvider.Client.CallCtx(ctx, calls...); errors.As(err, &batchErr) {
-			for i, e := range batchErr {
-				if e != nil {
-					w.wc.logg.Warn("failed to fetch transaction receipts in batch", "call_pos", i, "error", e)
-				}
+	receipts, receiptErrs, err := w.fetchReceipts(ctx, txsToCheck)
+	if err != nil {
+		return nil, err
+	}
+
+	var notMined []*store.OTX
+	for i, v := range txsToCheck {
+		switch {
+		case receiptErrs[i] == nil && receipts[i] != nil && receipts[i].BlockNumber != nil:
+			if err := w.markMined(ctx, tx, v, receipts[i]); err != nil {
+				return nil, err
+			}
+		case isNotFound(receiptErrs[i]):
+			// Replaced OTXs are superseded at their nonce, only the effective one is rebroadcast
+			if !v.Replaced && v.DispatchStatus == store.IN_NETWORK {
+				notMined = append(notMined, v)
 			}
+		default:
+			w.wc.logg.Warn("health check: failed to fetch transaction receipt", "otx_id", v.ID, "error", receiptErrs[i])
 		}
-		for i, v := range receipts {
-			if v != nil && v.BlockNumber != nil {
-				if v.Status == 1 {
-					updateDispatchStatus := store.DispatchTx{
-						OTXID:  txsToCheck[i].ID,
-						Status: store.SUCCESS,
-					}
-					if err := w.wc.store.UpdateDispatchTxStatus(ctx, tx, updateDispatchStatus); err != nil {
-						return err
-					}
-					w.wc.pub.Send(ctx, custodialEvent.Event{
-						TrackingID: txsToCheck[i].TrackingID,
-						Status:     updateDispatchStatus.Status,
-					})
-
-					w.wc.logg.Debug("health check manually updated otx status to SUCCESS",
-						"otx_id", txsToCheck[i].ID,
-						"tx_hash", v.TxHash.Hex(),
-						"block_number", v.BlockNumber,
-					)
-				}
+	}
 
-			}
+	if len(notMined) > 0 {
+		if err := w.rebroadcastDropped(ctx, tx, notMined); err != nil {
+			return nil, err
+		}
+	}
+
+	return otx, tx.Commit(ctx)
+}
+
+func (w *DispatchHealthCheckWorker) fetchReceipts(ctx context.Context, otx []*store.OTX) ([]*multirpc.Receipt, w3.CallErrors, error) {
+	calls := make([]w3types.RPCCaller, len(otx))
+	receipts := make([]*multirpc.Receipt, len(otx))
+
+	for i, v := range otx {
+		calls[i] = multirpc.TxReceipt(common.HexToHash(v.TxHash)).Returns(&receipts[i])
+	}
+
+	callErrs := make(w3.CallErrors, len(calls))
+	if err := w.wc.rpc.CallCtx(ctx, calls...); err != nil && !errors.As(err, &callErrs) {
+		return nil, nil, err
+	}
+
+	return receipts, callErrs, nil
+}
+
+func (w *DispatchHealthCheckWorker) markMined(ctx context.Context, tx pgx.Tx, otx *store.OTX, receipt *multirpc.Receipt) error {
+	blockNumber := receipt.BlockNumber.Uint64()
+	blockHash := receipt.BlockHash.Hex()
+	updateDispatchStatus := store.DispatchTx{
+		OTXID:       otx.ID,
+		Status:      store.SUCCESS,
+		BlockNumber: &blockNumber,
+		BlockHash:   &blockHash,
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		updateDispatchStatus.Status = store.REVERTED
+	}
+
+	if err := w.wc.store.UpdateDispatchTxReceipt(ctx, tx, updateDispatchStatus); err != nil {
+		return err
+	}
+	if err := w.wc.storeReceipt(ctx, tx, otx.ID, receipt); err != nil {
+		return err
+	}
+	w.wc.pub.Send(ctx, custodialEvent.Event{
+		TrackingID: otx.TrackingID,
+		Status:     updateDispatchStatus.Status,
+	})
+	if err := w.wc.resolveReplacements(ctx, tx, otx.ID); err != nil {
+		return err
+	}
+
+	w.wc.logg.Debug("health check manually updated otx status",
+		"otx_id", otx.ID,
+		"status", updateDispatchStatus.Status,
+		"tx_hash", receipt.TxHash.Hex(),
+		"block_number", receipt.BlockNumber,
+	)
+
+	return nil
+}
+
+// rebroadcastDropped re-dispatches the stored raw tx of OTXs that are neither mined nor known to the node anymore,
+// e.g. evicted from the mempool. Past droppedTxMaxAge the unlocker is run instead.
+func (w *DispatchHealthCheckWorker) rebroadcastDropped(ctx context.Context, tx pgx.Tx, otx []*store.OTX) error {
+	calls := make([]w3types.RPCCaller, len(otx))
+	pendingTxs := make([]*types.Transaction, len(otx))
+
+	for i, v := range otx {
+		calls[i] = eth.Tx(common.HexToHash(v.TxHash)).Returns(&pendingTxs[i])
+	}
+
+	callErrs := make(w3.CallErrors, len(calls))
+	if err := w.wc.rpc.CallCtx(ctx, calls...); err != nil && !errors.As(err, &callErrs) {
+		return err
+	}
+
+	var escalate bool
+	for i, v := range otx {
+		if !isNotFound(callErrs[i]) {
+			continue
+		}
+
+		if time.Since(v.CreatedAt) > droppedTxMaxAge {
+			w.wc.logg.Warn("health check: dropped transaction past max age, escalating to unlocker", "otx_id", v.ID, "tx_hash", v.TxHash)
+			escalate = true
+			continue
+		}
+
+		w.wc.logg.Info("health check: rebroadcasting dropped transaction", "otx_id", v.ID, "tx_hash", v.TxHash)
+		if _, err := w.wc.queueClient.InsertTx(ctx, tx, DispatchArgs{
+			TrackingID: v.TrackingID,
+			OTXID:      v.ID,
+			RawTx:      v.RawTx,
+		}, nil); err != nil {
+			return err
+		}
+	}
+
+	if escalate {
+		if _, err := w.wc.queueClient.InsertTx(ctx, tx, UnlockerArgs{}, &river.InsertOpts{
+			UniqueOpts: river.UniqueOpts{
+				ByArgs: true,
+			},
+		}); err != nil {
+			return err
 		}
 	}
 
-	return tx.Commit(ctx)
+	return nil
 }
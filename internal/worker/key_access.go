@@ -0,0 +1,110 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/grassrootseconomics/eth-custodial/internal/store"
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+)
+
+type (
+	KeyAccessSealArgs struct{}
+
+	KeyAccessSealWorker struct {
+		river.WorkerDefaults[KeyAccessSealArgs]
+		wc *WorkerContainer
+	}
+)
+
+const (
+	KeyAccessSealID = "KEY_ACCESS_SEAL"
+
+	keyAccessSealBatchSize = 1000
+)
+
+func (KeyAccessSealArgs) Kind() string { return KeyAccessSealID }
+
+func (w *KeyAccessSealWorker) Work(ctx context.Context, _ *river.Job[KeyAccessSealArgs]) error {
+	for {
+		sealed, err := w.sealBatch(ctx)
+		if err != nil {
+			return err
+		}
+
+		if sealed > 0 {
+			w.wc.logg.Debug("sealed key access log batch", "count", sealed)
+		}
+		if sealed < keyAccessSealBatchSize {
+			return nil
+		}
+	}
+}
+
+func (w *KeyAccessSealWorker) sealBatch(ctx context.Context) (int, error) {
+	tx, err := w.wc.store.Pool().Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	sealed, err := w.wc.store.SealKeyAccessLog(ctx, tx, keyAccessSealBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	return sealed, tx.Commit(ctx)
+}
+
+// keyAccessMiddleware attributes private key loads and OTXs within a job to its kind, tracking ID and the JWT subject
+// the job originates from.
+func keyAccessMiddleware() rivertype.Middleware {
+	return river.WorkerMiddlewareFunc(func(ctx context.Context, job *rivertype.JobRow, doInner func(context.Context) error) error {
+		var args struct {
+			TrackingID string `json:"trackingId"`
+		}
+		// Not all jobs carry a tracking ID.
+		_ = json.Unmarshal(job.EncodedArgs, &args)
+
+		var metadata struct {
+			Subject string `json:"subject"`
+		}
+		_ = json.Unmarshal(job.Metadata, &metadata)
+
+		return doInner(store.WithKeyAccess(ctx, store.KeyAccess{
+			Caller:     job.Kind,
+			TrackingID: args.TrackingID,
+			Subject:    metadata.Subject,
+		}))
+	})
+}
+
+// subjectInsertMiddleware carries the JWT subject of the inserting API request or job into the job metadata, so that
+// follow up jobs such as dispatches and gas refills stay attributed to it.
+func subjectInsertMiddleware() rivertype.Middleware {
+	return river.JobInsertMiddlewareFunc(func(ctx context.Context, manyParams []*rivertype.JobInsertParams, doInner func(context.Context) ([]*rivertype.JobInsertResult, error)) ([]*rivertype.JobInsertResult, error) {
+		subject := store.KeyAccessFromContext(ctx).Subject
+		if subject == "" {
+			return doInner(ctx)
+		}
+
+		for _, params := range manyParams {
+			metadata := make(map[string]any)
+			if len(params.Metadata) > 0 {
+				if err := json.Unmarshal(params.Metadata, &metadata); err != nil {
+					return nil, err
+				}
+			}
+			metadata["subject"] = subject
+
+			encoded, err := json.Marshal(metadata)
+			if err != nil {
+				return nil, err
+			}
+			params.Metadata = encoded
+		}
+
+		return doInner(ctx)
+	})
+}
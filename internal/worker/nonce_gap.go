@@ -0,0 +1,210 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/google/uuid"
+	"github.com/grassrootseconomics/eth-custodial/internal/gas"
+	"github.com/grassrootseconomics/eth-custodial/internal/store"
+	"github.com/grassrootseconomics/ethutils"
+	"github.com/riverqueue/river"
+)
+
+type (
+	NonceGapArgs struct{}
+
+	NonceGapWorker struct {
+		river.WorkerDefaults[NonceGapArgs]
+		wc *WorkerContainer
+	}
+)
+
+const (
+	NonceGapID = "NONCE_GAP"
+
+	// nonceGapLookback limits the check to accounts whose internal nonce moved recently.
+	nonceGapLookback      = 24 * time.Hour
+	nonceGapAccountsLimit = 500
+	// nonceGapFillLimit caps the no-op transactions sent per account per run.
+	nonceGapFillLimit = 25
+)
+
+func (NonceGapArgs) Kind() string { return NonceGapID }
+
+// Work compares the internal next nonce, the network nonce and the OTX nonces of recently active accounts. Nonces
+// that were acquired but never used by an OTX stall every later transaction of the account, they are filled with a
+// no-op transaction at the current oracle price. An internal nonce behind the network nonce is raised to it.
+func (w *NonceGapWorker) Work(ctx context.Context, _ *river.Job[NonceGapArgs]) error {
+	tx, err := w.wc.store.Pool().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	accounts, err := w.wc.store.GetNonceCheckAccounts(ctx, tx, time.Now().Add(-nonceGapLookback), nonceGapAccountsLimit)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	for _, account := range accounts {
+		if err := w.processAccount(ctx, account); err != nil {
+			w.wc.logg.Error("nonce gap: failed to process account", "account", account.PublicKey, "error", err)
+		}
+	}
+
+	return nil
+}
+
+func (w *NonceGapWorker) processAccount(ctx context.Context, account *store.NonceCheckAccount) error {
+	networkNonce, err := w.wc.rpc.Nonce(ctx, ethutils.HexToAddress(account.PublicKey), nil)
+	if err != nil {
+		return err
+	}
+
+	if account.NextNonce < networkNonce {
+		return w.raiseNonce(ctx, account, networkNonce)
+	}
+
+	tx, err := w.wc.store.Pool().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	gaps, err := w.wc.store.GetNonceGaps(ctx, tx, account.PublicKey, networkNonce, nonceGapFillLimit)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	for _, nonce := range gaps {
+		nonceGap := store.NonceGap{
+			PublicKey:     account.PublicKey,
+			Kind:          store.NONCE_MISSING_OTX,
+			Nonce:         nonce,
+			InternalNonce: account.NextNonce,
+			NetworkNonce:  networkNonce,
+		}
+		w.wc.logg.Warn("nonce gap: missing otx", "account", account.PublicKey, "nonce", nonce, "network_nonce", networkNonce, "internal_nonce", account.NextNonce)
+
+		if err := w.fillGap(ctx, nonceGap); err != nil {
+			w.wc.logg.Error("nonce gap: failed to fill gap", "account", account.PublicKey, "nonce", nonce, "error", err)
+			nonceGap.Error = err.Error()
+			if err := w.recordUnrepaired(ctx, nonceGap); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (w *NonceGapWorker) raiseNonce(ctx context.Context, account *store.NonceCheckAccount, networkNonce uint64) error {
+	w.wc.logg.Warn("nonce gap: internal nonce behind network", "account", account.PublicKey, "network_nonce", networkNonce, "internal_nonce", account.NextNonce)
+
+	tx, err := w.wc.store.Pool().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := w.wc.store.RaiseAccountNonce(ctx, tx, account.PublicKey, networkNonce); err != nil {
+		return err
+	}
+
+	if err := w.wc.store.InsertNonceGap(ctx, tx, store.NonceGap{
+		PublicKey:     account.PublicKey,
+		Kind:          store.NONCE_BEHIND,
+		Nonce:         networkNonce,
+		InternalNonce: account.NextNonce,
+		NetworkNonce:  networkNonce,
+		Repaired:      true,
+	}); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// fillGap queues a zero value transfer to the zero address at the missing nonce and records the repair with it.
+func (w *NonceGapWorker) fillGap(ctx context.Context, nonceGap store.NonceGap) error {
+	tx, err := w.wc.store.Pool().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	gasSettings, err := w.wc.gasOracle.GetSettings(gas.DefaultFeeClass(store.NONCE_GAP_FILL))
+	if err != nil {
+		return err
+	}
+
+	builtTx, err := w.wc.signGasTransferTx(ctx, tx, nonceGap.PublicKey, noopTx(gasSettings.GasFeeCap, gasSettings.GasTipCap, nonceGap.Nonce))
+	if err != nil {
+		return err
+	}
+
+	rawTx, err := builtTx.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	rawTxHex := hexutil.Encode(rawTx)
+	trackingID := uuid.NewString()
+
+	otxID, err := w.wc.store.InsertOTX(ctx, tx, store.OTX{
+		TrackingID:    trackingID,
+		OTXType:       store.NONCE_GAP_FILL,
+		SignerAccount: nonceGap.PublicKey,
+		RawTx:         rawTxHex,
+		TxHash:        builtTx.Hash().Hex(),
+		Nonce:         nonceGap.Nonce,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := w.wc.store.InsertDispatchTx(ctx, tx, store.DispatchTx{
+		OTXID:  otxID,
+		Status: store.PENDING,
+	}); err != nil {
+		return err
+	}
+
+	nonceGap.Repaired = true
+	nonceGap.RepairOTXID = &otxID
+	if err := w.wc.store.InsertNonceGap(ctx, tx, nonceGap); err != nil {
+		return err
+	}
+
+	if _, err := w.wc.queueClient.InsertTx(ctx, tx, DispatchArgs{
+		TrackingID: trackingID,
+		OTXID:      otxID,
+		RawTx:      rawTxHex,
+	}, nil); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (w *NonceGapWorker) recordUnrepaired(ctx context.Context, nonceGap store.NonceGap) error {
+	tx, err := w.wc.store.Pool().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := w.wc.store.InsertNonceGap(ctx, tx, nonceGap); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,134 @@
+package worker
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/grassrootseconomics/eth-custodial/internal/gas"
+	"github.com/grassrootseconomics/eth-custodial/internal/store"
+	"github.com/grassrootseconomics/eth-custodial/pkg/event"
+	"github.com/jackc/pgx/v5"
+	"github.com/riverqueue/river"
+)
+
+type (
+	OTXCancelArgs struct {
+		// TrackingID is that of the cancellation, CancelTrackingID is that of the OTX chain being cancelled.
+		TrackingID       string `json:"trackingId"`
+		CancelTrackingID string `json:"cancelTrackingId"`
+	}
+
+	OTXCancelWorker struct {
+		river.WorkerDefaults[OTXCancelArgs]
+		wc *WorkerContainer
+	}
+)
+
+const OTXCancelID = "OTX_CANCEL"
+
+func (OTXCancelArgs) Kind() string { return OTXCancelID }
+
+// Work replaces every OTX of the tracking id that is not yet final with a no-op transaction at the same nonce. Whichever
+// of the two is mined first decides the outcome, see store.ResolveReplacements.
+func (w *OTXCancelWorker) Work(ctx context.Context, job *river.Job[OTXCancelArgs]) error {
+	tx, err := w.wc.store.Pool().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	otxs, err := w.wc.store.GetOTXByTrackingID(ctx, tx, job.Args.CancelTrackingID)
+	if err != nil {
+		return err
+	}
+
+	gasSettings, err := w.wc.gasOracle.GetSettings(gas.DefaultFeeClass(store.CANCEL))
+	if err != nil {
+		return err
+	}
+
+	var cancelled int
+	for _, otx := range otxs {
+		if !IsCancellable(otx) {
+			continue
+		}
+
+		originalTx, err := decodeTx(otx.RawTx)
+		if err != nil {
+			return err
+		}
+
+		gasFeeCap, gasTipCap := bumpFees(originalTx)
+		gasFeeCap = bigMax(gasFeeCap, gasSettings.GasFeeCap)
+		gasTipCap = bigMax(gasTipCap, gasSettings.GasTipCap)
+
+		builtTx, err := w.wc.signGasTransferTx(ctx, tx, otx.SignerAccount, noopTx(gasFeeCap, gasTipCap, otx.Nonce))
+		if err != nil {
+			return err
+		}
+
+		if err := w.insertCancelOTX(ctx, tx, job.Args.TrackingID, otx, builtTx); err != nil {
+			return err
+		}
+		cancelled++
+	}
+
+	if cancelled == 0 {
+		w.wc.logg.Info("otx cancel: nothing left to cancel", "tracking_id", job.Args.CancelTrackingID)
+		return nil
+	}
+
+	w.wc.pub.Send(ctx, event.Event{
+		TrackingID: job.Args.TrackingID,
+		Status:     store.PENDING,
+	})
+
+	return tx.Commit(ctx)
+}
+
+func (w *OTXCancelWorker) insertCancelOTX(ctx context.Context, tx pgx.Tx, trackingID string, otx *store.OTX, builtTx *types.Transaction) error {
+	rawTx, err := builtTx.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	rawTxHex := hexutil.Encode(rawTx)
+
+	otxID, err := w.wc.store.InsertOTX(ctx, tx, store.OTX{
+		TrackingID:    trackingID,
+		OTXType:       store.CANCEL,
+		SignerAccount: otx.SignerAccount,
+		RawTx:         rawTxHex,
+		TxHash:        builtTx.Hash().Hex(),
+		Nonce:         otx.Nonce,
+		ReplacesOTXID: &otx.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	// The cancellation becomes the effective OTX of the nonce, a later cancel or speed-up of the original is refused.
+	if err := w.wc.store.MarkOTXReplaced(ctx, tx, otx.ID); err != nil {
+		return err
+	}
+
+	if err := w.wc.store.InsertDispatchTx(ctx, tx, store.DispatchTx{
+		OTXID:  otxID,
+		Status: store.PENDING,
+	}); err != nil {
+		return err
+	}
+
+	_, err = w.wc.queueClient.InsertTx(ctx, tx, DispatchArgs{
+		TrackingID: trackingID,
+		OTXID:      otxID,
+		RawTx:      rawTxHex,
+	}, nil)
+	return err
+}
+
+// IsCancellable reports whether an OTX can still be replaced by a cancellation.
+func IsCancellable(otx *store.OTX) bool {
+	return IsReplaceable(otx) && otx.OTXType != store.CANCEL
+}
@@ -0,0 +1,54 @@
+package worker
+
+import (
+	"testing"
+
+	"github.com/grassrootseconomics/eth-custodial/internal/store"
+)
+
+func TestIsCancellable(t *testing.T) {
+	tests := []struct {
+		name string
+		otx  store.OTX
+		want bool
+	}{
+		{
+			name: "pending transfer",
+			otx:  store.OTX{OTXType: store.TOKEN_TRANSFER, DispatchStatus: store.PENDING},
+			want: true,
+		},
+		{
+			name: "in network transfer",
+			otx:  store.OTX{OTXType: store.TOKEN_TRANSFER, DispatchStatus: store.IN_NETWORK},
+			want: true,
+		},
+		{
+			name: "confirmed transfer",
+			otx:  store.OTX{OTXType: store.TOKEN_TRANSFER, DispatchStatus: store.CONFIRMED},
+			want: false,
+		},
+		{
+			// The original of a cancellation is marked replaced, cancelling it again must be refused.
+			name: "cancel after cancel",
+			otx:  store.OTX{OTXType: store.TOKEN_TRANSFER, DispatchStatus: store.IN_NETWORK, Replaced: true},
+			want: false,
+		},
+		{
+			name: "pending cancellation",
+			otx:  store.OTX{OTXType: store.CANCEL, DispatchStatus: store.PENDING},
+			want: false,
+		},
+		{
+			name: "generic sign",
+			otx:  store.OTX{OTXType: store.GENERIC_SIGN, DispatchStatus: store.PENDING},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsCancellable(&tt.otx); got != tt.want {
+				t.Errorf("IsCancellable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
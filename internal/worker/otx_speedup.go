@@ -0,0 +1,131 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/grassrootseconomics/eth-custodial/internal/store"
+	"github.com/grassrootseconomics/eth-custodial/pkg/event"
+	"github.com/riverqueue/river"
+)
+
+type (
+	OTXSpeedUpArgs struct {
+		TrackingID string  `json:"trackingId"`
+		FeeBump    FeeBump `json:"feeBump"`
+	}
+
+	OTXSpeedUpWorker struct {
+		river.WorkerDefaults[OTXSpeedUpArgs]
+		wc *WorkerContainer
+	}
+
+	// FeeBump sets the fees of a replacement. Either both explicit fee caps are set, or the original fees are multiplied
+	// by Multiplier. The zero value bumps by 15%.
+	FeeBump struct {
+		Multiplier float64  `json:"multiplier,omitempty"`
+		GasFeeCap  *big.Int `json:"gasFeeCap,omitempty"`
+		GasTipCap  *big.Int `json:"gasTipCap,omitempty"`
+	}
+)
+
+const (
+	OTXSpeedUpID = "OTX_SPEEDUP"
+
+	// minReplacementBump is the minimum percentage increase of both fees that nodes accept for a replacement.
+	minReplacementBump = 110
+)
+
+var (
+	ErrFeeBumpTooLow    = errors.New("fee bump is below the 10% minimum required for a replacement")
+	ErrFeeAboveMaximum  = errors.New("fee cap is above the allowed maximum")
+	ErrTipAboveFeeCap   = errors.New("tip cap is above the fee cap")
+	ErrNothingToReplace = errors.New("no replaceable transaction in the otx chain")
+)
+
+func (OTXSpeedUpArgs) Kind() string { return OTXSpeedUpID }
+
+// Apply returns the replacement fee cap and tip for originalTx, refusing fees above maxGasFeeCap.
+func (b FeeBump) Apply(originalTx *types.Transaction, maxGasFeeCap *big.Int) (*big.Int, *big.Int, error) {
+	var gasFeeCap, gasTipCap *big.Int
+
+	switch {
+	case b.GasFeeCap != nil && b.GasTipCap != nil:
+		gasFeeCap, gasTipCap = b.GasFeeCap, b.GasTipCap
+	case b.Multiplier > 0:
+		perMille := big.NewInt(int64(b.Multiplier * 1000))
+		gasFeeCap = new(big.Int).Div(new(big.Int).Mul(originalTx.GasFeeCap(), perMille), big.NewInt(1000))
+		gasTipCap = new(big.Int).Div(new(big.Int).Mul(originalTx.GasTipCap(), perMille), big.NewInt(1000))
+	default:
+		gasFeeCap, gasTipCap = bumpFees(originalTx)
+	}
+
+	if gasFeeCap.Cmp(minimumReplacementFee(originalTx.GasFeeCap())) < 0 || gasTipCap.Cmp(minimumReplacementFee(originalTx.GasTipCap())) < 0 {
+		return nil, nil, ErrFeeBumpTooLow
+	}
+	if gasTipCap.Cmp(gasFeeCap) > 0 {
+		return nil, nil, ErrTipAboveFeeCap
+	}
+	if maxGasFeeCap != nil && gasFeeCap.Cmp(maxGasFeeCap) > 0 {
+		return nil, nil, ErrFeeAboveMaximum
+	}
+
+	return gasFeeCap, gasTipCap, nil
+}
+
+// Work re-signs every replaceable OTX of the tracking id at the same nonce with bumped fees. The replacement is a new
+// OTX linked to the original, which is kept so that whichever of the two is mined resolves the nonce.
+func (w *OTXSpeedUpWorker) Work(ctx context.Context, job *river.Job[OTXSpeedUpArgs]) error {
+	tx, err := w.wc.store.Pool().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	otxs, err := w.wc.store.GetOTXByTrackingID(ctx, tx, job.Args.TrackingID)
+	if err != nil {
+		return err
+	}
+
+	var replaced int
+	for _, otx := range otxs {
+		if !IsReplaceable(otx) {
+			continue
+		}
+
+		originalTx, err := decodeTx(otx.RawTx)
+		if err != nil {
+			return err
+		}
+
+		gasFeeCap, gasTipCap, err := job.Args.FeeBump.Apply(originalTx, w.wc.maxGasFeeCap)
+		if err != nil {
+			return river.JobCancel(fmt.Errorf("otx %d: %w", otx.ID, err))
+		}
+
+		if _, err := w.wc.replaceOTX(ctx, tx, otx, originalTx, gasFeeCap, gasTipCap, 0); err != nil {
+			return err
+		}
+		replaced++
+	}
+
+	if replaced == 0 {
+		w.wc.logg.Info("otx speed-up: nothing left to replace", "tracking_id", job.Args.TrackingID)
+		return nil
+	}
+
+	w.wc.pub.Send(ctx, event.Event{
+		TrackingID: job.Args.TrackingID,
+		Status:     store.PENDING,
+	})
+
+	return tx.Commit(ctx)
+}
+
+func minimumReplacementFee(fee *big.Int) *big.Int {
+	minimum := new(big.Int).Mul(fee, big.NewInt(minReplacementBump))
+	return minimum.Div(minimum, big.NewInt(100))
+}
@@ -0,0 +1,79 @@
+package worker
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/lmittmann/w3"
+)
+
+func TestFeeBump_Apply(t *testing.T) {
+	originalTx := types.NewTx(&types.DynamicFeeTx{
+		GasFeeCap: w3.I("10 gwei"),
+		GasTipCap: w3.I("1 gwei"),
+	})
+
+	tests := []struct {
+		name          string
+		feeBump       FeeBump
+		maxGasFeeCap  *big.Int
+		wantGasFeeCap *big.Int
+		wantGasTipCap *big.Int
+		wantErr       error
+	}{
+		{
+			name:          "default bump",
+			wantGasFeeCap: w3.I("11.5 gwei"),
+			wantGasTipCap: w3.I("1.15 gwei"),
+		},
+		{
+			name:          "multiplier",
+			feeBump:       FeeBump{Multiplier: 2},
+			wantGasFeeCap: w3.I("20 gwei"),
+			wantGasTipCap: w3.I("2 gwei"),
+		},
+		{
+			name:          "explicit fee caps",
+			feeBump:       FeeBump{GasFeeCap: w3.I("30 gwei"), GasTipCap: w3.I("3 gwei")},
+			wantGasFeeCap: w3.I("30 gwei"),
+			wantGasTipCap: w3.I("3 gwei"),
+		},
+		{
+			name:    "explicit tip below replacement minimum",
+			feeBump: FeeBump{GasFeeCap: w3.I("30 gwei"), GasTipCap: w3.I("1 gwei")},
+			wantErr: ErrFeeBumpTooLow,
+		},
+		{
+			name:    "multiplier below replacement minimum",
+			feeBump: FeeBump{Multiplier: 1.05},
+			wantErr: ErrFeeBumpTooLow,
+		},
+		{
+			name:    "tip above fee cap",
+			feeBump: FeeBump{GasFeeCap: w3.I("12 gwei"), GasTipCap: w3.I("13 gwei")},
+			wantErr: ErrTipAboveFeeCap,
+		},
+		{
+			name:         "above maximum",
+			feeBump:      FeeBump{Multiplier: 3},
+			maxGasFeeCap: w3.I("25 gwei"),
+			wantErr:      ErrFeeAboveMaximum,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gasFeeCap, gasTipCap, err := tt.feeBump.Apply(originalTx, tt.maxGasFeeCap)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if gasFeeCap.Cmp(tt.wantGasFeeCap) != 0 || gasTipCap.Cmp(tt.wantGasTipCap) != 0 {
+				t.Errorf("Apply() = %v, %v, want %v, %v", gasFeeCap, gasTipCap, tt.wantGasFeeCap, tt.wantGasTipCap)
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
 	ensclient "github.com/grassrootseconomics/eth-custodial/internal/ens_client"
+	"github.com/grassrootseconomics/eth-custodial/internal/gas"
 	"github.com/grassrootseconomics/eth-custodial/internal/store"
 	"github.com/grassrootseconomics/eth-custodial/pkg/event"
 	"github.com/grassrootseconomics/ethutils"
@@ -22,6 +23,7 @@ type (
 		Name       string `json:"name"`
 		Symbol     string `json:"symbol"`
 		Owner      string `json:"owner"`
+		FeeClass   string `json:"feeClass,omitempty"`
 	}
 
 	PoolDeployWorker struct {
@@ -39,23 +41,18 @@ func (w *PoolDeployWorker) Work(ctx context.Context, job *river.Job[PoolDeployAr
 	}
 	defer tx.Rollback(ctx)
 
-	systemKeypair, err := w.wc.store.LoadMasterSignerKey(ctx, tx)
+	systemAddress, err := w.wc.assignSystemSigner(ctx, tx)
 	if err != nil {
 		return err
 	}
 
-	privateKey, err := crypto.HexToECDSA(systemKeypair.Private)
-	if err != nil {
-		return err
-	}
-
-	gasSettings, err := w.wc.gasOracle.GetSettings()
+	gasSettings, err := w.wc.gasOracle.GetSettings(gas.ResolveFeeClass(job.Args.FeeClass, store.POOL_DEPLOY))
 	if err != nil {
 		return err
 	}
 
 	// Deploy TokenIndex
-	tokenIndexNonce, err := w.wc.store.AcquireNonce(ctx, tx, systemKeypair.Public)
+	tokenIndexNonce, err := w.wc.store.AcquireNonce(ctx, tx, systemAddress)
 	if err != nil {
 		return err
 	}
@@ -66,8 +63,8 @@ func (w *PoolDeployWorker) Work(ctx context.Context, job *river.Job[PoolDeployAr
 		return err
 	}
 
-	builtTokenIndexDeployTx, err := w.wc.chainProvider.SignContractPublishTx(privateKey, ethutils.ContractPublishTxOpts{
-		ContractByteCode: addDivviRefferalTag(w.wc.chainProvider, tokenIndexByteCode, ethutils.HexToAddress(systemKeypair.Public)),
+	builtTokenIndexDeployTx, err := w.wc.signContractPublishTx(ctx, tx, systemAddress, ethutils.ContractPublishTxOpts{
+		ContractByteCode: addDivviRefferalTag(w.wc.chainProvider, tokenIndexByteCode, ethutils.HexToAddress(systemAddress)),
 		GasFeeCap:        gasSettings.GasFeeCap,
 		GasTipCap:        gasSettings.GasTipCap,
 		GasLimit:         tokenIndexContract.MaxGasLimit(),
@@ -83,12 +80,12 @@ func (w *PoolDeployWorker) Work(ctx context.Context, job *river.Job[PoolDeployAr
 	}
 
 	rawTokenIndexDeployTxHex := hexutil.Encode(rawTokenIndexDeployTx)
-	tokenIndexAddress := crypto.CreateAddress(common.HexToAddress(systemKeypair.Public), tokenIndexNonce)
+	tokenIndexAddress := crypto.CreateAddress(common.HexToAddress(systemAddress), tokenIndexNonce)
 
 	tokenIndexDeployOTXID, err := w.wc.store.InsertOTX(ctx, tx, store.OTX{
 		TrackingID:    job.Args.TrackingID,
 		OTXType:       store.TOKEN_INDEX_DEPLOY,
-		SignerAccount: systemKeypair.Public,
+		SignerAccount: systemAddress,
 		RawTx:         rawTokenIndexDeployTxHex,
 		TxHash:        builtTokenIndexDeployTx.Hash().Hex(),
 		Nonce:         tokenIndexNonce,
@@ -105,7 +102,7 @@ func (w *PoolDeployWorker) Work(ctx context.Context, job *river.Job[PoolDeployAr
 	}
 
 	// Deploy Limiter
-	limiterNonce, err := w.wc.store.AcquireNonce(ctx, tx, systemKeypair.Public)
+	limiterNonce, err := w.wc.store.AcquireNonce(ctx, tx, systemAddress)
 	if err != nil {
 		return err
 	}
@@ -116,8 +113,8 @@ func (w *PoolDeployWorker) Work(ctx context.Context, job *river.Job[PoolDeployAr
 		return err
 	}
 
-	builtLimiterDeployTx, err := w.wc.chainProvider.SignContractPublishTx(privateKey, ethutils.ContractPublishTxOpts{
-		ContractByteCode: addDivviRefferalTag(w.wc.chainProvider, limiterByteCode, ethutils.HexToAddress(systemKeypair.Public)),
+	builtLimiterDeployTx, err := w.wc.signContractPublishTx(ctx, tx, systemAddress, ethutils.ContractPublishTxOpts{
+		ContractByteCode: addDivviRefferalTag(w.wc.chainProvider, limiterByteCode, ethutils.HexToAddress(systemAddress)),
 		GasFeeCap:        gasSettings.GasFeeCap,
 		GasTipCap:        gasSettings.GasTipCap,
 		GasLimit:         limiterContract.MaxGasLimit(),
@@ -133,12 +130,12 @@ func (w *PoolDeployWorker) Work(ctx context.Context, job *river.Job[PoolDeployAr
 	}
 
 	rawLimiterDeployTxHex := hexutil.Encode(rawLimiterDeployTx)
-	limiterAddress := crypto.CreateAddress(common.HexToAddress(systemKeypair.Public), limiterNonce)
+	limiterAddress := crypto.CreateAddress(common.HexToAddress(systemAddress), limiterNonce)
 
 	limiterDeployOTXID, err := w.wc.store.InsertOTX(ctx, tx, store.OTX{
 		TrackingID:    job.Args.TrackingID,
 		OTXType:       store.LIMITER_DEPLOY,
-		SignerAccount: systemKeypair.Public,
+		SignerAccount: systemAddress,
 		RawTx:         rawLimiterDeployTxHex,
 		TxHash:        builtLimiterDeployTx.Hash().Hex(),
 		Nonce:         limiterNonce,
@@ -155,7 +152,7 @@ func (w *PoolDeployWorker) Work(ctx context.Context, job *river.Job[PoolDeployAr
 	}
 
 	// Deploy SwapPool
-	swapPoolNonce, err := w.wc.store.AcquireNonce(ctx, tx, systemKeypair.Public)
+	swapPoolNonce, err := w.wc.store.AcquireNonce(ctx, tx, systemAddress)
 	if err != nil {
 		return err
 	}
@@ -172,8 +169,8 @@ func (w *PoolDeployWorker) Work(ctx context.Context, job *river.Job[PoolDeployAr
 		return err
 	}
 
-	builtSwapPoolDeployTx, err := w.wc.chainProvider.SignContractPublishTx(privateKey, ethutils.ContractPublishTxOpts{
-		ContractByteCode: addDivviRefferalTag(w.wc.chainProvider, swapPoolByteCode, ethutils.HexToAddress(systemKeypair.Public)),
+	builtSwapPoolDeployTx, err := w.wc.signContractPublishTx(ctx, tx, systemAddress, ethutils.ContractPublishTxOpts{
+		ContractByteCode: addDivviRefferalTag(w.wc.chainProvider, swapPoolByteCode, ethutils.HexToAddress(systemAddress)),
 		GasFeeCap:        gasSettings.GasFeeCap,
 		GasTipCap:        gasSettings.GasTipCap,
 		GasLimit:         swapPoolContract.MaxGasLimit(),
@@ -189,12 +186,12 @@ func (w *PoolDeployWorker) Work(ctx context.Context, job *river.Job[PoolDeployAr
 	}
 
 	rawSwapPoolDeployTxHex := hexutil.Encode(rawSwapPoolDeployTx)
-	swapPoolAddress := crypto.CreateAddress(common.HexToAddress(systemKeypair.Public), swapPoolNonce)
+	swapPoolAddress := crypto.CreateAddress(common.HexToAddress(systemAddress), swapPoolNonce)
 
 	swapPoolDeployOTXID, err := w.wc.store.InsertOTX(ctx, tx, store.OTX{
 		TrackingID:    job.Args.TrackingID,
 		OTXType:       store.SWAPPOOL_DEPLOY,
-		SignerAccount: systemKeypair.Public,
+		SignerAccount: systemAddress,
 		RawTx:         rawSwapPoolDeployTxHex,
 		TxHash:        builtSwapPoolDeployTx.Hash().Hex(),
 		Nonce:         swapPoolNonce,
@@ -211,7 +208,7 @@ func (w *PoolDeployWorker) Work(ctx context.Context, job *river.Job[PoolDeployAr
 	}
 
 	// Deploy PriceIndexQuoter
-	priceIndexQuoterNonce, err := w.wc.store.AcquireNonce(ctx, tx, systemKeypair.Public)
+	priceIndexQuoterNonce, err := w.wc.store.AcquireNonce(ctx, tx, systemAddress)
 	if err != nil {
 		return err
 	}
@@ -222,8 +219,8 @@ func (w *PoolDeployWorker) Work(ctx context.Context, job *river.Job[PoolDeployAr
 		return err
 	}
 
-	builtPriceIndexQuoterDeployTx, err := w.wc.chainProvider.SignContractPublishTx(privateKey, ethutils.ContractPublishTxOpts{
-		ContractByteCode: addDivviRefferalTag(w.wc.chainProvider, priceIndexQuoterByteCode, ethutils.HexToAddress(systemKeypair.Public)),
+	builtPriceIndexQuoterDeployTx, err := w.wc.signContractPublishTx(ctx, tx, systemAddress, ethutils.ContractPublishTxOpts{
+		ContractByteCode: addDivviRefferalTag(w.wc.chainProvider, priceIndexQuoterByteCode, ethutils.HexToAddress(systemAddress)),
 		GasFeeCap:        gasSettings.GasFeeCap,
 		GasTipCap:        gasSettings.GasTipCap,
 		GasLimit:         priceIndexQuoterContract.MaxGasLimit(),
@@ -239,12 +236,12 @@ func (w *PoolDeployWorker) Work(ctx context.Context, job *river.Job[PoolDeployAr
 	}
 
 	rawPriceIndexQuoterDeployTxHex := hexutil.Encode(rawPriceIndexQuoterDeployTx)
-	priceIndexQuoterAddress := crypto.CreateAddress(common.HexToAddress(systemKeypair.Public), priceIndexQuoterNonce)
+	priceIndexQuoterAddress := crypto.CreateAddress(common.HexToAddress(systemAddress), priceIndexQuoterNonce)
 
 	priceIndexQuoterDeployOTXID, err := w.wc.store.InsertOTX(ctx, tx, store.OTX{
 		TrackingID:    job.Args.TrackingID,
 		OTXType:       store.PRICEINDEXQUOTER_DEPLOY,
-		SignerAccount: systemKeypair.Public,
+		SignerAccount: systemAddress,
 		RawTx:         rawPriceIndexQuoterDeployTxHex,
 		TxHash:        builtPriceIndexQuoterDeployTx.Hash().Hex(),
 		Nonce:         priceIndexQuoterNonce,
@@ -266,7 +263,7 @@ func (w *PoolDeployWorker) Work(ctx context.Context, job *river.Job[PoolDeployAr
 		return err
 	}
 
-	addToPoolIndexNonce, err := w.wc.store.AcquireNonce(ctx, tx, systemKeypair.Public)
+	addToPoolIndexNonce, err := w.wc.store.AcquireNonce(ctx, tx, systemAddress)
 	if err != nil {
 		return err
 	}
@@ -276,12 +273,17 @@ func (w *PoolDeployWorker) Work(ctx context.Context, job *river.Job[PoolDeployAr
 		poolIndex = w3.A("0x01eD8Fe01a2Ca44Cb26D00b1309d7D777471D00C")
 	}
 
-	builtAddToPoolIndexTx, err := w.wc.chainProvider.SignContractExecutionTx(privateKey, ethutils.ContractExecutionTxOpts{
+	addToPoolIndexGasLimit, err := w.wc.gasLimitEstimator.Estimate(ctx, store.POOL_INDEX_ADD, ethutils.HexToAddress(systemAddress), poolIndex, addToPoolIndexData, nil)
+	if err != nil {
+		return err
+	}
+
+	builtAddToPoolIndexTx, err := w.wc.signContractExecutionTx(ctx, tx, systemAddress, ethutils.ContractExecutionTxOpts{
 		ContractAddress: poolIndex,
-		InputData:       addDivviRefferalTag(w.wc.chainProvider, addToPoolIndexData, ethutils.HexToAddress(systemKeypair.Public)),
+		InputData:       addDivviRefferalTag(w.wc.chainProvider, addToPoolIndexData, ethutils.HexToAddress(systemAddress)),
 		GasFeeCap:       gasSettings.GasFeeCap,
 		GasTipCap:       gasSettings.GasTipCap,
-		GasLimit:        gasSettings.GasLimit,
+		GasLimit:        addToPoolIndexGasLimit.Limit,
 		Nonce:           addToPoolIndexNonce,
 	})
 	if err != nil {
@@ -296,12 +298,14 @@ func (w *PoolDeployWorker) Work(ctx context.Context, job *river.Job[PoolDeployAr
 	rawAddToPoolIndexTxHex := hexutil.Encode(rawAddToPoolIndexTx)
 
 	addToPoolIndexOTXID, err := w.wc.store.InsertOTX(ctx, tx, store.OTX{
-		TrackingID:    job.Args.TrackingID,
-		OTXType:       store.POOL_INDEX_ADD,
-		SignerAccount: systemKeypair.Public,
-		RawTx:         rawAddToPoolIndexTxHex,
-		TxHash:        builtAddToPoolIndexTx.Hash().Hex(),
-		Nonce:         addToPoolIndexNonce,
+		TrackingID:     job.Args.TrackingID,
+		OTXType:        store.POOL_INDEX_ADD,
+		SignerAccount:  systemAddress,
+		RawTx:          rawAddToPoolIndexTxHex,
+		TxHash:         builtAddToPoolIndexTx.Hash().Hex(),
+		Nonce:          addToPoolIndexNonce,
+		GasLimit:       addToPoolIndexGasLimit.Limit,
+		GasLimitSource: addToPoolIndexGasLimit.Source,
 	})
 	if err != nil {
 		return err
@@ -320,17 +324,22 @@ func (w *PoolDeployWorker) Work(ctx context.Context, job *river.Job[PoolDeployAr
 		return err
 	}
 
-	setQuoterNonce, err := w.wc.store.AcquireNonce(ctx, tx, systemKeypair.Public)
+	setQuoterNonce, err := w.wc.store.AcquireNonce(ctx, tx, systemAddress)
+	if err != nil {
+		return err
+	}
+
+	setQuoterGasLimit, err := w.wc.gasLimitEstimator.Estimate(ctx, store.SET_QUOTER, ethutils.HexToAddress(systemAddress), swapPoolAddress, setQuoterData, nil)
 	if err != nil {
 		return err
 	}
 
-	builtSetQuoterTx, err := w.wc.chainProvider.SignContractExecutionTx(privateKey, ethutils.ContractExecutionTxOpts{
+	builtSetQuoterTx, err := w.wc.signContractExecutionTx(ctx, tx, systemAddress, ethutils.ContractExecutionTxOpts{
 		ContractAddress: swapPoolAddress,
-		InputData:       addDivviRefferalTag(w.wc.chainProvider, setQuoterData, ethutils.HexToAddress(systemKeypair.Public)),
+		InputData:       addDivviRefferalTag(w.wc.chainProvider, setQuoterData, ethutils.HexToAddress(systemAddress)),
 		GasFeeCap:       gasSettings.GasFeeCap,
 		GasTipCap:       gasSettings.GasTipCap,
-		GasLimit:        gasSettings.GasLimit,
+		GasLimit:        setQuoterGasLimit.Limit,
 		Nonce:           setQuoterNonce,
 	})
 	if err != nil {
@@ -345,12 +354,14 @@ func (w *PoolDeployWorker) Work(ctx context.Context, job *river.Job[PoolDeployAr
 	rawSetQuoterTxHex := hexutil.Encode(rawSetQuoterTx)
 
 	setQuoterOTXID, err := w.wc.store.InsertOTX(ctx, tx, store.OTX{
-		TrackingID:    job.Args.TrackingID,
-		OTXType:       store.SET_QUOTER,
-		SignerAccount: systemKeypair.Public,
-		RawTx:         rawSetQuoterTxHex,
-		TxHash:        builtSetQuoterTx.Hash().Hex(),
-		Nonce:         setQuoterNonce,
+		TrackingID:     job.Args.TrackingID,
+		OTXType:        store.SET_QUOTER,
+		SignerAccount:  systemAddress,
+		RawTx:          rawSetQuoterTxHex,
+		TxHash:         builtSetQuoterTx.Hash().Hex(),
+		Nonce:          setQuoterNonce,
+		GasLimit:       setQuoterGasLimit.Limit,
+		GasLimitSource: setQuoterGasLimit.Source,
 	})
 	if err != nil {
 		return err
@@ -371,17 +382,22 @@ func (w *PoolDeployWorker) Work(ctx context.Context, job *river.Job[PoolDeployAr
 		return err
 	}
 
-	transferLimiterOwnershipNonce, err := w.wc.store.AcquireNonce(ctx, tx, systemKeypair.Public)
+	transferLimiterOwnershipNonce, err := w.wc.store.AcquireNonce(ctx, tx, systemAddress)
 	if err != nil {
 		return err
 	}
 
-	builtTransferLimiterOwnershipTx, err := w.wc.chainProvider.SignContractExecutionTx(privateKey, ethutils.ContractExecutionTxOpts{
+	transferLimiterOwnershipGasLimit, err := w.wc.gasLimitEstimator.Estimate(ctx, store.TRANSFER_OWNERSHIP, ethutils.HexToAddress(systemAddress), limiterAddress, transferLimiterOwnershipData, nil)
+	if err != nil {
+		return err
+	}
+
+	builtTransferLimiterOwnershipTx, err := w.wc.signContractExecutionTx(ctx, tx, systemAddress, ethutils.ContractExecutionTxOpts{
 		ContractAddress: limiterAddress,
-		InputData:       addDivviRefferalTag(w.wc.chainProvider, transferLimiterOwnershipData, ethutils.HexToAddress(systemKeypair.Public)),
+		InputData:       addDivviRefferalTag(w.wc.chainProvider, transferLimiterOwnershipData, ethutils.HexToAddress(systemAddress)),
 		GasFeeCap:       gasSettings.GasFeeCap,
 		GasTipCap:       gasSettings.GasTipCap,
-		GasLimit:        gasSettings.GasLimit,
+		GasLimit:        transferLimiterOwnershipGasLimit.Limit,
 		Nonce:           transferLimiterOwnershipNonce,
 	})
 	if err != nil {
@@ -396,12 +412,14 @@ func (w *PoolDeployWorker) Work(ctx context.Context, job *river.Job[PoolDeployAr
 	rawTransferLimiterOwnershipTxHex := hexutil.Encode(rawTransferLimiterOwnershipTx)
 
 	transferLimiterOwnershipOTXID, err := w.wc.store.InsertOTX(ctx, tx, store.OTX{
-		TrackingID:    job.Args.TrackingID,
-		OTXType:       store.TRANSFER_OWNERSHIP,
-		SignerAccount: systemKeypair.Public,
-		RawTx:         rawTransferLimiterOwnershipTxHex,
-		TxHash:        builtTransferLimiterOwnershipTx.Hash().Hex(),
-		Nonce:         transferLimiterOwnershipNonce,
+		TrackingID:     job.Args.TrackingID,
+		OTXType:        store.TRANSFER_OWNERSHIP,
+		SignerAccount:  systemAddress,
+		RawTx:          rawTransferLimiterOwnershipTxHex,
+		TxHash:         builtTransferLimiterOwnershipTx.Hash().Hex(),
+		Nonce:          transferLimiterOwnershipNonce,
+		GasLimit:       transferLimiterOwnershipGasLimit.Limit,
+		GasLimitSource: transferLimiterOwnershipGasLimit.Source,
 	})
 	if err != nil {
 		return err
@@ -420,17 +438,22 @@ func (w *PoolDeployWorker) Work(ctx context.Context, job *river.Job[PoolDeployAr
 		return err
 	}
 
-	transferTokenIndexOwnershipNonce, err := w.wc.store.AcquireNonce(ctx, tx, systemKeypair.Public)
+	transferTokenIndexOwnershipNonce, err := w.wc.store.AcquireNonce(ctx, tx, systemAddress)
 	if err != nil {
 		return err
 	}
 
-	builtTransferTokenIndexOwnershipTx, err := w.wc.chainProvider.SignContractExecutionTx(privateKey, ethutils.ContractExecutionTxOpts{
+	transferTokenIndexOwnershipGasLimit, err := w.wc.gasLimitEstimator.Estimate(ctx, store.TRANSFER_OWNERSHIP, ethutils.HexToAddress(systemAddress), tokenIndexAddress, transferTokenIndexOwnershipData, nil)
+	if err != nil {
+		return err
+	}
+
+	builtTransferTokenIndexOwnershipTx, err := w.wc.signContractExecutionTx(ctx, tx, systemAddress, ethutils.ContractExecutionTxOpts{
 		ContractAddress: tokenIndexAddress,
-		InputData:       addDivviRefferalTag(w.wc.chainProvider, transferTokenIndexOwnershipData, ethutils.HexToAddress(systemKeypair.Public)),
+		InputData:       addDivviRefferalTag(w.wc.chainProvider, transferTokenIndexOwnershipData, ethutils.HexToAddress(systemAddress)),
 		GasFeeCap:       gasSettings.GasFeeCap,
 		GasTipCap:       gasSettings.GasTipCap,
-		GasLimit:        gasSettings.GasLimit,
+		GasLimit:        transferTokenIndexOwnershipGasLimit.Limit,
 		Nonce:           transferTokenIndexOwnershipNonce,
 	})
 	if err != nil {
@@ -445,12 +468,14 @@ func (w *PoolDeployWorker) Work(ctx context.Context, job *river.Job[PoolDeployAr
 	rawTransferTokenIndexOwnershipTxHex := hexutil.Encode(rawTransferTokenIndexOwnershipTx)
 
 	transferTokenIndexOwnershipOTXID, err := w.wc.store.InsertOTX(ctx, tx, store.OTX{
-		TrackingID:    job.Args.TrackingID,
-		OTXType:       store.TRANSFER_OWNERSHIP,
-		SignerAccount: systemKeypair.Public,
-		RawTx:         rawTransferTokenIndexOwnershipTxHex,
-		TxHash:        builtTransferTokenIndexOwnershipTx.Hash().Hex(),
-		Nonce:         transferTokenIndexOwnershipNonce,
+		TrackingID:     job.Args.TrackingID,
+		OTXType:        store.TRANSFER_OWNERSHIP,
+		SignerAccount:  systemAddress,
+		RawTx:          rawTransferTokenIndexOwnershipTxHex,
+		TxHash:         builtTransferTokenIndexOwnershipTx.Hash().Hex(),
+		Nonce:          transferTokenIndexOwnershipNonce,
+		GasLimit:       transferTokenIndexOwnershipGasLimit.Limit,
+		GasLimitSource: transferTokenIndexOwnershipGasLimit.Source,
 	})
 	if err != nil {
 		return err
@@ -469,17 +494,22 @@ func (w *PoolDeployWorker) Work(ctx context.Context, job *river.Job[PoolDeployAr
 		return err
 	}
 
-	transferSwapPoolOwnershipNonce, err := w.wc.store.AcquireNonce(ctx, tx, systemKeypair.Public)
+	transferSwapPoolOwnershipNonce, err := w.wc.store.AcquireNonce(ctx, tx, systemAddress)
+	if err != nil {
+		return err
+	}
+
+	transferSwapPoolOwnershipGasLimit, err := w.wc.gasLimitEstimator.Estimate(ctx, store.TRANSFER_OWNERSHIP, ethutils.HexToAddress(systemAddress), swapPoolAddress, transferSwapPoolOwnershipData, nil)
 	if err != nil {
 		return err
 	}
 
-	builtTransferSwapPoolOwnershipTx, err := w.wc.chainProvider.SignContractExecutionTx(privateKey, ethutils.ContractExecutionTxOpts{
+	builtTransferSwapPoolOwnershipTx, err := w.wc.signContractExecutionTx(ctx, tx, systemAddress, ethutils.ContractExecutionTxOpts{
 		ContractAddress: swapPoolAddress,
-		InputData:       addDivviRefferalTag(w.wc.chainProvider, transferSwapPoolOwnershipData, ethutils.HexToAddress(systemKeypair.Public)),
+		InputData:       addDivviRefferalTag(w.wc.chainProvider, transferSwapPoolOwnershipData, ethutils.HexToAddress(systemAddress)),
 		GasFeeCap:       gasSettings.GasFeeCap,
 		GasTipCap:       gasSettings.GasTipCap,
-		GasLimit:        gasSettings.GasLimit,
+		GasLimit:        transferSwapPoolOwnershipGasLimit.Limit,
 		Nonce:           transferSwapPoolOwnershipNonce,
 	})
 	if err != nil {
@@ -494,12 +524,14 @@ func (w *PoolDeployWorker) Work(ctx context.Context, job *river.Job[PoolDeployAr
 	rawTransferSwapPoolOwnershipTxHex := hexutil.Encode(rawTransferSwapPoolOwnershipTx)
 
 	transferSwapPoolOwnershipOTXID, err := w.wc.store.InsertOTX(ctx, tx, store.OTX{
-		TrackingID:    job.Args.TrackingID,
-		OTXType:       store.TRANSFER_OWNERSHIP,
-		SignerAccount: systemKeypair.Public,
-		RawTx:         rawTransferSwapPoolOwnershipTxHex,
-		TxHash:        builtTransferSwapPoolOwnershipTx.Hash().Hex(),
-		Nonce:         transferSwapPoolOwnershipNonce,
+		TrackingID:     job.Args.TrackingID,
+		OTXType:        store.TRANSFER_OWNERSHIP,
+		SignerAccount:  systemAddress,
+		RawTx:          rawTransferSwapPoolOwnershipTxHex,
+		TxHash:         builtTransferSwapPoolOwnershipTx.Hash().Hex(),
+		Nonce:          transferSwapPoolOwnershipNonce,
+		GasLimit:       transferSwapPoolOwnershipGasLimit.Limit,
+		GasLimitSource: transferSwapPoolOwnershipGasLimit.Source,
 	})
 	if err != nil {
 		return err
@@ -518,17 +550,22 @@ func (w *PoolDeployWorker) Work(ctx context.Context, job *river.Job[PoolDeployAr
 		return err
 	}
 
-	transferPriceIndexQuoterOwnershipNonce, err := w.wc.store.AcquireNonce(ctx, tx, systemKeypair.Public)
+	transferPriceIndexQuoterOwnershipNonce, err := w.wc.store.AcquireNonce(ctx, tx, systemAddress)
+	if err != nil {
+		return err
+	}
+
+	transferPriceIndexQuoterOwnershipGasLimit, err := w.wc.gasLimitEstimator.Estimate(ctx, store.TRANSFER_OWNERSHIP, ethutils.HexToAddress(systemAddress), priceIndexQuoterAddress, transferPriceIndexQuoterOwnershipData, nil)
 	if err != nil {
 		return err
 	}
 
-	builtTransferPriceIndexQuoterOwnershipTx, err := w.wc.chainProvider.SignContractExecutionTx(privateKey, ethutils.ContractExecutionTxOpts{
+	builtTransferPriceIndexQuoterOwnershipTx, err := w.wc.signContractExecutionTx(ctx, tx, systemAddress, ethutils.ContractExecutionTxOpts{
 		ContractAddress: priceIndexQuoterAddress,
-		InputData:       addDivviRefferalTag(w.wc.chainProvider, transferPriceIndexQuoterOwnershipData, ethutils.HexToAddress(systemKeypair.Public)),
+		InputData:       addDivviRefferalTag(w.wc.chainProvider, transferPriceIndexQuoterOwnershipData, ethutils.HexToAddress(systemAddress)),
 		GasFeeCap:       gasSettings.GasFeeCap,
 		GasTipCap:       gasSettings.GasTipCap,
-		GasLimit:        gasSettings.GasLimit,
+		GasLimit:        transferPriceIndexQuoterOwnershipGasLimit.Limit,
 		Nonce:           transferPriceIndexQuoterOwnershipNonce,
 	})
 	if err != nil {
@@ -543,12 +580,14 @@ func (w *PoolDeployWorker) Work(ctx context.Context, job *river.Job[PoolDeployAr
 	rawTransferPriceIndexQuoterOwnershipTxHex := hexutil.Encode(rawTransferPriceIndexQuoterOwnershipTx)
 
 	transferPriceIndexQuoterOwnershipOTXID, err := w.wc.store.InsertOTX(ctx, tx, store.OTX{
-		TrackingID:    job.Args.TrackingID,
-		OTXType:       store.TRANSFER_OWNERSHIP,
-		SignerAccount: systemKeypair.Public,
-		RawTx:         rawTransferPriceIndexQuoterOwnershipTxHex,
-		TxHash:        builtTransferPriceIndexQuoterOwnershipTx.Hash().Hex(),
-		Nonce:         transferPriceIndexQuoterOwnershipNonce,
+		TrackingID:     job.Args.TrackingID,
+		OTXType:        store.TRANSFER_OWNERSHIP,
+		SignerAccount:  systemAddress,
+		RawTx:          rawTransferPriceIndexQuoterOwnershipTxHex,
+		TxHash:         builtTransferPriceIndexQuoterOwnershipTx.Hash().Hex(),
+		Nonce:          transferPriceIndexQuoterOwnershipNonce,
+		GasLimit:       transferPriceIndexQuoterOwnershipGasLimit.Limit,
+		GasLimitSource: transferPriceIndexQuoterOwnershipGasLimit.Source,
 	})
 	if err != nil {
 		return err
@@ -574,9 +613,10 @@ func (w *PoolDeployWorker) Work(ctx context.Context, job *river.Job[PoolDeployAr
 		},
 		{
 			Args: DispatchArgs{
-				TrackingID: job.Args.TrackingID,
-				OTXID:      limiterDeployOTXID,
-				RawTx:      rawLimiterDeployTxHex,
+				TrackingID:       job.Args.TrackingID,
+				OTXID:            limiterDeployOTXID,
+				RawTx:            rawLimiterDeployTxHex,
+				PredecessorOTXID: tokenIndexDeployOTXID,
 			},
 			InsertOpts: &river.InsertOpts{
 				Priority: 1,
@@ -584,9 +624,10 @@ func (w *PoolDeployWorker) Work(ctx context.Context, job *river.Job[PoolDeployAr
 		},
 		{
 			Args: DispatchArgs{
-				TrackingID: job.Args.TrackingID,
-				OTXID:      swapPoolDeployOTXID,
-				RawTx:      rawSwapPoolDeployTxHex,
+				TrackingID:       job.Args.TrackingID,
+				OTXID:            swapPoolDeployOTXID,
+				RawTx:            rawSwapPoolDeployTxHex,
+				PredecessorOTXID: limiterDeployOTXID,
 			},
 			InsertOpts: &river.InsertOpts{
 				Priority: 1,
@@ -594,9 +635,10 @@ func (w *PoolDeployWorker) Work(ctx context.Context, job *river.Job[PoolDeployAr
 		},
 		{
 			Args: DispatchArgs{
-				TrackingID: job.Args.TrackingID,
-				OTXID:      priceIndexQuoterDeployOTXID,
-				RawTx:      rawPriceIndexQuoterDeployTxHex,
+				TrackingID:       job.Args.TrackingID,
+				OTXID:            priceIndexQuoterDeployOTXID,
+				RawTx:            rawPriceIndexQuoterDeployTxHex,
+				PredecessorOTXID: swapPoolDeployOTXID,
 			},
 			InsertOpts: &river.InsertOpts{
 				Priority: 1,
@@ -604,9 +646,10 @@ func (w *PoolDeployWorker) Work(ctx context.Context, job *river.Job[PoolDeployAr
 		},
 		{
 			Args: DispatchArgs{
-				TrackingID: job.Args.TrackingID,
-				OTXID:      addToPoolIndexOTXID,
-				RawTx:      rawAddToPoolIndexTxHex,
+				TrackingID:       job.Args.TrackingID,
+				OTXID:            addToPoolIndexOTXID,
+				RawTx:            rawAddToPoolIndexTxHex,
+				PredecessorOTXID: priceIndexQuoterDeployOTXID,
 			},
 			InsertOpts: &river.InsertOpts{
 				Priority: 2,
@@ -614,9 +657,10 @@ func (w *PoolDeployWorker) Work(ctx context.Context, job *river.Job[PoolDeployAr
 		},
 		{
 			Args: DispatchArgs{
-				TrackingID: job.Args.TrackingID,
-				OTXID:      setQuoterOTXID,
-				RawTx:      rawSetQuoterTxHex,
+				TrackingID:       job.Args.TrackingID,
+				OTXID:            setQuoterOTXID,
+				RawTx:            rawSetQuoterTxHex,
+				PredecessorOTXID: addToPoolIndexOTXID,
 			},
 			InsertOpts: &river.InsertOpts{
 				Priority: 3,
@@ -624,9 +668,10 @@ func (w *PoolDeployWorker) Work(ctx context.Context, job *river.Job[PoolDeployAr
 		},
 		{
 			Args: DispatchArgs{
-				TrackingID: job.Args.TrackingID,
-				OTXID:      transferLimiterOwnershipOTXID,
-				RawTx:      rawTransferLimiterOwnershipTxHex,
+				TrackingID:       job.Args.TrackingID,
+				OTXID:            transferLimiterOwnershipOTXID,
+				RawTx:            rawTransferLimiterOwnershipTxHex,
+				PredecessorOTXID: setQuoterOTXID,
 			},
 			InsertOpts: &river.InsertOpts{
 				Priority: 4,
@@ -634,9 +679,10 @@ func (w *PoolDeployWorker) Work(ctx context.Context, job *river.Job[PoolDeployAr
 		},
 		{
 			Args: DispatchArgs{
-				TrackingID: job.Args.TrackingID,
-				OTXID:      transferTokenIndexOwnershipOTXID,
-				RawTx:      rawTransferTokenIndexOwnershipTxHex,
+				TrackingID:       job.Args.TrackingID,
+				OTXID:            transferTokenIndexOwnershipOTXID,
+				RawTx:            rawTransferTokenIndexOwnershipTxHex,
+				PredecessorOTXID: transferLimiterOwnershipOTXID,
 			},
 			InsertOpts: &river.InsertOpts{
 				Priority: 4,
@@ -644,9 +690,10 @@ func (w *PoolDeployWorker) Work(ctx context.Context, job *river.Job[PoolDeployAr
 		},
 		{
 			Args: DispatchArgs{
-				TrackingID: job.Args.TrackingID,
-				OTXID:      transferSwapPoolOwnershipOTXID,
-				RawTx:      rawTransferSwapPoolOwnershipTxHex,
+				TrackingID:       job.Args.TrackingID,
+				OTXID:            transferSwapPoolOwnershipOTXID,
+				RawTx:            rawTransferSwapPoolOwnershipTxHex,
+				PredecessorOTXID: transferTokenIndexOwnershipOTXID,
 			},
 			InsertOpts: &river.InsertOpts{
 				Priority: 4,
@@ -654,9 +701,10 @@ func (w *PoolDeployWorker) Work(ctx context.Context, job *river.Job[PoolDeployAr
 		},
 		{
 			Args: DispatchArgs{
-				TrackingID: job.Args.TrackingID,
-				OTXID:      transferPriceIndexQuoterOwnershipOTXID,
-				RawTx:      rawTransferPriceIndexQuoterOwnershipTxHex,
+				TrackingID:       job.Args.TrackingID,
+				OTXID:            transferPriceIndexQuoterOwnershipOTXID,
+				RawTx:            rawTransferPriceIndexQuoterOwnershipTxHex,
+				PredecessorOTXID: transferSwapPoolOwnershipOTXID,
 			},
 			InsertOpts: &river.InsertOpts{
 				Priority: 4,
@@ -5,7 +5,7 @@ import (
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
-	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/grassrootseconomics/eth-custodial/internal/gas"
 	"github.com/grassrootseconomics/eth-custodial/internal/store"
 	"github.com/grassrootseconomics/eth-custodial/pkg/event"
 	"github.com/grassrootseconomics/ethutils"
@@ -25,6 +25,7 @@ type (
 		ToTokenAddress   string `json:"toTokenAddress"`
 		PoolAddress      string `json:"poolAddress"`
 		Amount           string `json:"amount"`
+		FeeClass         string `json:"feeClass,omitempty"`
 	}
 
 	PoolSwapWorker struct {
@@ -42,42 +43,81 @@ func (w *PoolSwapWorker) Work(ctx context.Context, job *river.Job[PoolSwapArgs])
 	}
 	defer tx.Rollback(ctx)
 
-	keypair, err := w.wc.store.LoadPrivateKey(ctx, tx, job.Args.From)
+	resetApprovalInput, err := Abi[Approve].EncodeArgs(
+		ethutils.HexToAddress(job.Args.PoolAddress),
+		big.NewInt(0),
+	)
 	if err != nil {
 		return err
 	}
 
-	privateKey, err := crypto.HexToECDSA(keypair.Private)
+	bumpedApprovalAmount, err := StringToBigInt(job.Args.Amount, true)
 	if err != nil {
 		return err
 	}
 
-	gasSettings, err := w.wc.gasOracle.GetSettings()
+	setApprovalInput, err := Abi[Approve].EncodeArgs(
+		ethutils.HexToAddress(job.Args.PoolAddress),
+		bumpedApprovalAmount,
+	)
 	if err != nil {
 		return err
 	}
 
-	// Reset approval -> 0
-
-	resetApprovalNonce, err := w.wc.store.AcquireNonce(ctx, tx, job.Args.From)
+	amount, err := StringToBigInt(job.Args.Amount, false)
 	if err != nil {
 		return err
 	}
 
-	resetApprovalInput, err := Abi[Approve].EncodeArgs(
-		ethutils.HexToAddress(job.Args.PoolAddress),
-		big.NewInt(0),
+	input, err := Abi[Withdraw].EncodeArgs(
+		ethutils.HexToAddress(job.Args.ToTokenAddress),
+		ethutils.HexToAddress(job.Args.FromTokenAddress),
+		amount,
 	)
 	if err != nil {
 		return err
 	}
 
-	builtResetApprovalTx, err := w.wc.chainProvider.SignContractExecutionTx(privateKey, ethutils.ContractExecutionTxOpts{
+	if err := w.wc.preflight(ctx, tx, job.Args.TrackingID, store.POOL_SWAP, job.Args.From,
+		simulationCall{To: ethutils.HexToAddress(job.Args.FromTokenAddress), Input: resetApprovalInput},
+		simulationCall{To: ethutils.HexToAddress(job.Args.FromTokenAddress), Input: setApprovalInput},
+		simulationCall{To: ethutils.HexToAddress(job.Args.PoolAddress), Input: input},
+	); err != nil {
+		return err
+	}
+
+	gasSettings, err := w.wc.gasOracle.GetSettings(gas.ResolveFeeClass(job.Args.FeeClass, store.POOL_SWAP))
+	if err != nil {
+		return err
+	}
+
+	from := ethutils.HexToAddress(job.Args.From)
+	resetApprovalGasLimit, err := w.wc.gasLimitEstimator.Estimate(ctx, store.TOKEN_APPROVE, from, ethutils.HexToAddress(job.Args.FromTokenAddress), resetApprovalInput, nil)
+	if err != nil {
+		return err
+	}
+	setApprovalGasLimit, err := w.wc.gasLimitEstimator.Estimate(ctx, store.TOKEN_APPROVE, from, ethutils.HexToAddress(job.Args.FromTokenAddress), setApprovalInput, nil)
+	if err != nil {
+		return err
+	}
+	gasLimit, err := w.wc.gasLimitEstimator.Estimate(ctx, store.POOL_SWAP, from, ethutils.HexToAddress(job.Args.PoolAddress), input, nil)
+	if err != nil {
+		return err
+	}
+
+	// Reset approval -> 0
+
+	resetApprovalNonce, err := w.wc.store.AcquireNonce(ctx, tx, job.Args.From)
+	if err != nil {
+		return err
+	}
+
+	builtResetApprovalTx, err := w.wc.signContractExecutionTx(ctx, tx, job.Args.From, ethutils.ContractExecutionTxOpts{
 		ContractAddress: ethutils.HexToAddress(job.Args.FromTokenAddress),
 		InputData:       addDivviRefferalTag(w.wc.chainProvider, resetApprovalInput, ethutils.HexToAddress(job.Args.From)),
 		GasFeeCap:       gasSettings.GasFeeCap,
 		GasTipCap:       gasSettings.GasTipCap,
-		GasLimit:        gasSettings.GasLimit,
+		GasLimit:        resetApprovalGasLimit.Limit,
 		Nonce:           resetApprovalNonce,
 	})
 	if err != nil {
@@ -92,12 +132,14 @@ func (w *PoolSwapWorker) Work(ctx context.Context, job *river.Job[PoolSwapArgs])
 	rawResetApprovalTxHex := hexutil.Encode(rawResetApprovalTx)
 
 	resetApprovalOTXID, err := w.wc.store.InsertOTX(ctx, tx, store.OTX{
-		TrackingID:    job.Args.TrackingID,
-		OTXType:       store.TOKEN_APPROVE,
-		SignerAccount: job.Args.From,
-		RawTx:         rawResetApprovalTxHex,
-		TxHash:        builtResetApprovalTx.Hash().Hex(),
-		Nonce:         resetApprovalNonce,
+		TrackingID:     job.Args.TrackingID,
+		OTXType:        store.TOKEN_APPROVE,
+		SignerAccount:  job.Args.From,
+		RawTx:          rawResetApprovalTxHex,
+		TxHash:         builtResetApprovalTx.Hash().Hex(),
+		Nonce:          resetApprovalNonce,
+		GasLimit:       resetApprovalGasLimit.Limit,
+		GasLimitSource: resetApprovalGasLimit.Source,
 	})
 	if err != nil {
 		return err
@@ -121,25 +163,12 @@ func (w *PoolSwapWorker) Work(ctx context.Context, job *river.Job[PoolSwapArgs])
 		return err
 	}
 
-	bumpedApprovalAmount, err := StringToBigInt(job.Args.Amount, true)
-	if err != nil {
-		return err
-	}
-
-	setApprovalInput, err := Abi[Approve].EncodeArgs(
-		ethutils.HexToAddress(job.Args.PoolAddress),
-		bumpedApprovalAmount,
-	)
-	if err != nil {
-		return err
-	}
-
-	builtSetApprovalTx, err := w.wc.chainProvider.SignContractExecutionTx(privateKey, ethutils.ContractExecutionTxOpts{
+	builtSetApprovalTx, err := w.wc.signContractExecutionTx(ctx, tx, job.Args.From, ethutils.ContractExecutionTxOpts{
 		ContractAddress: ethutils.HexToAddress(job.Args.FromTokenAddress),
 		InputData:       addDivviRefferalTag(w.wc.chainProvider, setApprovalInput, ethutils.HexToAddress(job.Args.From)),
 		GasFeeCap:       gasSettings.GasFeeCap,
 		GasTipCap:       gasSettings.GasTipCap,
-		GasLimit:        gasSettings.GasLimit,
+		GasLimit:        setApprovalGasLimit.Limit,
 		Nonce:           setApprovalNonce,
 	})
 	if err != nil {
@@ -154,12 +183,14 @@ func (w *PoolSwapWorker) Work(ctx context.Context, job *river.Job[PoolSwapArgs])
 	rawSetApprovalTxHex := hexutil.Encode(rawSetApprovalTx)
 
 	setApprovalOTXID, err := w.wc.store.InsertOTX(ctx, tx, store.OTX{
-		TrackingID:    job.Args.TrackingID,
-		OTXType:       store.TOKEN_APPROVE,
-		SignerAccount: job.Args.From,
-		RawTx:         rawSetApprovalTxHex,
-		TxHash:        builtSetApprovalTx.Hash().Hex(),
-		Nonce:         setApprovalNonce,
+		TrackingID:     job.Args.TrackingID,
+		OTXType:        store.TOKEN_APPROVE,
+		SignerAccount:  job.Args.From,
+		RawTx:          rawSetApprovalTxHex,
+		TxHash:         builtSetApprovalTx.Hash().Hex(),
+		Nonce:          setApprovalNonce,
+		GasLimit:       setApprovalGasLimit.Limit,
+		GasLimitSource: setApprovalGasLimit.Source,
 	})
 	if err != nil {
 		return err
@@ -183,26 +214,12 @@ func (w *PoolSwapWorker) Work(ctx context.Context, job *river.Job[PoolSwapArgs])
 		return err
 	}
 
-	amount, err := StringToBigInt(job.Args.Amount, false)
-	if err != nil {
-		return err
-	}
-
-	input, err := Abi[Withdraw].EncodeArgs(
-		ethutils.HexToAddress(job.Args.ToTokenAddress),
-		ethutils.HexToAddress(job.Args.FromTokenAddress),
-		amount,
-	)
-	if err != nil {
-		return err
-	}
-
-	builtTx, err := w.wc.chainProvider.SignContractExecutionTx(privateKey, ethutils.ContractExecutionTxOpts{
+	builtTx, err := w.wc.signContractExecutionTx(ctx, tx, job.Args.From, ethutils.ContractExecutionTxOpts{
 		ContractAddress: ethutils.HexToAddress(job.Args.PoolAddress),
 		InputData:       addDivviRefferalTag(w.wc.chainProvider, input, ethutils.HexToAddress(job.Args.From)),
 		GasFeeCap:       gasSettings.GasFeeCap,
 		GasTipCap:       gasSettings.GasTipCap,
-		GasLimit:        gasSettings.GasLimit,
+		GasLimit:        gasLimit.Limit,
 		Nonce:           nonce,
 	})
 	if err != nil {
@@ -217,12 +234,14 @@ func (w *PoolSwapWorker) Work(ctx context.Context, job *river.Job[PoolSwapArgs])
 	rawTxHex := hexutil.Encode(rawTx)
 
 	otxID, err := w.wc.store.InsertOTX(ctx, tx, store.OTX{
-		TrackingID:    job.Args.TrackingID,
-		OTXType:       store.POOL_SWAP,
-		SignerAccount: job.Args.From,
-		RawTx:         rawTxHex,
-		TxHash:        builtTx.Hash().Hex(),
-		Nonce:         nonce,
+		TrackingID:     job.Args.TrackingID,
+		OTXType:        store.POOL_SWAP,
+		SignerAccount:  job.Args.From,
+		RawTx:          rawTxHex,
+		TxHash:         builtTx.Hash().Hex(),
+		Nonce:          nonce,
+		GasLimit:       gasLimit.Limit,
+		GasLimitSource: gasLimit.Source,
 	})
 	if err != nil {
 		return err
@@ -247,18 +266,20 @@ func (w *PoolSwapWorker) Work(ctx context.Context, job *river.Job[PoolSwapArgs])
 		},
 		{
 			Args: DispatchArgs{
-				TrackingID: job.Args.TrackingID,
-				OTXID:      setApprovalOTXID,
-				RawTx:      rawSetApprovalTxHex,
+				TrackingID:       job.Args.TrackingID,
+				OTXID:            setApprovalOTXID,
+				RawTx:            rawSetApprovalTxHex,
+				PredecessorOTXID: resetApprovalOTXID,
 			}, InsertOpts: &river.InsertOpts{
 				Priority: 2,
 			},
 		},
 		{
 			Args: DispatchArgs{
-				TrackingID: job.Args.TrackingID,
-				OTXID:      otxID,
-				RawTx:      rawTxHex,
+				TrackingID:       job.Args.TrackingID,
+				OTXID:            otxID,
+				RawTx:            rawTxHex,
+				PredecessorOTXID: setApprovalOTXID,
 			}, InsertOpts: &river.InsertOpts{
 				Priority: 3,
 			},
@@ -0,0 +1,130 @@
+package worker
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/grassrootseconomics/eth-custodial/internal/store"
+	"github.com/grassrootseconomics/eth-custodial/pkg/event"
+	"github.com/jackc/pgx/v5"
+)
+
+// IsReplaceable reports whether an OTX is the effective transaction of its nonce and can still be replaced at that
+// nonce, either by a cancellation or a speed-up.
+func IsReplaceable(otx *store.OTX) bool {
+	if otx.Replaced {
+		return false
+	}
+
+	switch otx.OTXType {
+	case store.GENERIC_SIGN, store.OTHER_MANUAL:
+		return false
+	}
+
+	return !store.FinalDispatchStatus(otx.DispatchStatus)
+}
+
+// replaceOTX re-signs originalTx at the same nonce with new fees as a new OTX linked to otx and queues its dispatch.
+// The original is marked replaced but kept so that whichever of the two is mined resolves the nonce. The replacement
+// still waits on the predecessor of otx within a multi transaction flow.
+func (wc *WorkerContainer) replaceOTX(ctx context.Context, tx pgx.Tx, otx *store.OTX, originalTx *types.Transaction, gasFeeCap *big.Int, gasTipCap *big.Int, retryAttempt int) (uint64, error) {
+	builtTx, err := wc.signTx(ctx, tx, otx.SignerAccount, &types.DynamicFeeTx{
+		Nonce:     originalTx.Nonce(),
+		To:        originalTx.To(),
+		Value:     originalTx.Value(),
+		Data:      originalTx.Data(),
+		Gas:       originalTx.Gas(),
+		GasFeeCap: gasFeeCap,
+		GasTipCap: gasTipCap,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	predecessorOTXID, err := wc.store.GetPredecessorOTXID(ctx, tx, otx.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	otxID, rawTxHex, err := wc.insertReplacementOTX(ctx, tx, otx, builtTx, store.PENDING)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := wc.queueClient.InsertTx(ctx, tx, DispatchArgs{
+		TrackingID:       otx.TrackingID,
+		OTXID:            otxID,
+		RawTx:            rawTxHex,
+		RetryAttempt:     retryAttempt,
+		PredecessorOTXID: predecessorOTXID,
+	}, nil); err != nil {
+		return 0, err
+	}
+
+	return otxID, nil
+}
+
+// insertReplacementOTX stores replacementTx as the new effective OTX of otx's nonce with the given dispatch status.
+// otx is kept as history of the lineage and marked replaced.
+func (wc *WorkerContainer) insertReplacementOTX(ctx context.Context, tx pgx.Tx, otx *store.OTX, replacementTx *types.Transaction, status string) (uint64, string, error) {
+	rawTx, err := replacementTx.MarshalBinary()
+	if err != nil {
+		return 0, "", err
+	}
+
+	rawTxHex := hexutil.Encode(rawTx)
+
+	otxID, err := wc.store.InsertOTX(ctx, tx, store.OTX{
+		TrackingID:    otx.TrackingID,
+		OTXType:       otx.OTXType,
+		SignerAccount: otx.SignerAccount,
+		RawTx:         rawTxHex,
+		TxHash:        replacementTx.Hash().Hex(),
+		Nonce:         otx.Nonce,
+		ReplacesOTXID: &otx.ID,
+	})
+	if err != nil {
+		return 0, "", err
+	}
+
+	if err := wc.store.MarkOTXReplaced(ctx, tx, otx.ID); err != nil {
+		return 0, "", err
+	}
+
+	if err := wc.store.InsertDispatchTx(ctx, tx, store.DispatchTx{
+		OTXID:  otxID,
+		Status: status,
+	}); err != nil {
+		return 0, "", err
+	}
+
+	return otxID, rawTxHex, nil
+}
+
+// resolveReplacements must be called once otxID is mined. It publishes the status of OTXs of other tracking ids that
+// lost the race for the same nonce, e.g. the original of a cancellation.
+func (wc *WorkerContainer) resolveReplacements(ctx context.Context, tx pgx.Tx, otxID uint64) error {
+	resolved, err := wc.store.ResolveReplacements(ctx, tx, otxID)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range resolved {
+		wc.pub.Send(ctx, event.Event{
+			TrackingID: v.TrackingID,
+			Status:     v.Status,
+		})
+	}
+
+	return nil
+}
+
+func bigMax(a *big.Int, b *big.Int) *big.Int {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+
+	return b
+}
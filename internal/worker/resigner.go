@@ -1,7 +1,6 @@
 package worker
 
 import (
-	"errors"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -23,35 +22,18 @@ func decodeTx(signedTxHash string) (*types.Transaction, error) {
 	return tx, nil
 }
 
-func bumpGas(signedTxHash string) (ethutils.ContractExecutionTxOpts, error) {
-	originalTx, err := decodeTx(signedTxHash)
-	if err != nil {
-		return ethutils.ContractExecutionTxOpts{}, err
-	}
-
-	// In eth-custodial, we always sign with type 2 (dynamic fee) transactions
-	if originalTx.Type() != types.DynamicFeeTxType {
-		return ethutils.ContractExecutionTxOpts{}, errors.New("tx is not a dynamic fee transaction")
-	}
-
-	// Bump the gas price by 15%
-	originalGasPrice := originalTx.GasFeeCap()
+// bumpFees raises both the fee cap and the tip by 15%. Nodes only accept a replacement at the same nonce if both are
+// raised by at least 10%.
+func bumpFees(originalTx *types.Transaction) (*big.Int, *big.Int) {
 	bumpMultiplier := big.NewInt(115)
-	bumped := new(big.Int).Mul(originalGasPrice, bumpMultiplier)
-	bumped.Div(bumped, big.NewInt(100))
 
-	if originalTx.To() == nil {
-		return ethutils.ContractExecutionTxOpts{}, errors.New("tx has no recipient (is contract creation)")
-	}
+	gasFeeCap := new(big.Int).Mul(originalTx.GasFeeCap(), bumpMultiplier)
+	gasFeeCap.Div(gasFeeCap, big.NewInt(100))
+
+	gasTipCap := new(big.Int).Mul(originalTx.GasTipCap(), bumpMultiplier)
+	gasTipCap.Div(gasTipCap, big.NewInt(100))
 
-	return ethutils.ContractExecutionTxOpts{
-		ContractAddress: *originalTx.To(),
-		InputData:       originalTx.Data(),
-		GasFeeCap:       bumped,
-		GasTipCap:       originalTx.GasTipCap(),
-		GasLimit:        originalTx.Gas(),
-		Nonce:           originalTx.Nonce(),
-	}, nil
+	return gasFeeCap, gasTipCap
 }
 
 func noopTx(gasFeeCap *big.Int, gasTipCap *big.Int, nonce uint64) ethutils.GasTransferTxOpts {
@@ -2,15 +2,32 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/google/uuid"
+	"github.com/grassrootseconomics/eth-custodial/internal/gas"
+	"github.com/grassrootseconomics/eth-custodial/internal/rpcerror"
 	"github.com/grassrootseconomics/eth-custodial/internal/store"
+	"github.com/grassrootseconomics/eth-custodial/pkg/event"
+	"github.com/jackc/pgx/v5"
+	"github.com/lmittmann/w3"
+	"github.com/lmittmann/w3/module/eth"
 	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
 )
 
 type (
 	RetrierArgs struct {
 		TrackingID string `json:"trackingId"`
+		OTXID      uint64 `json:"otxId"`
+		Attempt    int    `json:"attempt"`
+		// RefillTrackingID and RefillJobID are set once a gas refill has been requested for a NO_GAS dispatch.
+		RefillTrackingID string `json:"refillTrackingId,omitempty"`
+		RefillJobID      int64  `json:"refillJobId,omitempty"`
 	}
 
 	RetrierWorker struct {
@@ -19,10 +36,64 @@ type (
 	}
 )
 
-const RetrierID = "RETRIER"
+const (
+	RetrierID = "RETRIER"
+
+	// maxRetrierAttempts bounds the remediations per tracking id so that a persistently failing OTX doesn't cascade
+	// into an endless chain of bumps and refills. Past the bound the OTX is left to the unlocker.
+	maxRetrierAttempts = 5
+	retrierBaseBackoff = 30 * time.Second
+)
 
 func (RetrierArgs) Kind() string { return RetrierID }
 
+// retrierBackoff doubles the delay with every attempt: 30s, 1m, 2m, 4m, 8m.
+func retrierBackoff(attempt int) time.Duration {
+	return retrierBaseBackoff << attempt
+}
+
+// scheduleRetrier queues a remediation for the OTX after its backoff, or records that the retrier gave up once the
+// attempts are exhausted.
+func (wc *WorkerContainer) scheduleRetrier(ctx context.Context, args RetrierArgs, dispatchStatus string) error {
+	tx, err := wc.store.Pool().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := wc.scheduleRetrierTx(ctx, tx, args, dispatchStatus); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (wc *WorkerContainer) scheduleRetrierTx(ctx context.Context, tx pgx.Tx, args RetrierArgs, dispatchStatus string) error {
+	if args.Attempt >= maxRetrierAttempts {
+		wc.logg.Warn("retrier: attempts exhausted, leaving otx to the unlocker", "otx_id", args.OTXID, "attempt", args.Attempt)
+		if err := wc.store.InsertRetrierAction(ctx, tx, store.RetrierAction{
+			OTXID:          args.OTXID,
+			Attempt:        args.Attempt,
+			DispatchStatus: dispatchStatus,
+			Action:         store.RETRY_GAVE_UP,
+			Detail:         "attempts exhausted",
+		}); err != nil {
+			return err
+		}
+
+		return wc.abortSuccessors(ctx, tx, args.TrackingID, args.OTXID)
+	}
+
+	_, err := wc.queueClient.InsertTx(ctx, tx, args, &river.InsertOpts{
+		MaxAttempts: 3,
+		ScheduledAt: time.Now().Add(retrierBackoff(args.Attempt)),
+		UniqueOpts: river.UniqueOpts{
+			ByArgs: true,
+		},
+	})
+	return err
+}
+
 func (w *RetrierWorker) Work(ctx context.Context, job *river.Job[RetrierArgs]) error {
 	tx, err := w.wc.store.Pool().Begin(ctx)
 	if err != nil {
@@ -30,41 +101,241 @@ func (w *RetrierWorker) Work(ctx context.Context, job *river.Job[RetrierArgs]) e
 	}
 	defer tx.Rollback(ctx)
 
-	otx, err := w.wc.store.GetOTXByTrackingID(ctx, tx, job.Args.TrackingID)
+	otxs, err := w.wc.store.GetOTXByTrackingID(ctx, tx, job.Args.TrackingID)
 	if err != nil {
 		return err
 	}
 
-	if len(otx) < 1 {
-		return fmt.Errorf("retrier: no otx found for tracking id %s", job.Args.TrackingID)
+	var otx *store.OTX
+	for _, v := range otxs {
+		if v.ID == job.Args.OTXID {
+			otx = v
+			break
+		}
+	}
+	if otx == nil {
+		return fmt.Errorf("retrier: otx %d not found for tracking id %s", job.Args.OTXID, job.Args.TrackingID)
 	}
 
-	for _, v := range otx {
-		if !isChainError(v.DispatchStatus) {
-			w.wc.logg.Debug("retrier: skipping non-chain error withing otx chain sequence", "otx_id", v.ID, "status", v.DispatchStatus)
-			break
+	if !IsReplaceable(otx) {
+		w.wc.logg.Debug("retrier: otx already resolved", "otx_id", otx.ID, "status", otx.DispatchStatus)
+		return nil
+	}
+
+	switch rpcerror.StatusAction(otx.DispatchStatus) {
+	case rpcerror.ActionRefillGas:
+		err = w.handleNoGas(ctx, tx, job.Args, otx)
+	case rpcerror.ActionBumpFees:
+		err = w.handleLowGasPrice(ctx, tx, job.Args, otx)
+	case rpcerror.ActionReconcileNonce:
+		err = w.handleLowNonce(ctx, tx, job.Args, otx)
+	default:
+		w.wc.logg.Debug("retrier: skipping non-chain error", "otx_id", otx.ID, "status", otx.DispatchStatus)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// handleNoGas requests a gas refill for the signer on the first run and re-dispatches the unchanged OTX once the
+// refill is mined.
+func (w *RetrierWorker) handleNoGas(ctx context.Context, tx pgx.Tx, args RetrierArgs, otx *store.OTX) error {
+	if args.RefillTrackingID == "" {
+		w.wc.logg.Warn("retrier: NO_GAS, requesting gas refill", "otx_id", otx.ID, "account", otx.SignerAccount)
+		refillTrackingID := uuid.NewString()
+
+		refillJob, err := w.wc.queueClient.InsertTx(ctx, tx, GasRefillArgs{
+			TrackingID: refillTrackingID,
+			Address:    otx.SignerAccount,
+		}, nil)
+		if err != nil {
+			return err
 		}
 
-		switch v.DispatchStatus {
-		case store.NO_GAS:
-			w.wc.logg.Warn("retrier: encountered NO_GAS error during dispatch attempting to topup account", "account", v.SignerAccount)
-			return nil
-		case store.LOW_GAS_PRICE, store.REPLACEMENT_UNDERPRICED:
-			w.wc.logg.Warn("retrier: encountered low gas error during dispatch attempting to bump gas", "account", v.SignerAccount, "reason", v.DispatchStatus)
-			break
-		case store.LOW_NONCE:
-			w.wc.logg.Error("retrier: encountered low nonce error during dispatch", "account", v.SignerAccount)
-			return nil
+		if err := w.recordAction(ctx, tx, args, otx, store.RETRY_GAS_REFILL_REQUESTED, refillTrackingID); err != nil {
+			return err
+		}
+
+		next := args
+		next.Attempt++
+		next.RefillTrackingID = refillTrackingID
+		next.RefillJobID = refillJob.Job.ID
+		return w.wc.scheduleRetrierTx(ctx, tx, next, otx.DispatchStatus)
+	}
+
+	refillOTXs, err := w.wc.store.GetOTXByTrackingID(ctx, tx, args.RefillTrackingID)
+	if err != nil {
+		return err
+	}
+
+	if len(refillOTXs) > 0 {
+		refillStatus := refillOTXs[len(refillOTXs)-1].DispatchStatus
+		switch refillStatus {
+		case store.SUCCESS, store.CONFIRMED:
+		case store.PENDING, store.IN_NETWORK:
+			return w.awaitGasRefill(ctx, tx, args, otx, refillStatus)
+		default:
+			return w.recordAction(ctx, tx, args, otx, store.RETRY_GAVE_UP, "gas refill "+refillStatus)
 		}
+	} else if args.RefillJobID != 0 {
+		// Without an OTX the refill job is either yet to run or has completed because the gas faucet refused the
+		// refill, e.g. the account is still within its cooldown. The account could have been topped up externally in
+		// the meantime, so a re-dispatch after a refusal is still worth a try. Finalized jobs that were already pruned
+		// are treated as completed.
+		refillJob, err := w.wc.queueClient.JobGetTx(ctx, tx, args.RefillJobID)
+		if err != nil && !errors.Is(err, river.ErrNotFound) {
+			return err
+		}
+		if refillJob != nil {
+			switch refillJob.State {
+			case rivertype.JobStateCompleted:
+			case rivertype.JobStateCancelled, rivertype.JobStateDiscarded:
+				return w.recordAction(ctx, tx, args, otx, store.RETRY_GAVE_UP, "gas refill job "+string(refillJob.State))
+			default:
+				return w.awaitGasRefill(ctx, tx, args, otx, string(refillJob.State))
+			}
+		}
+	}
+
+	if err := w.wc.store.UpdateDispatchTxStatus(ctx, tx, store.DispatchTx{
+		OTXID:  otx.ID,
+		Status: store.PENDING,
+	}); err != nil {
+		return err
+	}
+
+	if _, err := w.wc.queueClient.InsertTx(ctx, tx, DispatchArgs{
+		TrackingID:   otx.TrackingID,
+		OTXID:        otx.ID,
+		RawTx:        otx.RawTx,
+		RetryAttempt: args.Attempt + 1,
+	}, nil); err != nil {
+		return err
+	}
+
+	w.wc.pub.Send(ctx, event.Event{
+		TrackingID: otx.TrackingID,
+		Status:     store.PENDING,
+	})
+
+	return w.recordAction(ctx, tx, args, otx, store.RETRY_REDISPATCHED, "")
+}
+
+// awaitGasRefill checks the NO_GAS dispatch again on the next attempt while the gas refill is still in flight.
+func (w *RetrierWorker) awaitGasRefill(ctx context.Context, tx pgx.Tx, args RetrierArgs, otx *store.OTX, refillStatus string) error {
+	if err := w.recordAction(ctx, tx, args, otx, store.RETRY_AWAITING_GAS_REFILL, refillStatus); err != nil {
+		return err
+	}
+
+	next := args
+	next.Attempt++
+	return w.wc.scheduleRetrierTx(ctx, tx, next, otx.DispatchStatus)
+}
+
+// handleLowGasPrice re-signs the OTX at the same nonce with bumped fees, never below the current oracle settings.
+func (w *RetrierWorker) handleLowGasPrice(ctx context.Context, tx pgx.Tx, args RetrierArgs, otx *store.OTX) error {
+	originalTx, err := decodeTx(otx.RawTx)
+	if err != nil {
+		return err
+	}
+
+	gasSettings, err := w.wc.gasOracle.GetSettings(gas.DefaultFeeClass(otx.OTXType))
+	if err != nil {
+		return err
+	}
+
+	gasFeeCap, gasTipCap := bumpFees(originalTx)
+	gasFeeCap = bigMax(gasFeeCap, gasSettings.GasFeeCap)
+	gasTipCap = bigMax(gasTipCap, gasSettings.GasTipCap)
+
+	if w.wc.maxGasFeeCap != nil && gasFeeCap.Cmp(w.wc.maxGasFeeCap) > 0 {
+		w.wc.logg.Warn("retrier: bumped fee above maximum", "otx_id", otx.ID, "gas_fee_cap", gasFeeCap)
+		return w.recordAction(ctx, tx, args, otx, store.RETRY_GAVE_UP, "fee cap "+gasFeeCap.String()+" above maximum")
+	}
+
+	otxID, err := w.wc.replaceOTX(ctx, tx, otx, originalTx, gasFeeCap, gasTipCap, args.Attempt+1)
+	if err != nil {
+		return err
+	}
+
+	w.wc.pub.Send(ctx, event.Event{
+		TrackingID: otx.TrackingID,
+		Status:     store.PENDING,
+	})
+
+	return w.recordAction(ctx, tx, args, otx, store.RETRY_FEE_BUMPED, fmt.Sprintf("otx %d fee cap %s tip %s", otxID, gasFeeCap, gasTipCap))
+}
+
+// handleLowNonce reconciles the status from the receipt. A missing receipt means another transaction consumed the
+// nonce, which the unlocker recovers by scanning blocks.
+func (w *RetrierWorker) handleLowNonce(ctx context.Context, tx pgx.Tx, args RetrierArgs, otx *store.OTX) error {
+	var receipt *types.Receipt
+	if err := w.wc.rpc.CallCtx(
+		ctx,
+		eth.TxReceipt(common.HexToHash(otx.TxHash)).Returns(&receipt),
+	); isNotFound(err) {
+		return w.recordAction(ctx, tx, args, otx, store.RETRY_NONCE_CONSUMED, "")
+	} else if err != nil {
+		return err
+	}
+
+	status := store.REVERTED
+	if receipt.Status == types.ReceiptStatusSuccessful {
+		status = store.SUCCESS
+	}
+
+	if err := w.wc.store.UpdateDispatchTxStatus(ctx, tx, store.DispatchTx{
+		OTXID:  otx.ID,
+		Status: status,
+	}); err != nil {
+		return err
+	}
+
+	if err := w.wc.resolveReplacements(ctx, tx, otx.ID); err != nil {
+		return err
+	}
+
+	w.wc.pub.Send(ctx, event.Event{
+		TrackingID: otx.TrackingID,
+		Status:     status,
+	})
+
+	return w.recordAction(ctx, tx, args, otx, store.RETRY_RECEIPT_RECONCILED, status)
+}
+
+// recordAction stores the remediation of an attempt. Once the OTX can no longer be remediated, the remaining steps of
+// its flow that are waiting on it are aborted.
+func (w *RetrierWorker) recordAction(ctx context.Context, tx pgx.Tx, args RetrierArgs, otx *store.OTX, action string, detail string) error {
+	w.wc.logg.Info("retrier: remediation", "otx_id", otx.ID, "attempt", args.Attempt, "status", otx.DispatchStatus, "action", action)
+
+	if err := w.wc.store.InsertRetrierAction(ctx, tx, store.RetrierAction{
+		OTXID:          otx.ID,
+		Attempt:        args.Attempt,
+		DispatchStatus: otx.DispatchStatus,
+		Action:         action,
+		Detail:         detail,
+	}); err != nil {
+		return err
+	}
+
+	switch action {
+	case store.RETRY_GAVE_UP, store.RETRY_NONCE_CONSUMED:
+		return w.wc.abortSuccessors(ctx, tx, otx.TrackingID, otx.ID)
 	}
 
 	return nil
 }
 
-func isChainError(status string) bool {
-	switch status {
-	case store.NO_GAS, store.LOW_GAS_PRICE, store.REPLACEMENT_UNDERPRICED, store.LOW_NONCE:
-		return true
+// isNotFound reports whether an RPC call returned null, w3 doesn't export the error it returns in that case. A single
+// call still wraps the error in w3.CallErrors.
+func isNotFound(err error) bool {
+	var callErrs w3.CallErrors
+	if errors.As(err, &callErrs) && len(callErrs) == 1 {
+		err = callErrs[0]
 	}
-	return false
+
+	return err != nil && err.Error() == "not found"
 }
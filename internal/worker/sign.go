@@ -0,0 +1,73 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/grassrootseconomics/ethutils"
+	"github.com/jackc/pgx/v5"
+	"github.com/riverqueue/river"
+)
+
+var ErrAccountNotActive = errors.New("account is not active")
+
+// gasTransferGasLimit matches ethutils.Provider.SignGasTransferTx.
+const gasTransferGasLimit = 25_000
+
+// The helpers below mirror the ethutils.Provider Sign* functions but delegate signing to the configured signer
+// backend so that workers never handle raw private keys.
+
+func (wc *WorkerContainer) signContractExecutionTx(ctx context.Context, dbTx pgx.Tx, from string, txData ethutils.ContractExecutionTxOpts) (*types.Transaction, error) {
+	return wc.signTx(ctx, dbTx, from, &types.DynamicFeeTx{
+		To:        &txData.ContractAddress,
+		Nonce:     txData.Nonce,
+		Data:      txData.InputData,
+		Gas:       txData.GasLimit,
+		GasFeeCap: txData.GasFeeCap,
+		GasTipCap: txData.GasTipCap,
+	})
+}
+
+func (wc *WorkerContainer) signContractPublishTx(ctx context.Context, dbTx pgx.Tx, from string, txData ethutils.ContractPublishTxOpts) (*types.Transaction, error) {
+	return wc.signTx(ctx, dbTx, from, &types.DynamicFeeTx{
+		Data:      txData.ContractByteCode,
+		Nonce:     txData.Nonce,
+		Gas:       txData.GasLimit,
+		GasFeeCap: txData.GasFeeCap,
+		GasTipCap: txData.GasTipCap,
+	})
+}
+
+func (wc *WorkerContainer) signGasTransferTx(ctx context.Context, dbTx pgx.Tx, from string, txData ethutils.GasTransferTxOpts) (*types.Transaction, error) {
+	return wc.signTx(ctx, dbTx, from, &types.DynamicFeeTx{
+		To:        &txData.To,
+		Value:     txData.Value,
+		Data:      txData.InputData,
+		Nonce:     txData.Nonce,
+		Gas:       gasTransferGasLimit,
+		GasFeeCap: txData.GasFeeCap,
+		GasTipCap: txData.GasTipCap,
+	})
+}
+
+// signTx refuses to sign for accounts that are not active. The job is cancelled rather than retried since the account
+// status only changes through manual intervention.
+func (wc *WorkerContainer) signTx(ctx context.Context, dbTx pgx.Tx, from string, txData types.TxData) (*types.Transaction, error) {
+	active, err := wc.store.CheckKeypair(ctx, dbTx, from)
+	if err != nil {
+		return nil, err
+	}
+	if !active {
+		return nil, river.JobCancel(fmt.Errorf("%w: %s", ErrAccountNotActive, from))
+	}
+
+	return wc.signer.SignTx(ctx, dbTx, ethutils.HexToAddress(from), types.NewTx(txData))
+}
+
+// assignSystemSigner picks a system signer from the pool for a job. Jobs that sign multiple transactions must call it
+// once and reuse the address so that the whole flow stays on a single signer and nonce sequence.
+func (wc *WorkerContainer) assignSystemSigner(ctx context.Context, dbTx pgx.Tx) (string, error) {
+	return wc.store.NextSystemSigner(ctx, dbTx, wc.systemSignerStrategy)
+}
@@ -0,0 +1,228 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/grassrootseconomics/eth-custodial/internal/store"
+	"github.com/grassrootseconomics/eth-custodial/pkg/event"
+	"github.com/grassrootseconomics/ethutils"
+	"github.com/jackc/pgx/v5"
+	"github.com/lmittmann/w3"
+	"github.com/lmittmann/w3/module/eth"
+	"github.com/lmittmann/w3/w3types"
+	"github.com/riverqueue/river"
+)
+
+type (
+	// simulationCall is a transaction a worker is about to sign.
+	simulationCall struct {
+		To    common.Address
+		Input []byte
+		Value *big.Int
+	}
+
+	// SimulationError is a simulated call that reverted.
+	SimulationError struct {
+		To           common.Address
+		Input        []byte
+		RevertReason string
+	}
+
+	// simulateV1Caller runs several calls on top of each other with eth_simulateV1 so that later steps of a flow see
+	// the state changes of the earlier ones, e.g. a swap after its approval.
+	simulateV1Caller struct {
+		from    common.Address
+		calls   []simulationCall
+		results *[]simulateV1CallResult
+	}
+
+	simulateV1CallResult struct {
+		Status hexutil.Uint64 `json:"status"`
+		Error  *struct {
+			Message string `json:"message"`
+			Data    string `json:"data"`
+		} `json:"error"`
+	}
+)
+
+const evmRevertErrCode = 3
+
+// pendingBlock simulates on top of the transactions already in the node's pool.
+var pendingBlock = big.NewInt(-1)
+
+func (e *SimulationError) Error() string {
+	return fmt.Sprintf("eth-custodial: simulation of call to %s reverted: %s", e.To.Hex(), e.RevertReason)
+}
+
+// preflight simulates calls from the signer at the pending block when simulation is enabled. It runs before the nonce
+// is acquired, so that a failing simulation leaves no gap. A reverted call is recorded as SIMULATION_FAILED and tx is
+// committed before the job is cancelled. Any returned error must be returned by the worker as is.
+func (wc *WorkerContainer) preflight(ctx context.Context, tx pgx.Tx, trackingID string, otxType string, from string, calls ...simulationCall) error {
+	if !wc.simulate {
+		return nil
+	}
+
+	err := wc.simulateCalls(ctx, ethutils.HexToAddress(from), calls...)
+	var simErr *SimulationError
+	if !errors.As(err, &simErr) {
+		return err
+	}
+
+	wc.logg.Warn("simulation reverted", "tracking_id", trackingID, "otx_type", otxType, "to", simErr.To.Hex(), "revert_reason", simErr.RevertReason)
+	if err := wc.store.InsertSimulationFailure(ctx, tx, store.SimulationFailure{
+		TrackingID:      trackingID,
+		OTXType:         otxType,
+		SignerAccount:   from,
+		ContractAddress: simErr.To.Hex(),
+		Input:           hexutil.Encode(simErr.Input),
+		RevertReason:    simErr.RevertReason,
+	}); err != nil {
+		return err
+	}
+	wc.pub.Send(ctx, event.Event{
+		TrackingID: trackingID,
+		Status:     store.SIMULATION_FAILED,
+	})
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	return river.JobCancel(simErr)
+}
+
+// simulateCalls returns a *SimulationError for the first call that reverts. Node errors other than a revert, e.g. a
+// node without eth_simulateV1, don't block signing and only the first call is simulated. Transport errors are
+// returned so that the job is retried.
+func (wc *WorkerContainer) simulateCalls(ctx context.Context, from common.Address, calls ...simulationCall) error {
+	if len(calls) > 1 {
+		var results []simulateV1CallResult
+
+		err := wc.rpc.CallCtx(ctx, &simulateV1Caller{from: from, calls: calls, results: &results})
+		if err == nil {
+			for i, v := range results {
+				if i < len(calls) && v.Status == 0 {
+					reason := "execution reverted"
+					if v.Error != nil {
+						reason = revertReason(v.Error.Message, v.Error.Data)
+					}
+					return &SimulationError{To: calls[i].To, Input: calls[i].Input, RevertReason: reason}
+				}
+			}
+			return nil
+		}
+
+		var callErrs w3.CallErrors
+		if !errors.As(err, &callErrs) {
+			return err
+		}
+		wc.logg.Debug("eth_simulateV1 unavailable, simulating the first call only", "error", callErrs[0])
+	}
+
+	var output []byte
+	err := wc.rpc.CallCtx(ctx, eth.Call(&w3types.Message{
+		From:  from,
+		To:    &calls[0].To,
+		Input: calls[0].Input,
+		Value: calls[0].Value,
+	}, pendingBlock, nil).Returns(&output))
+	// Calls to an account without code return empty output, which w3 reports as not found.
+	if isNotFound(err) {
+		return nil
+	}
+
+	var callErrs w3.CallErrors
+	if errors.As(err, &callErrs) {
+		if !isRevert(callErrs[0]) {
+			wc.logg.Warn("simulation skipped", "to", calls[0].To.Hex(), "error", callErrs[0])
+			return nil
+		}
+
+		var data string
+		var dataErr rpc.DataError
+		if errors.As(callErrs[0], &dataErr) {
+			data, _ = dataErr.ErrorData().(string)
+		}
+		return &SimulationError{To: calls[0].To, Input: calls[0].Input, RevertReason: revertReason(callErrs[0].Error(), data)}
+	}
+
+	return err
+}
+
+func isRevert(err error) bool {
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == evmRevertErrCode {
+		return true
+	}
+	return strings.Contains(strings.ToLower(err.Error()), "revert")
+}
+
+// revertReason decodes an Error(string) revert, custom errors are returned as their raw data.
+func revertReason(message string, data string) string {
+	revertData, err := hexutil.Decode(data)
+	if err != nil || len(revertData) == 0 {
+		return message
+	}
+
+	if reason, err := abi.UnpackRevert(revertData); err == nil {
+		return reason
+	}
+	return data
+}
+
+func (c *simulateV1Caller) CreateRequest() (rpc.BatchElem, error) {
+	type call struct {
+		From  common.Address `json:"from"`
+		To    common.Address `json:"to"`
+		Input hexutil.Bytes  `json:"input"`
+		Value *hexutil.Big   `json:"value,omitempty"`
+	}
+
+	calls := make([]call, len(c.calls))
+	for i, v := range c.calls {
+		calls[i] = call{
+			From:  c.from,
+			To:    v.To,
+			Input: v.Input,
+			Value: (*hexutil.Big)(v.Value),
+		}
+	}
+
+	return rpc.BatchElem{
+		Method: "eth_simulateV1",
+		Args: []any{
+			map[string]any{
+				"blockStateCalls": []map[string]any{{"calls": calls}},
+			},
+			"pending",
+		},
+		Result: &json.RawMessage{},
+	}, nil
+}
+
+func (c *simulateV1Caller) HandleResponse(elem rpc.BatchElem) error {
+	if elem.Error != nil {
+		return elem.Error
+	}
+
+	var blocks []struct {
+		Calls []simulateV1CallResult `json:"calls"`
+	}
+	if err := json.Unmarshal(*elem.Result.(*json.RawMessage), &blocks); err != nil {
+		return err
+	}
+	if len(blocks) != 1 {
+		return fmt.Errorf("eth_simulateV1: expected 1 block, got %d", len(blocks))
+	}
+
+	*c.results = blocks[0].Calls
+	return nil
+}
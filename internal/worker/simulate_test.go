@@ -0,0 +1,39 @@
+package worker
+
+import "testing"
+
+func TestRevertReason(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		data    string
+		want    string
+	}{
+		{
+			name:    "error string",
+			message: "execution reverted: ERR_LIMIT",
+			// Error("ERR_LIMIT")
+			data: "0x08c379a0000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000000094552525f4c494d49540000000000000000000000000000000000000000000000",
+			want: "ERR_LIMIT",
+		},
+		{
+			name:    "custom error",
+			message: "execution reverted",
+			data:    "0x82b42900",
+			want:    "0x82b42900",
+		},
+		{
+			name:    "no data",
+			message: "execution reverted",
+			data:    "",
+			want:    "execution reverted",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := revertReason(tt.message, tt.data); got != tt.want {
+				t.Errorf("revertReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
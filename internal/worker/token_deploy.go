@@ -7,6 +7,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/grassrootseconomics/eth-custodial/internal/gas"
 	"github.com/grassrootseconomics/eth-custodial/internal/store"
 	"github.com/grassrootseconomics/eth-custodial/pkg/event"
 	"github.com/grassrootseconomics/ethutils"
@@ -24,6 +25,7 @@ type (
 		InitialMintee   string `json:"initialMintee"`
 		Owner           string `json:"owner"`
 		ExpiryTimestamp string `json:"expiryTimestamp,omitempty"`
+		FeeClass        string `json:"feeClass,omitempty"`
 	}
 
 	TokenDeployWorker struct {
@@ -42,22 +44,17 @@ func (w *TokenDeployWorker) Work(ctx context.Context, job *river.Job[TokenDeploy
 	}
 	defer tx.Rollback(ctx)
 
-	systemKeypair, err := w.wc.store.LoadMasterSignerKey(ctx, tx)
+	systemAddress, err := w.wc.assignSystemSigner(ctx, tx)
 	if err != nil {
 		return err
 	}
 
-	privateKey, err := crypto.HexToECDSA(systemKeypair.Private)
+	gasSettings, err := w.wc.gasOracle.GetSettings(gas.ResolveFeeClass(job.Args.FeeClass, store.STANDARD_TOKEN_DEPLOY))
 	if err != nil {
 		return err
 	}
 
-	gasSettings, err := w.wc.gasOracle.GetSettings()
-	if err != nil {
-		return err
-	}
-
-	nonce, err := w.wc.store.AcquireNonce(ctx, tx, systemKeypair.Public)
+	nonce, err := w.wc.store.AcquireNonce(ctx, tx, systemAddress)
 	if err != nil {
 		return err
 	}
@@ -86,8 +83,8 @@ func (w *TokenDeployWorker) Work(ctx context.Context, job *river.Job[TokenDeploy
 		return err
 	}
 
-	builtContractDeployTx, err := w.wc.chainProvider.SignContractPublishTx(privateKey, ethutils.ContractPublishTxOpts{
-		ContractByteCode: addDivviRefferalTag(w.wc.chainProvider, byteCode, ethutils.HexToAddress(systemKeypair.Public)),
+	builtContractDeployTx, err := w.wc.signContractPublishTx(ctx, tx, systemAddress, ethutils.ContractPublishTxOpts{
+		ContractByteCode: addDivviRefferalTag(w.wc.chainProvider, byteCode, ethutils.HexToAddress(systemAddress)),
 		GasFeeCap:        gasSettings.GasFeeCap,
 		GasTipCap:        gasSettings.GasTipCap,
 		GasLimit:         contract.MaxGasLimit(),
@@ -107,7 +104,7 @@ func (w *TokenDeployWorker) Work(ctx context.Context, job *river.Job[TokenDeploy
 	deployContractOTXID, err := w.wc.store.InsertOTX(ctx, tx, store.OTX{
 		TrackingID:    job.Args.TrackingID,
 		OTXType:       otxType,
-		SignerAccount: systemKeypair.Public,
+		SignerAccount: systemAddress,
 		RawTx:         rawContractDeployTxHex,
 		TxHash:        builtContractDeployTx.Hash().Hex(),
 		Nonce:         nonce,
@@ -127,24 +124,29 @@ func (w *TokenDeployWorker) Work(ctx context.Context, job *river.Job[TokenDeploy
 		Status:     store.PENDING,
 	})
 
-	contractAddress := crypto.CreateAddress(common.HexToAddress(systemKeypair.Public), nonce)
+	contractAddress := crypto.CreateAddress(common.HexToAddress(systemAddress), nonce)
 
 	addData, err := Abi[Add].EncodeArgs(contractAddress)
 	if err != nil {
 		return err
 	}
 
-	addNonce, err := w.wc.store.AcquireNonce(ctx, tx, systemKeypair.Public)
+	addNonce, err := w.wc.store.AcquireNonce(ctx, tx, systemAddress)
 	if err != nil {
 		return err
 	}
 
-	builtAddTx, err := w.wc.chainProvider.SignContractExecutionTx(privateKey, ethutils.ContractExecutionTxOpts{
+	addGasLimit, err := w.wc.gasLimitEstimator.Estimate(ctx, store.TOKEN_INDEX_ADD, ethutils.HexToAddress(systemAddress), w.tokenIndex, addData, nil)
+	if err != nil {
+		return err
+	}
+
+	builtAddTx, err := w.wc.signContractExecutionTx(ctx, tx, systemAddress, ethutils.ContractExecutionTxOpts{
 		ContractAddress: w.tokenIndex,
-		InputData:       addDivviRefferalTag(w.wc.chainProvider, addData, ethutils.HexToAddress(systemKeypair.Public)),
+		InputData:       addDivviRefferalTag(w.wc.chainProvider, addData, ethutils.HexToAddress(systemAddress)),
 		GasFeeCap:       gasSettings.GasFeeCap,
 		GasTipCap:       gasSettings.GasTipCap,
-		GasLimit:        gasSettings.GasLimit,
+		GasLimit:        addGasLimit.Limit,
 		Nonce:           addNonce,
 	})
 	if err != nil {
@@ -159,12 +161,14 @@ func (w *TokenDeployWorker) Work(ctx context.Context, job *river.Job[TokenDeploy
 	rawAddTxHex := hexutil.Encode(rawAddTx)
 
 	addOTXID, err := w.wc.store.InsertOTX(ctx, tx, store.OTX{
-		TrackingID:    job.Args.TrackingID,
-		OTXType:       store.TOKEN_INDEX_ADD,
-		SignerAccount: systemKeypair.Public,
-		RawTx:         rawAddTxHex,
-		TxHash:        builtAddTx.Hash().Hex(),
-		Nonce:         addNonce,
+		TrackingID:     job.Args.TrackingID,
+		OTXType:        store.TOKEN_INDEX_ADD,
+		SignerAccount:  systemAddress,
+		RawTx:          rawAddTxHex,
+		TxHash:         builtAddTx.Hash().Hex(),
+		Nonce:          addNonce,
+		GasLimit:       addGasLimit.Limit,
+		GasLimitSource: addGasLimit.Source,
 	})
 	if err != nil {
 		return err
@@ -190,17 +194,22 @@ func (w *TokenDeployWorker) Work(ctx context.Context, job *river.Job[TokenDeploy
 		return err
 	}
 
-	mintToNonce, err := w.wc.store.AcquireNonce(ctx, tx, systemKeypair.Public)
+	mintToNonce, err := w.wc.store.AcquireNonce(ctx, tx, systemAddress)
+	if err != nil {
+		return err
+	}
+
+	mintToGasLimit, err := w.wc.gasLimitEstimator.Estimate(ctx, store.TOKEN_TRANSFER, ethutils.HexToAddress(systemAddress), contractAddress, mintToData, nil)
 	if err != nil {
 		return err
 	}
 
-	builtMintToTx, err := w.wc.chainProvider.SignContractExecutionTx(privateKey, ethutils.ContractExecutionTxOpts{
+	builtMintToTx, err := w.wc.signContractExecutionTx(ctx, tx, systemAddress, ethutils.ContractExecutionTxOpts{
 		ContractAddress: contractAddress,
-		InputData:       addDivviRefferalTag(w.wc.chainProvider, mintToData, ethutils.HexToAddress(systemKeypair.Public)),
+		InputData:       addDivviRefferalTag(w.wc.chainProvider, mintToData, ethutils.HexToAddress(systemAddress)),
 		GasFeeCap:       gasSettings.GasFeeCap,
 		GasTipCap:       gasSettings.GasTipCap,
-		GasLimit:        gasSettings.GasLimit,
+		GasLimit:        mintToGasLimit.Limit,
 		Nonce:           mintToNonce,
 	})
 	if err != nil {
@@ -215,12 +224,14 @@ func (w *TokenDeployWorker) Work(ctx context.Context, job *river.Job[TokenDeploy
 	rawMintToTxHex := hexutil.Encode(rawMintToTx)
 
 	mintToOTXID, err := w.wc.store.InsertOTX(ctx, tx, store.OTX{
-		TrackingID:    job.Args.TrackingID,
-		OTXType:       store.TOKEN_TRANSFER,
-		SignerAccount: systemKeypair.Public,
-		RawTx:         rawMintToTxHex,
-		TxHash:        builtMintToTx.Hash().Hex(),
-		Nonce:         mintToNonce,
+		TrackingID:     job.Args.TrackingID,
+		OTXType:        store.TOKEN_TRANSFER,
+		SignerAccount:  systemAddress,
+		RawTx:          rawMintToTxHex,
+		TxHash:         builtMintToTx.Hash().Hex(),
+		Nonce:          mintToNonce,
+		GasLimit:       mintToGasLimit.Limit,
+		GasLimitSource: mintToGasLimit.Source,
 	})
 	if err != nil {
 		return err
@@ -240,17 +251,22 @@ func (w *TokenDeployWorker) Work(ctx context.Context, job *river.Job[TokenDeploy
 		return err
 	}
 
-	transferOwnershipNonce, err := w.wc.store.AcquireNonce(ctx, tx, systemKeypair.Public)
+	transferOwnershipNonce, err := w.wc.store.AcquireNonce(ctx, tx, systemAddress)
 	if err != nil {
 		return err
 	}
 
-	builtTransferOwnershipTx, err := w.wc.chainProvider.SignContractExecutionTx(privateKey, ethutils.ContractExecutionTxOpts{
+	transferOwnershipGasLimit, err := w.wc.gasLimitEstimator.Estimate(ctx, store.TRANSFER_OWNERSHIP, ethutils.HexToAddress(systemAddress), contractAddress, transferOwnershipData, nil)
+	if err != nil {
+		return err
+	}
+
+	builtTransferOwnershipTx, err := w.wc.signContractExecutionTx(ctx, tx, systemAddress, ethutils.ContractExecutionTxOpts{
 		ContractAddress: contractAddress,
-		InputData:       addDivviRefferalTag(w.wc.chainProvider, transferOwnershipData, ethutils.HexToAddress(systemKeypair.Public)),
+		InputData:       addDivviRefferalTag(w.wc.chainProvider, transferOwnershipData, ethutils.HexToAddress(systemAddress)),
 		GasFeeCap:       gasSettings.GasFeeCap,
 		GasTipCap:       gasSettings.GasTipCap,
-		GasLimit:        gasSettings.GasLimit,
+		GasLimit:        transferOwnershipGasLimit.Limit,
 		Nonce:           transferOwnershipNonce,
 	})
 	if err != nil {
@@ -265,12 +281,14 @@ func (w *TokenDeployWorker) Work(ctx context.Context, job *river.Job[TokenDeploy
 	rawTransferOwnershipTxHex := hexutil.Encode(rawTransferOwnershipTx)
 
 	transferOwnershipOTXID, err := w.wc.store.InsertOTX(ctx, tx, store.OTX{
-		TrackingID:    job.Args.TrackingID,
-		OTXType:       store.TRANSFER_OWNERSHIP,
-		SignerAccount: systemKeypair.Public,
-		RawTx:         rawTransferOwnershipTxHex,
-		TxHash:        builtTransferOwnershipTx.Hash().Hex(),
-		Nonce:         transferOwnershipNonce,
+		TrackingID:     job.Args.TrackingID,
+		OTXType:        store.TRANSFER_OWNERSHIP,
+		SignerAccount:  systemAddress,
+		RawTx:          rawTransferOwnershipTxHex,
+		TxHash:         builtTransferOwnershipTx.Hash().Hex(),
+		Nonce:          transferOwnershipNonce,
+		GasLimit:       transferOwnershipGasLimit.Limit,
+		GasLimitSource: transferOwnershipGasLimit.Source,
 	})
 	if err != nil {
 		return err
@@ -296,9 +314,10 @@ func (w *TokenDeployWorker) Work(ctx context.Context, job *river.Job[TokenDeploy
 		},
 		{
 			Args: DispatchArgs{
-				TrackingID: job.Args.TrackingID,
-				OTXID:      addOTXID,
-				RawTx:      rawAddTxHex,
+				TrackingID:       job.Args.TrackingID,
+				OTXID:            addOTXID,
+				RawTx:            rawAddTxHex,
+				PredecessorOTXID: deployContractOTXID,
 			},
 			InsertOpts: &river.InsertOpts{
 				Priority: 2,
@@ -306,9 +325,10 @@ func (w *TokenDeployWorker) Work(ctx context.Context, job *river.Job[TokenDeploy
 		},
 		{
 			Args: DispatchArgs{
-				TrackingID: job.Args.TrackingID,
-				OTXID:      mintToOTXID,
-				RawTx:      rawMintToTxHex,
+				TrackingID:       job.Args.TrackingID,
+				OTXID:            mintToOTXID,
+				RawTx:            rawMintToTxHex,
+				PredecessorOTXID: addOTXID,
 			},
 			InsertOpts: &river.InsertOpts{
 				Priority: 3,
@@ -316,9 +336,10 @@ func (w *TokenDeployWorker) Work(ctx context.Context, job *river.Job[TokenDeploy
 		},
 		{
 			Args: DispatchArgs{
-				TrackingID: job.Args.TrackingID,
-				OTXID:      transferOwnershipOTXID,
-				RawTx:      rawTransferOwnershipTxHex,
+				TrackingID:       job.Args.TrackingID,
+				OTXID:            transferOwnershipOTXID,
+				RawTx:            rawTransferOwnershipTxHex,
+				PredecessorOTXID: mintToOTXID,
 			},
 			InsertOpts: &river.InsertOpts{
 				Priority: 4,
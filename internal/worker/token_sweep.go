@@ -4,7 +4,7 @@ import (
 	"context"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
-	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/grassrootseconomics/eth-custodial/internal/gas"
 	"github.com/grassrootseconomics/eth-custodial/internal/store"
 	"github.com/grassrootseconomics/eth-custodial/pkg/event"
 	"github.com/grassrootseconomics/ethutils"
@@ -17,6 +17,7 @@ type (
 		From         string `json:"from"`
 		To           string `json:"to"`
 		TokenAddress string `json:"tokenAddress"`
+		FeeClass     string `json:"feeClass,omitempty"`
 	}
 
 	TokenSweepWorker struct {
@@ -34,13 +35,17 @@ func (w *TokenSweepWorker) Work(ctx context.Context, job *river.Job[TokenSweepAr
 	}
 	defer tx.Rollback(ctx)
 
-	keypair, err := w.wc.store.LoadPrivateKey(ctx, tx, job.Args.From)
+	input, err := Abi[Sweep].EncodeArgs(
+		ethutils.HexToAddress(job.Args.To),
+	)
 	if err != nil {
 		return err
 	}
 
-	privateKey, err := crypto.HexToECDSA(keypair.Private)
-	if err != nil {
+	if err := w.wc.preflight(ctx, tx, job.Args.TrackingID, store.TOKEN_SWEEP, job.Args.From, simulationCall{
+		To:    ethutils.HexToAddress(job.Args.TokenAddress),
+		Input: input,
+	}); err != nil {
 		return err
 	}
 
@@ -49,24 +54,22 @@ func (w *TokenSweepWorker) Work(ctx context.Context, job *river.Job[TokenSweepAr
 		return err
 	}
 
-	input, err := Abi[Sweep].EncodeArgs(
-		ethutils.HexToAddress(job.Args.To),
-	)
+	gasSettings, err := w.wc.gasOracle.GetSettings(gas.ResolveFeeClass(job.Args.FeeClass, store.TOKEN_SWEEP))
 	if err != nil {
 		return err
 	}
 
-	gasSettings, err := w.wc.gasOracle.GetSettings()
+	gasLimit, err := w.wc.gasLimitEstimator.Estimate(ctx, store.TOKEN_SWEEP, ethutils.HexToAddress(job.Args.From), ethutils.HexToAddress(job.Args.TokenAddress), input, nil)
 	if err != nil {
 		return err
 	}
 
-	builtTx, err := w.wc.chainProvider.SignContractExecutionTx(privateKey, ethutils.ContractExecutionTxOpts{
+	builtTx, err := w.wc.signContractExecutionTx(ctx, tx, job.Args.From, ethutils.ContractExecutionTxOpts{
 		ContractAddress: ethutils.HexToAddress(job.Args.TokenAddress),
 		InputData:       addDivviRefferalTag(w.wc.chainProvider, input, ethutils.HexToAddress(job.Args.From)),
 		GasFeeCap:       gasSettings.GasFeeCap,
 		GasTipCap:       gasSettings.GasTipCap,
-		GasLimit:        gasSettings.GasLimit,
+		GasLimit:        gasLimit.Limit,
 		Nonce:           nonce,
 	})
 	if err != nil {
@@ -81,12 +84,14 @@ func (w *TokenSweepWorker) Work(ctx context.Context, job *river.Job[TokenSweepAr
 	rawTxHex := hexutil.Encode(rawTx)
 
 	otxID, err := w.wc.store.InsertOTX(ctx, tx, store.OTX{
-		TrackingID:    job.Args.TrackingID,
-		OTXType:       store.TOKEN_SWEEP,
-		SignerAccount: job.Args.From,
-		RawTx:         rawTxHex,
-		TxHash:        builtTx.Hash().Hex(),
-		Nonce:         nonce,
+		TrackingID:     job.Args.TrackingID,
+		OTXType:        store.TOKEN_SWEEP,
+		SignerAccount:  job.Args.From,
+		RawTx:          rawTxHex,
+		TxHash:         builtTx.Hash().Hex(),
+		Nonce:          nonce,
+		GasLimit:       gasLimit.Limit,
+		GasLimitSource: gasLimit.Source,
 	})
 	if err != nil {
 		return err
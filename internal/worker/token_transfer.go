@@ -4,7 +4,7 @@ import (
 	"context"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
-	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/grassrootseconomics/eth-custodial/internal/gas"
 	"github.com/grassrootseconomics/eth-custodial/internal/store"
 	"github.com/grassrootseconomics/eth-custodial/pkg/event"
 	"github.com/grassrootseconomics/ethutils"
@@ -18,6 +18,7 @@ type (
 		To           string `json:"to"`
 		TokenAddress string `json:"tokenAddress"`
 		Amount       string `json:"amount"`
+		FeeClass     string `json:"feeClass,omitempty"`
 	}
 
 	TokenTransferWorker struct {
@@ -35,45 +36,47 @@ func (w *TokenTransferWorker) Work(ctx context.Context, job *river.Job[TokenTran
 	}
 	defer tx.Rollback(ctx)
 
-	keypair, err := w.wc.store.LoadPrivateKey(ctx, tx, job.Args.From)
+	amount, err := StringToBigInt(job.Args.Amount, false)
 	if err != nil {
 		return err
 	}
 
-	privateKey, err := crypto.HexToECDSA(keypair.Private)
+	input, err := Abi[Transfer].EncodeArgs(
+		ethutils.HexToAddress(job.Args.To),
+		amount,
+	)
 	if err != nil {
 		return err
 	}
 
-	nonce, err := w.wc.store.AcquireNonce(ctx, tx, job.Args.From)
-	if err != nil {
+	if err := w.wc.preflight(ctx, tx, job.Args.TrackingID, store.TOKEN_TRANSFER, job.Args.From, simulationCall{
+		To:    ethutils.HexToAddress(job.Args.TokenAddress),
+		Input: input,
+	}); err != nil {
 		return err
 	}
 
-	amount, err := StringToBigInt(job.Args.Amount, false)
+	nonce, err := w.wc.store.AcquireNonce(ctx, tx, job.Args.From)
 	if err != nil {
 		return err
 	}
 
-	input, err := Abi[Transfer].EncodeArgs(
-		ethutils.HexToAddress(job.Args.To),
-		amount,
-	)
+	gasSettings, err := w.wc.gasOracle.GetSettings(gas.ResolveFeeClass(job.Args.FeeClass, store.TOKEN_TRANSFER))
 	if err != nil {
 		return err
 	}
 
-	gasSettings, err := w.wc.gasOracle.GetSettings()
+	gasLimit, err := w.wc.gasLimitEstimator.Estimate(ctx, store.TOKEN_TRANSFER, ethutils.HexToAddress(job.Args.From), ethutils.HexToAddress(job.Args.TokenAddress), input, nil)
 	if err != nil {
 		return err
 	}
 
-	builtTx, err := w.wc.chainProvider.SignContractExecutionTx(privateKey, ethutils.ContractExecutionTxOpts{
+	builtTx, err := w.wc.signContractExecutionTx(ctx, tx, job.Args.From, ethutils.ContractExecutionTxOpts{
 		ContractAddress: ethutils.HexToAddress(job.Args.TokenAddress),
 		InputData:       addDivviRefferalTag(w.wc.chainProvider, input, ethutils.HexToAddress(job.Args.From)),
 		GasFeeCap:       gasSettings.GasFeeCap,
 		GasTipCap:       gasSettings.GasTipCap,
-		GasLimit:        gasSettings.GasLimit,
+		GasLimit:        gasLimit.Limit,
 		Nonce:           nonce,
 	})
 	if err != nil {
@@ -88,12 +91,14 @@ func (w *TokenTransferWorker) Work(ctx context.Context, job *river.Job[TokenTran
 	rawTxHex := hexutil.Encode(rawTx)
 
 	otxID, err := w.wc.store.InsertOTX(ctx, tx, store.OTX{
-		TrackingID:    job.Args.TrackingID,
-		OTXType:       store.TOKEN_TRANSFER,
-		SignerAccount: job.Args.From,
-		RawTx:         rawTxHex,
-		TxHash:        builtTx.Hash().Hex(),
-		Nonce:         nonce,
+		TrackingID:     job.Args.TrackingID,
+		OTXType:        store.TOKEN_TRANSFER,
+		SignerAccount:  job.Args.From,
+		RawTx:          rawTxHex,
+		TxHash:         builtTx.Hash().Hex(),
+		Nonce:          nonce,
+		GasLimit:       gasLimit.Limit,
+		GasLimitSource: gasLimit.Source,
 	})
 	if err != nil {
 		return err
@@ -11,10 +11,10 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/grassrootseconomics/eth-custodial/internal/gas"
+	"github.com/grassrootseconomics/eth-custodial/internal/rpcerror"
 	"github.com/grassrootseconomics/eth-custodial/internal/store"
-	"github.com/lmittmann/w3"
+	"github.com/jackc/pgx/v5"
 	"github.com/lmittmann/w3/module/eth"
 	"github.com/riverqueue/river"
 )
@@ -56,11 +56,8 @@ func (w *UnlockerWorker) Work(ctx context.Context, _ *river.Job[UnlockerArgs]) e
 }
 
 func (w *UnlockerWorker) processAccount(ctx context.Context, account string) error {
-	var networkNonce uint64
-	if err := w.wc.chainProvider.Client.CallCtx(
-		ctx,
-		eth.Nonce(common.HexToAddress(account), nil).Returns(&networkNonce),
-	); err != nil {
+	networkNonce, err := w.wc.rpc.Nonce(ctx, common.HexToAddress(account), nil)
+	if err != nil {
 		return err
 	}
 	w.wc.logg.Info("unlocker: network nonce", "account", account, "nonce", networkNonce)
@@ -102,51 +99,41 @@ func (w *UnlockerWorker) processOTX(ctx context.Context, otx *store.OTX) error {
 		return err
 	}
 
-	if err := w.sendRawTx(ctx, rawTxBytes); err == nil {
+	endpoint, err := w.wc.broadcast(ctx, rawTxBytes)
+	if recordErr := w.recordAttempt(ctx, otx.ID, endpoint, rawTxBytes, err); recordErr != nil {
+		return recordErr
+	}
+
+	if inNetwork(err) {
 		w.wc.logg.Info("unlocker: resubmitted successfully", "otx_id", otx.ID, "nonce", otx.Nonce)
 		return w.setStatus(ctx, otx.ID, store.IN_NETWORK)
-	} else {
-		errType := unlockerClassifyRPCError(err)
-		w.wc.logg.Info("unlocker: resubmit error", "otx_id", otx.ID, "type", errType, "error", err)
-
-		switch errType {
-		case "nonce_low":
-			return w.checkReceipt(ctx, otx)
+	}
 
-		case "gas_price", "replacement_underpriced":
-			return w.resignAndResubmit(ctx, otx)
+	var dispatchErr *DispatchError
+	if !errors.As(err, &dispatchErr) {
+		return err
+	}
+	w.wc.logg.Info("unlocker: resubmit error", "otx_id", otx.ID, "status", dispatchErr.Class.Status, "error", err)
 
-		case "no_gas":
-			w.wc.logg.Warn("unlocker: account has no gas, stopping sequence", "otx_id", otx.ID)
-			return nil
+	switch dispatchErr.Class.Action {
+	case rpcerror.ActionReconcileNonce:
+		return w.checkReceipt(ctx, otx)
 
-		default:
-			return err
-		}
-	}
-}
+	case rpcerror.ActionBumpFees:
+		return w.resignAndResubmit(ctx, otx)
 
-func (w *UnlockerWorker) sendRawTx(ctx context.Context, rawTx []byte) error {
-	var txHash common.Hash
-	var callErrs w3.CallErrors
+	case rpcerror.ActionRefillGas:
+		w.wc.logg.Warn("unlocker: account has no gas, stopping sequence", "otx_id", otx.ID)
+		return nil
 
-	if err := w.wc.chainProvider.Client.CallCtx(ctx, eth.SendRawTx(rawTx).Returns(&txHash)); errors.As(err, &callErrs) {
-		if jsonErr, ok := callErrs[0].(rpc.Error); ok {
-			if classified := handleJSONRPCError(jsonErr.Error()); classified != nil {
-				return &DispatchError{Err: classified, OriginalErr: jsonErr}
-			}
-		}
-		return callErrs[0]
-	} else if err != nil {
-		return handleNetworkError(err)
+	default:
+		return err
 	}
-
-	return nil
 }
 
 func (w *UnlockerWorker) checkReceipt(ctx context.Context, otx *store.OTX) error {
 	var receipt *types.Receipt
-	if err := w.wc.chainProvider.Client.CallCtx(
+	if err := w.wc.rpc.CallCtx(
 		ctx,
 		eth.TxReceipt(common.HexToHash(otx.TxHash)).Returns(&receipt),
 	); err != nil {
@@ -156,11 +143,8 @@ func (w *UnlockerWorker) checkReceipt(ctx context.Context, otx *store.OTX) error
 	if receipt == nil {
 		// Stored hash was never mined. Check if nonce was consumed by a different tx
 		// (resignAndResubmit race: replacement submitted, DB updated, but original got mined first).
-		var networkNonce uint64
-		if err := w.wc.chainProvider.Client.CallCtx(
-			ctx,
-			eth.Nonce(common.HexToAddress(otx.SignerAccount), nil).Returns(&networkNonce),
-		); err != nil {
+		networkNonce, err := w.wc.rpc.Nonce(ctx, common.HexToAddress(otx.SignerAccount), nil)
+		if err != nil {
 			return err
 		}
 		if networkNonce > otx.Nonce {
@@ -188,7 +172,7 @@ func (w *UnlockerWorker) recoverOrphanedNonce(ctx context.Context, otx *store.OT
 		SELECT otx.tx_hash FROM otx
 		INNER JOIN keystore ON otx.signer_account = keystore.id
 		INNER JOIN dispatch ON otx.id = dispatch.otx_id
-		WHERE keystore.public_key = $1 AND otx.nonce = $2 AND dispatch.status = 'SUCCESS'
+		WHERE keystore.public_key = $1 AND otx.nonce = $2 AND dispatch.status IN ('SUCCESS', 'CONFIRMED')
 		LIMIT 1`, otx.SignerAccount, otx.Nonce+1).Scan(&anchorTxHash); err != nil {
 		w.wc.logg.Error("unlocker: orphaned nonce, no anchor tx found",
 			"otx_id", otx.ID, "nonce", otx.Nonce)
@@ -196,7 +180,7 @@ func (w *UnlockerWorker) recoverOrphanedNonce(ctx context.Context, otx *store.OT
 	}
 
 	var anchorReceipt *types.Receipt
-	if err := w.wc.chainProvider.Client.CallCtx(ctx,
+	if err := w.wc.rpc.CallCtx(ctx,
 		eth.TxReceipt(common.HexToHash(anchorTxHash)).Returns(&anchorReceipt)); err != nil || anchorReceipt == nil {
 		w.wc.logg.Error("unlocker: orphaned nonce, anchor receipt unavailable",
 			"otx_id", otx.ID, "nonce", otx.Nonce)
@@ -211,7 +195,7 @@ func (w *UnlockerWorker) recoverOrphanedNonce(ctx context.Context, otx *store.OT
 
 	for blockNum := scanFrom; blockNum <= anchorBlock; blockNum++ {
 		var block *types.Block
-		if err := w.wc.chainProvider.Client.CallCtx(ctx,
+		if err := w.wc.rpc.CallCtx(ctx,
 			eth.BlockByNumber(new(big.Int).SetUint64(blockNum)).Returns(&block)); err != nil || block == nil {
 			continue
 		}
@@ -225,7 +209,7 @@ func (w *UnlockerWorker) recoverOrphanedNonce(ctx context.Context, otx *store.OT
 			}
 			actualHash := tx.Hash().Hex()
 			var actualReceipt *types.Receipt
-			if err := w.wc.chainProvider.Client.CallCtx(ctx,
+			if err := w.wc.rpc.CallCtx(ctx,
 				eth.TxReceipt(tx.Hash()).Returns(&actualReceipt)); err != nil || actualReceipt == nil {
 				continue
 			}
@@ -240,15 +224,25 @@ func (w *UnlockerWorker) recoverOrphanedNonce(ctx context.Context, otx *store.OT
 				return err
 			}
 			defer dbTx.Rollback(ctx)
-			if _, err := dbTx.Exec(ctx, `UPDATE otx SET tx_hash = $1 WHERE id = $2`, actualHash, otx.ID); err != nil {
+			// The mined tx is either a known member of the lineage or an unrecorded re-sign, which is added to it.
+			minedOTXID, err := w.lineageOTXID(ctx, dbTx, otx, tx)
+			if err != nil {
 				return err
 			}
-			if err := w.wc.store.UpdateDispatchTxStatus(ctx, dbTx, store.DispatchTx{
-				OTXID:  otx.ID,
-				Status: status,
+			// The mined OTX may already be marked REPLACED, which only a receipt update overrides.
+			blockNumber := actualReceipt.BlockNumber.Uint64()
+			blockHash := actualReceipt.BlockHash.Hex()
+			if err := w.wc.store.UpdateDispatchTxReceipt(ctx, dbTx, store.DispatchTx{
+				OTXID:       minedOTXID,
+				Status:      status,
+				BlockNumber: &blockNumber,
+				BlockHash:   &blockHash,
 			}); err != nil {
 				return err
 			}
+			if err := w.wc.resolveReplacements(ctx, dbTx, minedOTXID); err != nil {
+				return err
+			}
 			return dbTx.Commit(ctx)
 		}
 	}
@@ -258,6 +252,21 @@ func (w *UnlockerWorker) recoverOrphanedNonce(ctx context.Context, otx *store.OT
 	return nil
 }
 
+// lineageOTXID returns the OTX of minedTx, which consumed otx's nonce. A mined tx that was never recorded is stored as a
+// replacement of otx instead of rewriting otx in place.
+func (w *UnlockerWorker) lineageOTXID(ctx context.Context, dbTx pgx.Tx, otx *store.OTX, minedTx *types.Transaction) (uint64, error) {
+	minedOTX, err := w.wc.store.GetOTXByTxHash(ctx, dbTx, minedTx.Hash().Hex())
+	if err == nil {
+		return minedOTX.ID, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return 0, err
+	}
+
+	minedOTXID, _, err := w.wc.insertReplacementOTX(ctx, dbTx, otx, minedTx, store.IN_NETWORK)
+	return minedOTXID, err
+}
+
 func (w *UnlockerWorker) resignAndResubmit(ctx context.Context, otx *store.OTX) error {
 	originalTxBytes, err := hexutil.Decode(otx.RawTx)
 	if err != nil {
@@ -277,7 +286,7 @@ func (w *UnlockerWorker) resignAndResubmit(ctx context.Context, otx *store.OTX)
 		return errors.New("cannot re-sign non-dynamic-fee transaction")
 	}
 
-	gasSettings, err := w.wc.gasOracle.GetSettings()
+	gasSettings, err := w.wc.gasOracle.GetSettings(gas.DefaultFeeClass(otx.OTXType))
 	if err != nil {
 		return err
 	}
@@ -300,17 +309,7 @@ func (w *UnlockerWorker) resignAndResubmit(ctx context.Context, otx *store.OTX)
 	}
 	defer dbTx.Rollback(ctx)
 
-	keypair, err := w.wc.store.LoadPrivateKey(ctx, dbTx, otx.SignerAccount)
-	if err != nil {
-		return err
-	}
-
-	privateKey, err := crypto.HexToECDSA(keypair.Private)
-	if err != nil {
-		return err
-	}
-
-	newTx, err := types.SignNewTx(privateKey, w.wc.chainProvider.Signer, &types.DynamicFeeTx{
+	newTx, err := w.wc.signTx(ctx, dbTx, otx.SignerAccount, &types.DynamicFeeTx{
 		Nonce:     originalTx.Nonce(),
 		To:        originalTx.To(),
 		Value:     originalTx.Value(),
@@ -328,38 +327,52 @@ func (w *UnlockerWorker) resignAndResubmit(ctx context.Context, otx *store.OTX)
 		return err
 	}
 
-	if err := w.sendRawTx(ctx, newRawTxBytes); err != nil {
+	endpoint, err := w.wc.broadcast(ctx, newRawTxBytes)
+	if !inNetwork(err) {
+		w.wc.recordDispatchAttempt(ctx, dbTx, otx.ID, attemptSourceUnlocker, endpoint, newRawTxBytes, err)
+		if commitErr := dbTx.Commit(ctx); commitErr != nil {
+			return commitErr
+		}
 		return err
 	}
 
 	// Guard against the block-inclusion race: if the original tx was sealed into a block
 	// while we were re-signing, our new tx is now invalid. Recover via checkReceipt.
-	var chainNonce uint64
-	if err := w.wc.chainProvider.Client.CallCtx(ctx,
-		eth.Nonce(common.HexToAddress(otx.SignerAccount), nil).Returns(&chainNonce)); err == nil && chainNonce > otx.Nonce {
+	if chainNonce, err := w.wc.rpc.Nonce(ctx, common.HexToAddress(otx.SignerAccount), nil); err == nil && chainNonce > otx.Nonce {
 		w.wc.logg.Warn("unlocker: nonce consumed during re-sign, recovering from original",
 			"otx_id", otx.ID, "nonce", otx.Nonce)
+		w.wc.recordDispatchAttempt(ctx, dbTx, otx.ID, attemptSourceUnlocker, endpoint, newRawTxBytes, nil)
+		// checkReceipt runs its own transactions, release this connection first.
+		if err := dbTx.Commit(ctx); err != nil {
+			return err
+		}
 		return w.checkReceipt(ctx, otx)
 	}
 
-	newRawTxHex := hexutil.Encode(newRawTxBytes)
-	newTxHash := newTx.Hash().Hex()
+	newOTXID, _, err := w.wc.insertReplacementOTX(ctx, dbTx, otx, newTx, store.IN_NETWORK)
+	if err != nil {
+		return err
+	}
+	w.wc.recordDispatchAttempt(ctx, dbTx, newOTXID, attemptSourceUnlocker, endpoint, newRawTxBytes, nil)
 
-	if _, err := dbTx.Exec(ctx,
-		`UPDATE otx SET raw_tx = $1, tx_hash = $2 WHERE id = $3`,
-		newRawTxHex, newTxHash, otx.ID,
-	); err != nil {
+	if err := dbTx.Commit(ctx); err != nil {
 		return err
 	}
 
-	if err := w.wc.store.UpdateDispatchTxStatus(ctx, dbTx, store.DispatchTx{
-		OTXID:  otx.ID,
-		Status: store.IN_NETWORK,
-	}); err != nil {
+	w.wc.logg.Info("unlocker: re-signed and resubmitted", "otx_id", otx.ID, "new_otx_id", newOTXID, "new_tx_hash", newTx.Hash().Hex())
+	return nil
+}
+
+// recordAttempt records a broadcast made while the unlocker holds no transaction.
+func (w *UnlockerWorker) recordAttempt(ctx context.Context, otxID uint64, endpoint string, rawTx []byte, sendErr error) error {
+	dbTx, err := w.wc.store.Pool().Begin(ctx)
+	if err != nil {
 		return err
 	}
+	defer dbTx.Rollback(ctx)
+
+	w.wc.recordDispatchAttempt(ctx, dbTx, otxID, attemptSourceUnlocker, endpoint, rawTx, sendErr)
 
-	w.wc.logg.Info("unlocker: re-signed and resubmitted", "otx_id", otx.ID, "new_tx_hash", newTxHash)
 	return dbTx.Commit(ctx)
 }
 
@@ -377,6 +390,12 @@ func (w *UnlockerWorker) setStatus(ctx context.Context, otxID uint64, status str
 		return err
 	}
 
+	if status == store.SUCCESS || status == store.REVERTED {
+		if err := w.wc.resolveReplacements(ctx, dbTx, otxID); err != nil {
+			return err
+		}
+	}
+
 	return dbTx.Commit(ctx)
 }
 
@@ -388,8 +407,9 @@ func (w *UnlockerWorker) getStuckOTXs(ctx context.Context) ([]*store.OTX, error)
 		FROM keystore
 		INNER JOIN otx ON keystore.id = otx.signer_account
 		INNER JOIN dispatch ON otx.id = dispatch.otx_id
-		WHERE dispatch.status NOT IN ('SUCCESS', 'REVERTED', 'EXTERNAL_DISPATCH')
+		WHERE dispatch.status NOT IN ('SUCCESS', 'CONFIRMED', 'REVERTED', 'EXTERNAL_DISPATCH', 'CANCELLED', 'REPLACED', 'ABORTED')
 		  AND otx.otx_type NOT IN ('GENERIC_SIGN', 'OTHER_MANUAL')
+		  AND NOT otx.replaced
 		  AND dispatch.updated_at <= $1
 		ORDER BY otx.id ASC
 		LIMIT 100`, cutoff)
@@ -411,8 +431,10 @@ func (w *UnlockerWorker) getOTXsFromNonce(ctx context.Context, account string, f
 		WHERE keystore.public_key = $1
 		  AND (
 		    otx.nonce >= $2
-		    OR dispatch.status NOT IN ('SUCCESS', 'REVERTED', 'EXTERNAL_DISPATCH')
+		    OR dispatch.status NOT IN ('SUCCESS', 'CONFIRMED', 'REVERTED', 'EXTERNAL_DISPATCH')
 		  )
+		  AND dispatch.status NOT IN ('CANCELLED', 'REPLACED', 'ABORTED')
+		  AND NOT otx.replaced
 		ORDER BY otx.nonce ASC`, account, fromNonce)
 	if err != nil {
 		return nil, err
@@ -449,22 +471,3 @@ func affectedAccounts(otxs []*store.OTX) map[string]struct{} {
 	}
 	return accounts
 }
-
-func unlockerClassifyRPCError(err error) string {
-	if err == nil {
-		return ""
-	}
-	msg := err.Error()
-	switch {
-	case strings.Contains(msg, "nonce too low"):
-		return "nonce_low"
-	case strings.Contains(msg, "replacement transaction underpriced"):
-		return "replacement_underpriced"
-	case strings.Contains(msg, "transaction underpriced"), strings.Contains(msg, "gas fee cap is below the minimum base fee"):
-		return "gas_price"
-	case strings.Contains(msg, "insufficient funds for gas"):
-		return "no_gas"
-	default:
-		return "unknown"
-	}
-}
@@ -3,18 +3,23 @@ package worker
 import (
 	"context"
 	"log/slog"
+	"math/big"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	ensclient "github.com/grassrootseconomics/eth-custodial/internal/ens_client"
 	"github.com/grassrootseconomics/eth-custodial/internal/gas"
+	"github.com/grassrootseconomics/eth-custodial/internal/multirpc"
 	"github.com/grassrootseconomics/eth-custodial/internal/pub"
+	"github.com/grassrootseconomics/eth-custodial/internal/rpcerror"
+	"github.com/grassrootseconomics/eth-custodial/internal/signer"
 	"github.com/grassrootseconomics/eth-custodial/internal/store"
 	"github.com/grassrootseconomics/ethutils"
 	"github.com/jackc/pgx/v5"
 	"github.com/riverqueue/river"
 	"github.com/riverqueue/river/riverdriver/riverpgxv5"
 	"github.com/riverqueue/river/rivermigrate"
+	"github.com/riverqueue/river/rivertype"
 )
 
 type (
@@ -23,20 +28,27 @@ type (
 		Registry            map[string]common.Address
 		HealthCheckInterval time.Duration
 		GasOracle           gas.GasOracle
+		GasLimitEstimator   *gas.GasLimitEstimator
 		Store               store.Store
 		Logg                *slog.Logger
 		ChainProvider       *ethutils.Provider
-		Pub                 *pub.Pub
-		EnsClient           *ensclient.EnsClient
+		RPC                 *multirpc.Client
+		RPCErrors           *rpcerror.Classifier
+		Signer              signer.Signer
+		// SystemSignerStrategy is either round_robin (default) or least_pending
+		SystemSignerStrategy string
+		Pub                  *pub.Pub
+		EnsClient            *ensclient.EnsClient
+		// Confirmations is the number of blocks after which a mined OTX is CONFIRMED
+		Confirmations uint64
+		// MaxGasFeeCap caps the fee of speed-ups and retrier bumps, nil means no cap
+		MaxGasFeeCap *big.Int
+		// Simulate runs transfers, sweeps, swaps, deposits and generic signs with eth_call before signing
+		Simulate bool
 		// TODO: temporary patch for prod because poolIndex doesn't exist in the entry point registry
 		Prod bool
 	}
 
-	signer struct {
-		gasOracle     gas.GasOracle
-		chainProvider *ethutils.Provider
-	}
-
 	WorkerContainer struct {
 		queueClient   *river.Client[pgx.Tx]
 		registry      map[string]common.Address
@@ -45,15 +57,27 @@ type (
 		logg          *slog.Logger
 		pub           *pub.Pub
 		chainProvider *ethutils.Provider
+		rpc           *multirpc.Client
+		rpcErrors     *rpcerror.Classifier
+		signer        signer.Signer
 		ensClient     *ensclient.EnsClient
 		prod          bool
+		simulate      bool
+
+		systemSignerStrategy string
+		maxGasFeeCap         *big.Int
+		confirmations        uint64
+		gasLimitEstimator    *gas.GasLimitEstimator
 	}
 )
 
 const (
-	migrationTimeout    = 15 * time.Second
-	healthCheckInterval = 2 * time.Minute
-	unlockerInterval    = 5 * time.Minute
+	migrationTimeout      = 15 * time.Second
+	healthCheckInterval   = 2 * time.Minute
+	unlockerInterval      = 5 * time.Minute
+	keyAccessSealInterval = 1 * time.Minute
+	nonceGapInterval      = 10 * time.Minute
+	confirmationInterval  = 1 * time.Minute
 )
 
 func New(o WorkerOpts) (*WorkerContainer, error) {
@@ -65,8 +89,17 @@ func New(o WorkerOpts) (*WorkerContainer, error) {
 		logg:          o.Logg,
 		pub:           o.Pub,
 		chainProvider: o.ChainProvider,
+		rpc:           o.RPC,
+		rpcErrors:     o.RPCErrors,
+		signer:        o.Signer,
 		ensClient:     o.EnsClient,
 		prod:          o.Prod,
+		simulate:      o.Simulate,
+
+		systemSignerStrategy: o.SystemSignerStrategy,
+		maxGasFeeCap:         o.MaxGasFeeCap,
+		confirmations:        o.Confirmations,
+		gasLimitEstimator:    o.GasLimitEstimator,
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), migrationTimeout)
@@ -98,6 +131,7 @@ func New(o WorkerOpts) (*WorkerContainer, error) {
 		},
 		Workers:      workers,
 		PeriodicJobs: setupPeriodicJobs(),
+		Middleware:   []rivertype.Middleware{keyAccessMiddleware(), subjectInsertMiddleware()},
 		Logger:       o.Logg,
 	})
 	if err != nil {
@@ -178,6 +212,30 @@ func setupWorkers(wc *WorkerContainer) (*river.Workers, error) {
 		return nil, err
 	}
 
+	if err := river.AddWorkerSafely(workers, &KeyAccessSealWorker{wc: wc}); err != nil {
+		return nil, err
+	}
+
+	if err := river.AddWorkerSafely(workers, &NonceGapWorker{wc: wc}); err != nil {
+		return nil, err
+	}
+
+	if err := river.AddWorkerSafely(workers, &OTXCancelWorker{wc: wc}); err != nil {
+		return nil, err
+	}
+
+	if err := river.AddWorkerSafely(workers, &OTXSpeedUpWorker{wc: wc}); err != nil {
+		return nil, err
+	}
+
+	if err := river.AddWorkerSafely(workers, &AccountDeactivateWorker{wc: wc}); err != nil {
+		return nil, err
+	}
+
+	if err := river.AddWorkerSafely(workers, &ConfirmationWorker{wc: wc}); err != nil {
+		return nil, err
+	}
+
 	return workers, nil
 }
 
@@ -201,5 +259,32 @@ func setupPeriodicJobs() []*river.PeriodicJob {
 				RunOnStart: true,
 			},
 		),
+		river.NewPeriodicJob(
+			river.PeriodicInterval(keyAccessSealInterval),
+			func() (river.JobArgs, *river.InsertOpts) {
+				return KeyAccessSealArgs{}, nil
+			},
+			&river.PeriodicJobOpts{
+				RunOnStart: true,
+			},
+		),
+		river.NewPeriodicJob(
+			river.PeriodicInterval(nonceGapInterval),
+			func() (river.JobArgs, *river.InsertOpts) {
+				return NonceGapArgs{}, nil
+			},
+			&river.PeriodicJobOpts{
+				RunOnStart: true,
+			},
+		),
+		river.NewPeriodicJob(
+			river.PeriodicInterval(confirmationInterval),
+			func() (river.JobArgs, *river.InsertOpts) {
+				return ConfirmationArgs{}, nil
+			},
+			&river.PeriodicJobOpts{
+				RunOnStart: true,
+			},
+		),
 	}
 }
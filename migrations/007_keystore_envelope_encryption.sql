@@ -0,0 +1,7 @@
+-- Envelope encryption for keystore.private_key
+-- key_version 0 marks legacy plaintext rows, any other value is the KEK version used to wrap data_key
+ALTER TABLE keystore
+ADD COLUMN data_key TEXT NOT NULL DEFAULT '',
+ADD COLUMN key_version INT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS key_version_idx ON keystore(key_version);
@@ -0,0 +1,25 @@
+-- ACCOUNT_CREATE jobs used to carry the generated private key in river_job.args
+-- Keys that never reached the keystore are saved as legacy plaintext rows (run "rekey" afterwards to encrypt them) and
+-- the job args are rewritten to only reference the public key
+-- river_job is created by the queue's own migrations which run after these on a fresh database
+DO $$
+BEGIN
+    IF to_regclass('river_job') IS NOT NULL THEN
+        INSERT INTO keystore(public_key, private_key)
+        SELECT DISTINCT river_job.args->'keypair'->>'Public', river_job.args->'keypair'->>'Private'
+        FROM river_job
+        WHERE river_job.kind = 'ACCOUNT_CREATE'
+        AND river_job.args ? 'keypair'
+        AND NOT EXISTS (
+            SELECT 1 FROM keystore WHERE keystore.public_key = river_job.args->'keypair'->>'Public'
+        );
+
+        UPDATE river_job
+        SET args = jsonb_build_object(
+            'trackingId', river_job.args->>'trackingId',
+            'publicKey', river_job.args->'keypair'->>'Public'
+        )
+        WHERE river_job.kind = 'ACCOUNT_CREATE'
+        AND river_job.args ? 'keypair';
+    END IF;
+END $$;
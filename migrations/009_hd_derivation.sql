@@ -0,0 +1,20 @@
+-- Hierarchical deterministic accounts
+-- Derived accounts store an empty private_key and are re-derived from the seed at m/44'/60'/0'/0/derivation_index
+ALTER TABLE keystore ADD COLUMN derivation_index INT UNIQUE;
+
+CREATE SEQUENCE IF NOT EXISTS derivation_index_seq AS INT MINVALUE 0 START WITH 0;
+
+-- Single row table holding the envelope encrypted HD seed
+CREATE TABLE IF NOT EXISTS hd_seed (
+    id INT PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+    seed TEXT NOT NULL,
+    data_key TEXT NOT NULL DEFAULT '',
+    key_version INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+create trigger update_hd_seed_timestamp
+    before update on hd_seed
+for each row
+execute procedure update_timestamp();
@@ -0,0 +1,12 @@
+-- Accounts can be frozen after their private key has been exported
+ALTER TABLE keystore ADD COLUMN frozen BOOLEAN NOT NULL DEFAULT false;
+
+-- Audit trail of private key exports
+CREATE TABLE IF NOT EXISTS key_export (
+    id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    keystore_id INT REFERENCES keystore(id) NOT NULL,
+    exported_by TEXT NOT NULL,
+    frozen BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS key_export_keystore_id_idx ON key_export(keystore_id);
@@ -0,0 +1,38 @@
+-- Account lifecycle states replace the keystore active and frozen flags
+CREATE TABLE IF NOT EXISTS account_status_type (
+  value TEXT PRIMARY KEY
+);
+INSERT INTO account_status_type (value) VALUES
+('PENDING'),
+('ACTIVE'),
+('FROZEN'),
+('CLOSED');
+
+ALTER TABLE keystore
+ADD COLUMN "status" TEXT REFERENCES account_status_type(value) NOT NULL DEFAULT 'PENDING';
+
+UPDATE keystore SET "status" = CASE
+    WHEN frozen THEN 'FROZEN'
+    WHEN active THEN 'ACTIVE'
+    ELSE 'PENDING'
+END;
+
+ALTER TABLE keystore
+DROP COLUMN active,
+DROP COLUMN frozen;
+
+-- History of account status changes
+CREATE TABLE IF NOT EXISTS account_status_change (
+    id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    keystore_id INT REFERENCES keystore(id) NOT NULL,
+    from_status TEXT REFERENCES account_status_type(value) NOT NULL,
+    to_status TEXT REFERENCES account_status_type(value) NOT NULL,
+    reason TEXT NOT NULL,
+    changed_by TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS account_status_change_keystore_id_idx ON account_status_change(keystore_id);
+
+-- Signed but not yet dispatched transactions of frozen or closed accounts
+INSERT INTO dispatch_status_type (value) VALUES
+('ACCOUNT_INACTIVE');
@@ -0,0 +1,5 @@
+-- master_key holds the pool of system signers, retired signers are kept for their in flight transactions
+ALTER TABLE master_key ADD COLUMN retired BOOLEAN NOT NULL DEFAULT false;
+
+-- Round robin cursor over the active system signers
+CREATE SEQUENCE IF NOT EXISTS system_signer_rr_seq;
@@ -0,0 +1,16 @@
+-- Master signer rotations, the old key is retired when a rotation begins and the new key joins master_key when it completes
+CREATE TABLE IF NOT EXISTS master_key_rotation (
+    id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    old_key_id INT REFERENCES keystore(id) NOT NULL,
+    new_key_id INT REFERENCES keystore(id) NOT NULL,
+    completed BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+-- Only one rotation can be in progress at a time
+CREATE UNIQUE INDEX IF NOT EXISTS master_key_rotation_pending_idx ON master_key_rotation(completed) WHERE NOT completed;
+
+create trigger update_master_key_rotation_timestamp
+    before update on master_key_rotation
+for each row
+execute procedure update_timestamp();
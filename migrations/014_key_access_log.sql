@@ -0,0 +1,44 @@
+-- Append only audit log of private key loads. Rows are hash chained in seq order by the sealer after they are committed.
+CREATE TABLE IF NOT EXISTS key_access_log (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    keystore_id INT REFERENCES keystore(id) NOT NULL,
+    caller TEXT NOT NULL,
+    tracking_id TEXT NOT NULL DEFAULT '',
+    subject TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    seq BIGINT UNIQUE,
+    prev_hash TEXT,
+    hash TEXT
+);
+CREATE INDEX IF NOT EXISTS key_access_log_keystore_id_idx ON key_access_log(keystore_id);
+CREATE INDEX IF NOT EXISTS key_access_log_unsealed_idx ON key_access_log(id) WHERE seq IS NULL;
+
+-- Only unsealed rows can be updated and only to set their chain fields
+CREATE OR REPLACE FUNCTION key_access_log_append_only()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE'
+        AND OLD.seq IS NULL
+        AND NEW.id = OLD.id
+        AND NEW.keystore_id = OLD.keystore_id
+        AND NEW.caller = OLD.caller
+        AND NEW.tracking_id = OLD.tracking_id
+        AND NEW.subject = OLD.subject
+        AND NEW.created_at = OLD.created_at
+    THEN
+        RETURN NEW;
+    END IF;
+
+    RAISE EXCEPTION 'key_access_log is append only';
+END;
+$$ language plpgsql;
+
+create trigger key_access_log_append_only
+    before update or delete on key_access_log
+for each row
+execute procedure key_access_log_append_only();
+
+create trigger key_access_log_no_truncate
+    before truncate on key_access_log
+for each statement
+execute procedure key_access_log_append_only();
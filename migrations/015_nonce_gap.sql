@@ -0,0 +1,18 @@
+INSERT INTO otx_tx_type (value) VALUES ('NONCE_GAP_FILL');
+
+-- Findings of the nonce gap check. A MISSING_OTX row is a nonce between the network nonce and next_nonce with no OTX
+-- and is repaired with a no-op transaction, a NONCE_BEHIND row is a next_nonce below the network nonce and is repaired
+-- by raising next_nonce.
+CREATE TABLE IF NOT EXISTS nonce_gap (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    keystore_id INT REFERENCES keystore(id) NOT NULL,
+    kind TEXT NOT NULL,
+    nonce INT NOT NULL,
+    internal_nonce INT NOT NULL,
+    network_nonce INT NOT NULL,
+    repaired BOOLEAN NOT NULL DEFAULT false,
+    repair_otx_id INT REFERENCES otx(id),
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS nonce_gap_keystore_id_idx ON nonce_gap(keystore_id);
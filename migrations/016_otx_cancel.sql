@@ -0,0 +1,6 @@
+INSERT INTO otx_tx_type (value) VALUES ('CANCEL');
+INSERT INTO dispatch_status_type (value) VALUES ('CANCELLED');
+
+-- A replacement transaction at the same nonce links to the OTX it replaces
+ALTER TABLE otx ADD COLUMN replaces_otx_id INT REFERENCES otx(id);
+CREATE INDEX IF NOT EXISTS otx_replaces_otx_id_idx ON otx(replaces_otx_id);
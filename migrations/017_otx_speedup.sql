@@ -0,0 +1 @@
+INSERT INTO dispatch_status_type (value) VALUES ('REPLACED');
@@ -0,0 +1,11 @@
+-- Remediations taken by the retrier after a dispatch error
+CREATE TABLE IF NOT EXISTS retrier_action (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    otx_id INT REFERENCES otx(id) NOT NULL,
+    attempt INT NOT NULL,
+    dispatch_status TEXT NOT NULL,
+    action TEXT NOT NULL,
+    detail TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS retrier_action_otx_id_idx ON retrier_action(otx_id);
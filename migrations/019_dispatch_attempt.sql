@@ -0,0 +1,15 @@
+-- Append-only history of every broadcast of an OTX, dispatch.status remains the latest state
+CREATE TABLE IF NOT EXISTS dispatch_attempt (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    otx_id INT REFERENCES otx(id) NOT NULL,
+    source TEXT NOT NULL,
+    tx_hash TEXT NOT NULL,
+    raw_tx TEXT NOT NULL,
+    gas_fee_cap NUMERIC NOT NULL,
+    gas_tip_cap NUMERIC NOT NULL,
+    rpc_endpoint TEXT NOT NULL,
+    "status" TEXT NOT NULL,
+    rpc_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS dispatch_attempt_otx_id_idx ON dispatch_attempt(otx_id);
@@ -0,0 +1,8 @@
+INSERT INTO dispatch_status_type (value) VALUES ('CONFIRMED');
+
+-- Block of the receipt an OTX was marked mined with, used to detect reorgs before it is confirmed
+ALTER TABLE dispatch ADD COLUMN IF NOT EXISTS block_number BIGINT;
+ALTER TABLE dispatch ADD COLUMN IF NOT EXISTS block_hash TEXT;
+
+-- Existing mined OTXs are long past any reorg
+UPDATE dispatch SET "status" = 'CONFIRMED' WHERE "status" = 'SUCCESS' AND updated_at < NOW() - INTERVAL '1 hour';
@@ -0,0 +1,23 @@
+-- JWT subject of the API request an OTX originates from, empty for system initiated OTXs
+ALTER TABLE otx ADD COLUMN IF NOT EXISTS subject TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS receipt (
+    otx_id INT PRIMARY KEY REFERENCES otx(id),
+    tx_hash TEXT NOT NULL,
+    block_number BIGINT NOT NULL,
+    block_hash TEXT NOT NULL,
+    success BOOLEAN NOT NULL,
+    gas_used BIGINT NOT NULL,
+    effective_gas_price NUMERIC NOT NULL,
+    fee NUMERIC NOT NULL,
+    contract_address TEXT,
+    logs JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS receipt_created_at_idx ON receipt(created_at);
+
+create trigger update_receipt_timestamp
+    before update on receipt
+for each row
+execute procedure update_timestamp();
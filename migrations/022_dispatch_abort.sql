@@ -0,0 +1,2 @@
+-- A step of a multi transaction flow that is never dispatched because an earlier step failed
+INSERT INTO dispatch_status_type (value) VALUES ('ABORTED');
@@ -0,0 +1,9 @@
+-- Node rejections that used to end up as UNKNOWN_ERROR. ALREADY_KNOWN is only recorded on dispatch attempts, the
+-- dispatch itself moves to IN_NETWORK.
+INSERT INTO dispatch_status_type (value) VALUES
+    ('ALREADY_KNOWN'),
+    ('INTRINSIC_GAS_TOO_LOW'),
+    ('GAS_LIMIT_EXCEEDED'),
+    ('TXPOOL_FULL'),
+    ('BASE_FEE_TOO_LOW'),
+    ('FEE_CURRENCY_ERROR');
@@ -0,0 +1,14 @@
+-- Simulations that reverted before signing. No OTX is created, so the nonce is never consumed.
+INSERT INTO dispatch_status_type (value) VALUES ('SIMULATION_FAILED');
+
+CREATE TABLE IF NOT EXISTS simulation_failure (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    tracking_id TEXT NOT NULL,
+    otx_type TEXT REFERENCES otx_tx_type(value) NOT NULL,
+    signer_account TEXT NOT NULL,
+    contract_address TEXT NOT NULL,
+    input TEXT NOT NULL,
+    revert_reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS simulation_failure_tracking_id_idx ON simulation_failure(tracking_id);
@@ -0,0 +1,4 @@
+-- Gas limit an OTX was signed with and whether it was estimated, taken from the cache of its contract method or the
+-- fallback. OTXs signed without estimation keep the defaults.
+ALTER TABLE otx ADD COLUMN IF NOT EXISTS gas_limit BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE otx ADD COLUMN IF NOT EXISTS gas_limit_source TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+-- System signers are added disabled and only receive work once enabled after their balance and allowlisting are
+-- checked on chain. Existing signers stay enabled.
+ALTER TABLE master_key ADD COLUMN IF NOT EXISTS enabled BOOLEAN NOT NULL DEFAULT true;
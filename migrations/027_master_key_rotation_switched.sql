@@ -0,0 +1,3 @@
+-- The old key keeps receiving work until the new key is allowlisted and both are switched in one step, the rotation
+-- completes once the old key has handed over its contracts and balance
+ALTER TABLE master_key_rotation ADD COLUMN IF NOT EXISTS switched BOOLEAN NOT NULL DEFAULT false;
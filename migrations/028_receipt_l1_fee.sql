@@ -0,0 +1,3 @@
+-- L1 data fee charged by OP stack chains such as Celo on top of gas_used * effective_gas_price, fee includes it.
+-- Receipts stored before are left without it.
+ALTER TABLE receipt ADD COLUMN IF NOT EXISTS l1_fee NUMERIC NOT NULL DEFAULT 0;
@@ -23,12 +23,14 @@ type (
 		To           string `json:"to" validate:"required,eth_addr_checksum"`
 		TokenAddress string `json:"tokenAddress" validate:"required,eth_addr_checksum"`
 		Amount       string `json:"amount" validate:"required"`
+		FeeClass     string `json:"feeClass,omitempty" validate:"omitempty,oneof=economy normal urgent"`
 	}
 
 	SweepRequest struct {
 		From         string `json:"from" validate:"required,eth_addr_checksum"`
 		To           string `json:"to" validate:"required,eth_addr_checksum"`
 		TokenAddress string `json:"tokenAddress" validate:"required,eth_addr_checksum"`
+		FeeClass     string `json:"feeClass,omitempty" validate:"omitempty,oneof=economy normal urgent"`
 	}
 
 	PoolSwapRequest struct {
@@ -37,6 +39,7 @@ type (
 		ToTokenAddress   string `json:"toTokenAddress" validate:"required,eth_addr_checksum"`
 		PoolAddress      string `json:"poolAddress" validate:"required,eth_addr_checksum"`
 		Amount           string `json:"amount" validate:"required"`
+		FeeClass         string `json:"feeClass,omitempty" validate:"omitempty,oneof=economy normal urgent"`
 	}
 
 	PoolDepositRequest struct {
@@ -44,6 +47,23 @@ type (
 		TokenAddress string `json:"tokenAddress" validate:"required,eth_addr_checksum"`
 		PoolAddress  string `json:"poolAddress" validate:"required,eth_addr_checksum"`
 		Amount       string `json:"amount" validate:"required"`
+		FeeClass     string `json:"feeClass,omitempty" validate:"omitempty,oneof=economy normal urgent"`
+	}
+
+	AccountImportRequest struct {
+		PrivateKey string `json:"privateKey" validate:"required,hexadecimal"`
+	}
+
+	AccountExportRequest struct {
+		Address  string `json:"address" validate:"required,eth_addr_checksum"`
+		Password string `json:"password" validate:"required,min=8"`
+		Freeze   bool   `json:"freeze"`
+	}
+
+	AccountStatusUpdateRequest struct {
+		Address string `param:"address" validate:"required,eth_addr_checksum"`
+		Status  string `json:"status" validate:"required,oneof=ACTIVE FROZEN CLOSED"`
+		Reason  string `json:"reason" validate:"required"`
 	}
 
 	AccountAddressParam struct {
@@ -54,6 +74,21 @@ type (
 		TrackingID string `param:"trackingId"  validate:"required,uuid"`
 	}
 
+	// SpeedUpRequest either multiplies the current fees by Multiplier or sets both fee caps in wei. Without either the
+	// fees are bumped by 15%.
+	SpeedUpRequest struct {
+		TrackingID string  `param:"trackingId" validate:"required,uuid"`
+		Multiplier float64 `json:"multiplier" validate:"omitempty,gte=1.1,lte=10,excluded_with=GasFeeCap"`
+		GasFeeCap  string  `json:"gasFeeCap" validate:"required_with=GasTipCap,omitempty,number"`
+		GasTipCap  string  `json:"gasTipCap" validate:"required_with=GasFeeCap,omitempty,number"`
+	}
+
+	// FeeReportRequest is a time range in unix seconds, To defaults to now.
+	FeeReportRequest struct {
+		From int64 `query:"from" validate:"required,gt=0"`
+		To   int64 `query:"to" validate:"omitempty,gtfield=From"`
+	}
+
 	OTXByAccountRequest struct {
 		Address string `param:"address" validate:"required,eth_addr_checksum"`
 		PerPage int    `query:"perPage" validate:"required,number,gt=0"`
@@ -69,12 +104,14 @@ type (
 		InitialMintee   string `json:"initialMintee" validate:"required,eth_addr_checksum"`
 		Owner           string `json:"owner" validate:"required,eth_addr_checksum"`
 		ExpiryTimestamp string `json:"expiryTimestamp,omitempty" validate:"omitempty"`
+		FeeClass        string `json:"feeClass,omitempty" validate:"omitempty,oneof=economy normal urgent"`
 	}
 
 	PoolDeployRequest struct {
-		Name   string `json:"name" validate:"required"`
-		Symbol string `json:"symbol" validate:"required"`
-		Owner  string `json:"owner" validate:"required,eth_addr_checksum"`
+		Name     string `json:"name" validate:"required"`
+		Symbol   string `json:"symbol" validate:"required"`
+		Owner    string `json:"owner" validate:"required,eth_addr_checksum"`
+		FeeClass string `json:"feeClass,omitempty" validate:"omitempty,oneof=economy normal urgent"`
 	}
 
 	DemurrageERC20DeployRequest struct {
@@ -87,6 +124,7 @@ type (
 		SinkAddress     string `json:"sinkAddress" validate:"required,eth_addr_checksum"`
 		DemurrageRate   string `json:"demurrageRate" validate:"required"`
 		DemurragePeriod string `json:"demurragePeriod" validate:"required"`
+		FeeClass        string `json:"feeClass,omitempty" validate:"omitempty,oneof=economy normal urgent"`
 	}
 )
 
@@ -101,4 +139,10 @@ const (
 	ErrBannedToken             = "E08"
 	ErrSymbolAlreadyExists     = "E09"
 	ErrPretiumLeak             = "E10"
+	ErrCodeServiceOnly         = "E11"
+	ErrCodeAccountExists       = "E12"
+	ErrCodeInvalidTransition   = "E13"
+	ErrCodeOTXFinal            = "E14"
+	ErrCodeInvalidFeeBump      = "E15"
+	ErrCodeSystemSigner        = "E16"
 )
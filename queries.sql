@@ -1,38 +1,347 @@
 --name: insert-keypair
--- Save hex encoded private key
+-- Save envelope encrypted private key
 -- $1: public_key
 -- $2: private_key
-INSERT INTO keystore(public_key, private_key) VALUES($1, $2) RETURNING id;
+-- $3: data_key
+-- $4: key_version
+INSERT INTO keystore(public_key, private_key, data_key, key_version) VALUES($1, $2, $3, $4) RETURNING id;
 
 --name: activate-keypair
--- Save hex encoded private key
+-- Activate a pending key once its account is registered on chain
 -- $1: public_key
-UPDATE keystore
-SET active = true
-WHERE public_key = $1;
+WITH activated AS (
+    UPDATE keystore
+    SET "status" = 'ACTIVE'
+    WHERE public_key = $1 AND "status" = 'PENDING'
+    RETURNING id
+)
+INSERT INTO account_status_change(keystore_id, from_status, to_status, reason, changed_by)
+SELECT id, 'PENDING', 'ACTIVE', 'custodial registration', 'system' FROM activated;
+
+--name: activate-system-keypair
+-- Activate a pending key added as a system signer
+-- $1: public_key
+WITH activated AS (
+    UPDATE keystore
+    SET "status" = 'ACTIVE'
+    WHERE public_key = $1 AND "status" = 'PENDING'
+    RETURNING id
+)
+INSERT INTO account_status_change(keystore_id, from_status, to_status, reason, changed_by)
+SELECT id, 'PENDING', 'ACTIVE', 'system signer', 'system' FROM activated;
 
 --name: load-key
 -- Load saved key pair
 -- $1: public_key
-SELECT public_key, private_key FROM keystore WHERE public_key=$1;
+SELECT id, public_key, private_key, data_key, key_version, derivation_index FROM keystore WHERE public_key=$1;
+
+--name: insert-external-keypair
+-- Save an active account whose private key is held outside the keystore, e.g. in an HSM
+-- $1: public_key
+INSERT INTO keystore(public_key, private_key, "status") VALUES($1, '', 'ACTIVE') RETURNING id;
+
+--name: insert-derived-keypair
+-- Save an account derived from the HD seed, the private key is not stored
+-- $1: public_key
+-- $2: derivation_index
+INSERT INTO keystore(public_key, private_key, derivation_index) VALUES($1, '', $2) RETURNING id;
+
+--name: next-derivation-index
+-- Reserve the next HD derivation index
+SELECT nextval('derivation_index_seq');
+
+--name: load-hd-seed
+-- Load the envelope encrypted HD seed
+SELECT seed, data_key, key_version FROM hd_seed WHERE id = 1;
+
+--name: bootstrap-hd-seed
+-- Save a newly generated HD seed if none exists
+-- $1: seed
+-- $2: data_key
+-- $3: key_version
+INSERT INTO hd_seed(seed, data_key, key_version) VALUES($1, $2, $3)
+ON CONFLICT (id) DO NOTHING;
+
+--name: update-hd-seed
+-- Replace the HD seed with its re-encrypted envelope
+-- $1: seed
+-- $2: data_key
+-- $3: key_version
+UPDATE hd_seed
+SET seed = $1, data_key = $2, key_version = $3
+WHERE id = 1;
 
 --name: check-keypair
--- Check if a key exists in the keystore and is activated
+-- Check if a key exists in the keystore and is active
+-- $1: public_key
+SELECT "status" = 'ACTIVE' FROM keystore WHERE public_key=$1;
+
+--name: keypair-exists
+-- Check if a key exists in the keystore regardless of its state
+-- $1: public_key
+SELECT EXISTS(SELECT 1 FROM keystore WHERE public_key=$1);
+
+--name: get-account-status
+-- Get an account's lifecycle status
+-- $1: public_key
+SELECT "status" FROM keystore WHERE public_key=$1;
+
+--name: update-account-status
+-- Change an account's lifecycle status and record the change, only if the status has not changed concurrently
+-- $1: public_key
+-- $2: from_status
+-- $3: to_status
+-- $4: reason
+-- $5: changed_by
+WITH updated AS (
+    UPDATE keystore
+    SET "status" = $3
+    WHERE public_key = $1 AND "status" = $2
+    RETURNING id
+)
+INSERT INTO account_status_change(keystore_id, from_status, to_status, reason, changed_by)
+SELECT id, $2, $3, $4, $5 FROM updated
+RETURNING id;
+
+--name: get-account-status-changes
+-- Get an account's lifecycle status history, latest first
+-- $1: public_key
+SELECT account_status_change.id, keystore.public_key, account_status_change.from_status, account_status_change.to_status,
+account_status_change.reason, account_status_change.changed_by, account_status_change.created_at
+FROM account_status_change
+INNER JOIN keystore ON account_status_change.keystore_id = keystore.id
+WHERE keystore.public_key = $1
+ORDER BY account_status_change.id DESC;
+
+--name: get-queued-jobs-by-account
+-- Get jobs that have not yet started and would sign or dispatch for an account
+-- $1: public_key
+SELECT river_job.id, COALESCE(river_job.args->>'trackingId', '') AS tracking_id FROM river_job
+WHERE river_job.state IN ('available', 'pending', 'retryable', 'scheduled')
+AND (
+    river_job.args->>'from' = $1
+    OR (river_job.kind = 'GAS_REFILL' AND river_job.args->>'address' = $1)
+    OR (river_job.kind = 'DISPATCH' AND (river_job.args->>'otxId')::INT IN (
+        SELECT otx.id FROM otx
+        INNER JOIN keystore ON otx.signer_account = keystore.id
+        WHERE keystore.public_key = $1
+    ))
+);
+
+--name: cancel-pending-dispatch-by-account
+-- Mark signed but not yet dispatched transactions of an account as cancelled
+-- $1: public_key
+UPDATE dispatch
+SET "status" = 'ACCOUNT_INACTIVE'
+FROM otx
+INNER JOIN keystore ON otx.signer_account = keystore.id
+WHERE dispatch.otx_id = otx.id AND keystore.public_key = $1 AND dispatch.status = 'PENDING'
+RETURNING otx.tracking_id;
+
+--name: insert-key-export
+-- Record a private key export
+-- $1: public_key
+-- $2: exported_by
+-- $3: frozen
+INSERT INTO key_export(keystore_id, exported_by, frozen)
+VALUES((SELECT id FROM keystore WHERE public_key = $1), $2, $3);
+
+--name: insert-key-access-log
+-- Record a private key load
+-- $1: public_key
+-- $2: caller
+-- $3: tracking_id
+-- $4: subject
+INSERT INTO key_access_log(keystore_id, caller, tracking_id, subject)
+VALUES((SELECT id FROM keystore WHERE public_key = $1), $2, $3, $4);
+
+--name: lock-key-access-log
+-- Serialize sealers for the rest of the transaction
+SELECT pg_advisory_xact_lock(hashtext('key_access_log'));
+
+--name: get-last-sealed-key-access-log
+-- Get the head of the key access log hash chain
+SELECT seq, hash FROM key_access_log WHERE seq IS NOT NULL ORDER BY seq DESC LIMIT 1;
+
+--name: get-unsealed-key-access-log
+-- Get committed key access log rows that are not yet part of the hash chain
+-- $1: limit
+SELECT key_access_log.id, keystore.public_key, key_access_log.caller, key_access_log.tracking_id, key_access_log.subject, key_access_log.created_at
+FROM key_access_log
+INNER JOIN keystore ON key_access_log.keystore_id = keystore.id
+WHERE key_access_log.seq IS NULL
+ORDER BY key_access_log.id ASC LIMIT $1;
+
+--name: seal-key-access-log
+-- Append a key access log row to the hash chain
+-- $1: id
+-- $2: seq
+-- $3: prev_hash
+-- $4: hash
+UPDATE key_access_log SET seq = $2, prev_hash = $3, hash = $4 WHERE id = $1 AND seq IS NULL;
+
+--name: get-sealed-key-access-log
+-- Get hash chained key access log rows in chain order
+-- $1: after_seq
+-- $2: limit
+SELECT key_access_log.id, keystore.public_key, key_access_log.caller, key_access_log.tracking_id, key_access_log.subject, key_access_log.created_at, key_access_log.seq, key_access_log.prev_hash, key_access_log.hash
+FROM key_access_log
+INNER JOIN keystore ON key_access_log.keystore_id = keystore.id
+WHERE key_access_log.seq > $1
+ORDER BY key_access_log.seq ASC LIMIT $2;
+
+--name: get-key-access-log-by-account
+-- Get key access log by account
+-- $1: public_key
+-- $2: limit
+SELECT key_access_log.id, keystore.public_key, key_access_log.caller, key_access_log.tracking_id, key_access_log.subject, key_access_log.created_at, key_access_log.seq, key_access_log.prev_hash, key_access_log.hash
+FROM keystore
+INNER JOIN key_access_log ON keystore.id = key_access_log.keystore_id
+WHERE keystore.public_key = $1
+ORDER BY key_access_log.id ASC LIMIT $2;
+
+--name: get-key-access-log-by-account-next
+-- Get key access log by account
 -- $1: public_key
-SELECT active FROM keystore WHERE public_key=$1;
+-- $2: cursor
+-- $3: limit
+SELECT key_access_log.id, keystore.public_key, key_access_log.caller, key_access_log.tracking_id, key_access_log.subject, key_access_log.created_at, key_access_log.seq, key_access_log.prev_hash, key_access_log.hash
+FROM keystore
+INNER JOIN key_access_log ON keystore.id = key_access_log.keystore_id
+WHERE keystore.public_key = $1
+AND key_access_log.id > $2
+ORDER BY key_access_log.id ASC LIMIT $3;
+
+--name: get-key-access-log-by-account-previous
+-- Get key access log by account
+-- $1: public_key
+-- $2: cursor
+-- $3: limit
+SELECT * FROM (
+    SELECT key_access_log.id, keystore.public_key, key_access_log.caller, key_access_log.tracking_id, key_access_log.subject, key_access_log.created_at, key_access_log.seq, key_access_log.prev_hash, key_access_log.hash
+    FROM keystore
+    INNER JOIN key_access_log ON keystore.id = key_access_log.keystore_id
+    WHERE keystore.public_key = $1
+    AND key_access_log.id < $2
+    ORDER BY key_access_log.id DESC LIMIT $3
+) AS previous_page ORDER BY id ASC;
 
 --name: load-master-key
 -- Load saved master key pair
-SELECT public_key, private_key FROM keystore
-INNER JOIN (SELECT id FROM master_key LIMIT 1) master_key ON keystore.id = master_key.id;
+SELECT keystore.id, public_key, private_key, data_key, key_version FROM keystore
+INNER JOIN (SELECT id FROM master_key ORDER BY id ASC LIMIT 1) master_key ON keystore.id = master_key.id;
+
+--name: load-master-address
+-- Load the master key's public key, preferring enabled system signers
+SELECT public_key FROM keystore
+INNER JOIN (SELECT id FROM master_key ORDER BY retired ASC, enabled DESC, id ASC LIMIT 1) master_key ON keystore.id = master_key.id;
+
+--name: get-system-signers
+-- Get all system signers including disabled and retired ones
+SELECT keystore.public_key, master_key.enabled, master_key.retired, master_key.created_at FROM master_key
+INNER JOIN keystore ON master_key.id = keystore.id
+ORDER BY master_key.id ASC;
+
+--name: next-system-signer-round-robin
+-- Pick the next active system signer in round robin order
+WITH signers AS (
+    SELECT keystore.public_key, row_number() OVER (ORDER BY master_key.id ASC) - 1 AS idx, count(*) OVER () AS total
+    FROM master_key
+    INNER JOIN keystore ON master_key.id = keystore.id
+    WHERE master_key.enabled AND NOT master_key.retired
+), rr AS (
+    SELECT nextval('system_signer_rr_seq') AS n
+)
+SELECT signers.public_key FROM signers, rr
+WHERE signers.idx = rr.n % signers.total;
+
+--name: next-system-signer-least-pending
+-- Pick the active system signer with the fewest signed transactions that are not yet confirmed
+SELECT keystore.public_key FROM master_key
+INNER JOIN keystore ON master_key.id = keystore.id
+WHERE master_key.enabled AND NOT master_key.retired
+ORDER BY (
+    SELECT COUNT(*) FROM otx
+    INNER JOIN dispatch ON otx.id = dispatch.otx_id
+    WHERE otx.signer_account = keystore.id AND dispatch.status IN ('PENDING', 'IN_NETWORK')
+) ASC, master_key.id ASC
+LIMIT 1;
+
+--name: add-system-signer
+-- Add an existing active key to the system signer pool, disabled until it is funded and allowlisted on chain
+-- $1: public_key
+INSERT INTO master_key (id, enabled)
+SELECT id, false FROM keystore WHERE public_key = $1
+RETURNING id;
+
+--name: enable-system-signer
+-- Let a disabled system signer receive work
+-- $1: public_key
+UPDATE master_key
+SET enabled = true
+WHERE id = (SELECT id FROM keystore WHERE public_key = $1) AND NOT enabled AND NOT retired
+RETURNING id;
+
+--name: retire-system-signer
+-- Retire a system signer so that no new work is assigned to it
+-- $1: public_key
+UPDATE master_key
+SET retired = true
+WHERE id = (SELECT id FROM keystore WHERE public_key = $1) AND NOT retired
+RETURNING id;
+
+--name: begin-master-signer-rotation
+-- Record the rotation from an active system signer to the new key, the old signer keeps receiving work
+-- $1: old_public_key
+-- $2: new_public_key
+INSERT INTO master_key_rotation(old_key_id, new_key_id)
+SELECT old_key.id, new_key.id FROM master_key
+INNER JOIN keystore old_key ON master_key.id = old_key.id, keystore new_key
+WHERE old_key.public_key = $1 AND NOT master_key.retired AND new_key.public_key = $2
+RETURNING id;
+
+--name: switch-master-signer-rotation
+-- Retire the old system signer and add the new key to the system signer pool in its place
+-- $1: rotation_id
+WITH rotation AS (
+    UPDATE master_key_rotation
+    SET switched = true
+    WHERE id = $1 AND NOT switched AND NOT completed
+    RETURNING old_key_id, new_key_id
+), retired AS (
+    UPDATE master_key
+    SET retired = true
+    WHERE id = (SELECT old_key_id FROM rotation)
+)
+INSERT INTO master_key (id)
+SELECT new_key_id FROM rotation
+RETURNING id;
+
+--name: complete-master-signer-rotation
+-- Mark a switched rotation as completed once the old key has settled
+-- $1: rotation_id
+UPDATE master_key_rotation
+SET completed = true
+WHERE id = $1 AND switched AND NOT completed
+RETURNING id;
+
+--name: get-pending-master-signer-rotation
+-- Get the master signer rotation in progress
+SELECT master_key_rotation.id, old_key.public_key AS old_public_key, new_key.public_key AS new_public_key, master_key_rotation.switched, master_key_rotation.created_at
+FROM master_key_rotation
+INNER JOIN keystore old_key ON master_key_rotation.old_key_id = old_key.id
+INNER JOIN keystore new_key ON master_key_rotation.new_key_id = new_key.id
+WHERE NOT master_key_rotation.completed;
 
 --name: bootstrap-master-key
 -- Save newely hex encoded private key to be used as a master key
 -- $1: public_key
 -- $2: private_key
+-- $3: data_key
+-- $4: key_version
 WITH new_key AS (
-    INSERT INTO keystore (public_key, private_key, active)
-    SELECT $1, $2, true
+    INSERT INTO keystore (public_key, private_key, data_key, key_version, "status")
+    SELECT $1, $2, $3, $4, 'ACTIVE'
     WHERE NOT EXISTS (
         SELECT 1 FROM master_key
     )
@@ -42,6 +351,25 @@ INSERT INTO master_key (id)
 SELECT id FROM new_key
 RETURNING id;
 
+--name: get-keys-for-rekey
+-- Lock a batch of keys that are not yet sealed with the current KEK version
+-- $1: key_version
+-- $2: limit
+SELECT id, public_key, private_key, data_key, key_version FROM keystore
+WHERE key_version <> $1 AND derivation_index IS NULL AND private_key <> ''
+ORDER BY id ASC LIMIT $2
+FOR UPDATE SKIP LOCKED;
+
+--name: update-sealed-key
+-- Replace a key with its re-encrypted envelope
+-- $1: id
+-- $2: private_key
+-- $3: data_key
+-- $4: key_version
+UPDATE keystore
+SET private_key = $2, data_key = $3, key_version = $4
+WHERE id = $1;
+
 --name: peek-nonce
 -- Get current nonce
 -- $1: public_key
@@ -82,6 +410,68 @@ WHERE keystore_id = (
     SELECT id FROM keystore WHERE public_key = $1
 );
 
+--name: get-next-nonce
+-- Get the next nonce the noncestore hands out to an account
+-- $1: public_key
+SELECT next_nonce FROM noncestore
+WHERE keystore_id = (SELECT id FROM keystore WHERE public_key = $1);
+
+--name: get-nonce-check-accounts
+-- Get accounts whose nonce moved recently along with their internal next nonce
+-- $1: updated_since
+-- $2: limit
+SELECT keystore.public_key, noncestore.next_nonce FROM noncestore
+INNER JOIN keystore ON noncestore.keystore_id = keystore.id
+WHERE noncestore.updated_at >= $1
+ORDER BY noncestore.updated_at DESC LIMIT $2;
+
+--name: get-nonce-gaps
+-- Get nonces between the network nonce and the internal next nonce that have no OTX, aborted OTXs were never dispatched
+-- $1: public_key
+-- $2: network_nonce
+-- $3: limit
+SELECT gap.nonce FROM keystore
+INNER JOIN noncestore ON keystore.id = noncestore.keystore_id
+CROSS JOIN LATERAL generate_series($2::INT, noncestore.next_nonce - 1) AS gap(nonce)
+WHERE keystore.public_key = $1
+AND NOT EXISTS (
+    SELECT 1 FROM otx
+    INNER JOIN dispatch ON otx.id = dispatch.otx_id
+    WHERE otx.signer_account = keystore.id AND otx.nonce = gap.nonce AND dispatch.status <> 'ABORTED'
+)
+ORDER BY gap.nonce ASC LIMIT $3;
+
+--name: raise-account-nonce
+-- Raise the next nonce if it is below the given value, a concurrent acquire is never moved backwards
+-- $1: public_key
+-- $2: nonce_value
+UPDATE noncestore
+SET next_nonce = $2
+WHERE keystore_id = (
+    SELECT id FROM keystore WHERE public_key = $1
+) AND next_nonce < $2;
+
+--name: insert-nonce-gap
+-- Record a nonce gap finding and its repair
+-- $1: public_key
+-- $2: kind
+-- $3: nonce
+-- $4: internal_nonce
+-- $5: network_nonce
+-- $6: repaired
+-- $7: repair_otx_id
+-- $8: error
+INSERT INTO nonce_gap(keystore_id, kind, nonce, internal_nonce, network_nonce, repaired, repair_otx_id, error)
+VALUES((SELECT id FROM keystore WHERE public_key = $1), $2, $3, $4, $5, $6, $7, $8);
+
+--name: get-nonce-gaps-report
+-- Get the latest nonce gap findings
+-- $1: limit
+SELECT nonce_gap.id, keystore.public_key, nonce_gap.kind, nonce_gap.nonce, nonce_gap.internal_nonce, nonce_gap.network_nonce, nonce_gap.repaired, nonce_gap.repair_otx_id, nonce_gap.error, nonce_gap.created_at
+FROM nonce_gap
+INNER JOIN keystore ON nonce_gap.keystore_id = keystore.id
+ORDER BY nonce_gap.id DESC LIMIT $1;
+
 --name: insert-otx
 -- Create a new locally originating tx
 -- $1: tracking_id
@@ -90,29 +480,81 @@ WHERE keystore_id = (
 -- $4: raw_tx
 -- $5: tx_hash
 -- $6: nonce
+-- $7: replaces_otx_id
+-- $8: subject
+-- $9: gas_limit
+-- $10: gas_limit_source
 INSERT INTO otx(
     tracking_id,
     otx_type,
     signer_account,
     raw_tx,
     tx_hash,
-    nonce
-) VALUES($1, $2, (SELECT id FROM keystore WHERE public_key = $3), $4, $5, $6) RETURNING id;
+    nonce,
+    replaces_otx_id,
+    subject,
+    gas_limit,
+    gas_limit_source
+) VALUES($1, $2, (SELECT id FROM keystore WHERE public_key = $3), $4, $5, $6, $7, $8, $9, $10) RETURNING id;
+
+--name: resolve-replacements
+-- Once an OTX is mined, every other OTX of the same signer and nonce can never be mined. They are cancelled if either
+-- side is a cancellation, otherwise replaced. The mined OTX becomes the effective one of its lineage.
+-- $1: otx_id
+WITH mined AS (
+    SELECT id, tracking_id, otx_type, signer_account, nonce FROM otx WHERE id = $1
+), dead AS (
+    UPDATE dispatch
+    SET "status" = CASE WHEN otx.otx_type = 'CANCEL' OR mined.otx_type = 'CANCEL' THEN 'CANCELLED' ELSE 'REPLACED' END
+    FROM otx, mined
+    WHERE dispatch.otx_id = otx.id
+    AND otx.signer_account = mined.signer_account
+    AND otx.nonce = mined.nonce
+    AND otx.id <> mined.id
+    AND dispatch.status NOT IN ('SUCCESS', 'CONFIRMED', 'REVERTED', 'CANCELLED', 'REPLACED', 'ABORTED')
+    RETURNING otx.id AS otx_id, otx.tracking_id, dispatch.status
+), lineage AS (
+    UPDATE otx
+    SET replaced = otx.id <> mined.id
+    FROM mined
+    WHERE otx.id = mined.id OR otx.id IN (SELECT otx_id FROM dead)
+)
+SELECT dead.tracking_id, dead.status FROM dead, mined
+WHERE dead.tracking_id <> mined.tracking_id;
+
+--name: mark-otx-replaced
+-- Mark an OTX as superseded by a replacement at the same nonce
+-- $1: otx_id
+UPDATE otx SET replaced = true WHERE id = $1;
+
+--name: get-deploy-nonces
+-- Get the nonces of successful contract deploys signed by an account, the contract addresses derive from them
+-- $1: public_key
+SELECT otx.nonce FROM otx
+INNER JOIN keystore ON otx.signer_account = keystore.id
+INNER JOIN dispatch ON otx.id = dispatch.otx_id
+WHERE keystore.public_key = $1
+  AND otx.otx_type IN (
+      'STANDARD_TOKEN_DEPLOY', 'DEMURRAGE_TOKEN_DEPLOY', 'EXPIRING_TOKEN_DEPLOY', 'TOKEN_INDEX_DEPLOY',
+      'LIMITER_DEPLOY', 'SWAPPOOL_DEPLOY', 'PRICEINDEXQUOTER_DEPLOY'
+  )
+  AND dispatch.status IN ('SUCCESS', 'CONFIRMED')
+ORDER BY otx.nonce ASC;
 
 --name: get-otx-by-tx-hash
 -- Get OTX by tracking id
 -- $1: tx_hash
-SELECT otx.id, otx.tracking_id, otx.otx_type, otx.signer_account AS public_key, otx.raw_tx, otx.tx_hash, otx.nonce, otx.replaced, otx.created_at, otx.updated_at, dispatch.status FROM otx
+SELECT otx.id, otx.tracking_id, otx.otx_type, otx.signer_account AS public_key, otx.raw_tx, otx.tx_hash, otx.nonce, otx.replaced, otx.replaces_otx_id, otx.created_at, otx.updated_at, dispatch.status FROM otx
 INNER JOIN dispatch ON otx.id = dispatch.otx_id
 WHERE otx.tx_hash = $1;
 
 --name: get-otx-by-tracking-id
--- Get OTX by tracking id
+-- Get OTX by tracking id, replaced OTXs are included as the history of their nonce
 -- $1: tracking_id
-SELECT otx.id, otx.tracking_id, otx.otx_type, keystore.public_key, otx.raw_tx, otx.tx_hash, otx.nonce, otx.replaced, otx.created_at, otx.updated_at, dispatch.status FROM otx
+SELECT otx.id, otx.tracking_id, otx.otx_type, keystore.public_key, otx.raw_tx, otx.tx_hash, otx.nonce, otx.replaced, otx.replaces_otx_id, otx.gas_limit, otx.gas_limit_source, otx.created_at, otx.updated_at, dispatch.status FROM otx
 INNER JOIN keystore ON otx.signer_account = keystore.id
 INNER JOIN dispatch ON otx.id = dispatch.otx_id
-WHERE otx.tracking_id = $1 ORDER BY otx.nonce ASC;
+WHERE otx.tracking_id = $1 ORDER BY otx.nonce ASC, otx.id ASC;
 
 --name: get-otx-by-account
 -- Get OTX by account
@@ -122,6 +564,7 @@ SELECT otx.id, otx.tracking_id, otx.otx_type, keystore.public_key, otx.raw_tx, o
 INNER JOIN otx ON keystore.id = otx.signer_account
 INNER JOIN dispatch ON otx.id = dispatch.otx_id
 WHERE keystore.public_key = $1
+AND NOT otx.replaced
 ORDER BY otx.id ASC LIMIT $2;
 
 --name: get-otx-by-account-next
@@ -133,6 +576,7 @@ SELECT otx.id, otx.tracking_id, otx.otx_type, keystore.public_key, otx.raw_tx, o
 INNER JOIN otx ON keystore.id = otx.signer_account
 INNER JOIN dispatch ON otx.id = dispatch.otx_id
 WHERE keystore.public_key = $1
+AND NOT otx.replaced
 AND otx.id > $2
 ORDER BY otx.id ASC LIMIT $3;
 
@@ -146,6 +590,7 @@ SELECT * FROM (
 	INNER JOIN otx ON keystore.id = otx.signer_account
     INNER JOIN dispatch ON otx.id = dispatch.otx_id
 	WHERE keystore.public_key = $1
+  AND NOT otx.replaced
   AND otx.id < $2
   ORDER BY otx.id DESC LIMIT $3
 ) AS previous_page ORDER BY id ASC;
@@ -161,16 +606,203 @@ INSERT INTO dispatch(
 ) VALUES($1, $2) RETURNING id;
 
 --name: update-dispatch-tx-status
--- Create a new dispatch request
+-- Update the status of a dispatch request that is not in a final state yet, mined statuses are set with the receipt
 -- $1: status
 -- $2: otx_id
 UPDATE dispatch
 SET "status" = $1
-WHERE otx_id = $2;
+WHERE otx_id = $2
+AND "status" NOT IN ('SUCCESS', 'CONFIRMED', 'REVERTED', 'CANCELLED', 'REPLACED', 'EXTERNAL_DISPATCH', 'ACCOUNT_INACTIVE', 'ABORTED');
 
---name: get-failed-otx
+--name: update-dispatch-tx-receipt
+-- Update a dispatch request with the block its receipt was found in
+-- $1: status
+-- $2: block_number
+-- $3: block_hash
+-- $4: otx_id
+UPDATE dispatch
+SET "status" = $1, block_number = $2, block_hash = $3
+WHERE otx_id = $4;
+
+--name: get-dispatch-state
+-- Get the dispatch status of an OTX and whether it was replaced at its nonce
+-- $1: otx_id
+SELECT dispatch.status, otx.replaced FROM otx
+INNER JOIN dispatch ON otx.id = dispatch.otx_id
+WHERE otx.id = $1;
+
+--name: get-dispatch-dependency
+-- Get the dispatch status of an OTX and of the effective OTX at the nonce of its predecessor
+-- $1: otx_id
+-- $2: predecessor_otx_id
+SELECT
+    (SELECT "status" FROM dispatch WHERE otx_id = $1) AS status,
+    (
+        SELECT dispatch.status FROM otx
+        INNER JOIN dispatch ON otx.id = dispatch.otx_id
+        INNER JOIN otx predecessor ON otx.signer_account = predecessor.signer_account AND otx.nonce = predecessor.nonce
+        WHERE predecessor.id = $2
+        ORDER BY otx.replaced ASC, otx.id DESC LIMIT 1
+    ) AS predecessor_status;
+
+--name: get-predecessor-otx-id
+-- Get the id of the previous step of an OTX's multi transaction flow, the OTX at the preceding nonce of the same
+-- tracking id and signer, or 0 if it is the first step
+-- $1: otx_id
+SELECT COALESCE((
+    SELECT predecessor.id FROM otx
+    INNER JOIN otx predecessor ON otx.tracking_id = predecessor.tracking_id AND otx.signer_account = predecessor.signer_account
+    WHERE otx.id = $1 AND predecessor.nonce = otx.nonce - 1
+    ORDER BY predecessor.id ASC LIMIT 1
+), 0) AS predecessor_otx_id;
+
+--name: abort-successors
+-- Mark the not yet dispatched OTXs of the same tracking id and signer that follow an OTX as aborted
+-- $1: otx_id
+UPDATE dispatch
+SET "status" = 'ABORTED'
+FROM otx, otx failed
+WHERE dispatch.otx_id = otx.id
+AND failed.id = $1
+AND otx.tracking_id = failed.tracking_id
+AND otx.signer_account = failed.signer_account
+AND otx.nonce > failed.nonce
+AND dispatch.status = 'PENDING'
+RETURNING otx.id;
+
+--name: get-unconfirmed-otx
+-- Get mined OTXs that have not reached the confirmation depth yet and reverted OTXs without a stored receipt, oldest first
+-- $1: limit
+SELECT otx.id, otx.tracking_id, otx.raw_tx, otx.tx_hash, dispatch.status, dispatch.block_number, dispatch.block_hash FROM otx
+INNER JOIN dispatch ON otx.id = dispatch.otx_id
+WHERE dispatch.status = 'SUCCESS'
+OR (dispatch.status = 'REVERTED' AND NOT EXISTS (SELECT 1 FROM receipt WHERE receipt.otx_id = otx.id))
+ORDER BY dispatch.updated_at ASC LIMIT $1;
+
+--name: upsert-receipt
+-- Store the receipt of a mined OTX, a receipt from another block after a reorg replaces it
+-- $1: otx_id
+-- $2: tx_hash
+-- $3: block_number
+-- $4: block_hash
+-- $5: success
+-- $6: gas_used
+-- $7: effective_gas_price
+-- $8: contract_address
+-- $9: logs
+-- $10: l1_fee
+INSERT INTO receipt(otx_id, tx_hash, block_number, block_hash, success, gas_used, effective_gas_price, l1_fee, fee, contract_address, logs)
+VALUES($1, $2, $3, $4, $5, $6, $7::NUMERIC, $10::NUMERIC, $6 * $7::NUMERIC + $10::NUMERIC, $8, $9)
+ON CONFLICT (otx_id) DO UPDATE SET
+    tx_hash = EXCLUDED.tx_hash,
+    block_number = EXCLUDED.block_number,
+    block_hash = EXCLUDED.block_hash,
+    success = EXCLUDED.success,
+    gas_used = EXCLUDED.gas_used,
+    effective_gas_price = EXCLUDED.effective_gas_price,
+    l1_fee = EXCLUDED.l1_fee,
+    fee = EXCLUDED.fee,
+    contract_address = EXCLUDED.contract_address,
+    logs = EXCLUDED.logs;
+
+--name: delete-receipt
+-- Drop the receipt of an OTX that was reorged out
+-- $1: otx_id
+DELETE FROM receipt WHERE otx_id = $1;
+
+--name: get-receipts-by-tracking-id
+-- Get the receipts of an OTX chain
+-- $1: tracking_id
+SELECT receipt.otx_id, receipt.tx_hash, receipt.block_number, receipt.block_hash, receipt.success, receipt.gas_used,
+receipt.effective_gas_price::TEXT AS effective_gas_price, receipt.l1_fee::TEXT AS l1_fee, receipt.fee::TEXT AS fee, receipt.contract_address, receipt.logs, receipt.created_at
+FROM receipt
+INNER JOIN otx ON receipt.otx_id = otx.id
+WHERE otx.tracking_id = $1
+ORDER BY otx.nonce ASC, otx.id ASC;
+
+--name: get-fee-report
+-- Native gas spent per signer account, JWT subject and otx type for receipts stored in a time range, fees include the L1
+-- data fee
+-- $1: from
+-- $2: to
+SELECT keystore.public_key AS account, otx.subject, otx.otx_type, COUNT(*) AS tx_count,
+SUM(receipt.gas_used)::TEXT AS gas_used, SUM(receipt.l1_fee)::TEXT AS l1_fees, SUM(receipt.fee)::TEXT AS fees
+FROM receipt
+INNER JOIN otx ON receipt.otx_id = otx.id
+INNER JOIN keystore ON otx.signer_account = keystore.id
+WHERE receipt.created_at >= $1 AND receipt.created_at < $2
+GROUP BY keystore.public_key, otx.subject, otx.otx_type
+ORDER BY SUM(receipt.fee) DESC;
+
+--name: insert-dispatch-attempt
+-- Append a broadcast attempt of an OTX
+-- $1: otx_id
+-- $2: source
+-- $3: tx_hash
+-- $4: raw_tx
+-- $5: gas_fee_cap
+-- $6: gas_tip_cap
+-- $7: rpc_endpoint
+-- $8: status
+-- $9: rpc_error
+INSERT INTO dispatch_attempt(otx_id, source, tx_hash, raw_tx, gas_fee_cap, gas_tip_cap, rpc_endpoint, "status", rpc_error)
+VALUES($1, $2, $3, $4, $5::NUMERIC, $6::NUMERIC, $7, $8, $9);
+
+--name: get-dispatch-attempts-by-tracking-id
+-- Get the broadcast timeline of an OTX chain, including replacements sharing the tracking id
+-- $1: tracking_id
+SELECT dispatch_attempt.id, dispatch_attempt.otx_id, dispatch_attempt.source, dispatch_attempt.tx_hash, dispatch_attempt.raw_tx,
+dispatch_attempt.gas_fee_cap::TEXT AS gas_fee_cap, dispatch_attempt.gas_tip_cap::TEXT AS gas_tip_cap, dispatch_attempt.rpc_endpoint,
+dispatch_attempt.status, dispatch_attempt.rpc_error, dispatch_attempt.created_at
+FROM dispatch_attempt
+INNER JOIN otx ON dispatch_attempt.otx_id = otx.id
+WHERE otx.tracking_id = $1
+ORDER BY dispatch_attempt.id ASC;
+
+--name: insert-retrier-action
+-- Record a retrier remediation
+-- $1: otx_id
+-- $2: attempt
+-- $3: dispatch_status
+-- $4: action
+-- $5: detail
+INSERT INTO retrier_action(otx_id, attempt, dispatch_status, action, detail) VALUES($1, $2, $3, $4, $5);
+
+--name: get-retrier-actions-by-tracking-id
+-- Get the retrier remediations of an OTX chain
+-- $1: tracking_id
+SELECT retrier_action.id, retrier_action.otx_id, retrier_action.attempt, retrier_action.dispatch_status, retrier_action.action, retrier_action.detail, retrier_action.created_at
+FROM retrier_action
+INNER JOIN otx ON retrier_action.otx_id = otx.id
+WHERE otx.tracking_id = $1
+ORDER BY retrier_action.id ASC;
+
+--name: get-non-final-otx
+-- Page through OTXs that are not mined yet, including replaced ones which may still be mined
+-- $1: cursor
+-- $2: limit
 SELECT otx.id, otx.tracking_id, otx.otx_type, keystore.public_key, otx.raw_tx, otx.tx_hash, otx.nonce, otx.replaced, otx.created_at, otx.updated_at, dispatch.status FROM keystore
 INNER JOIN otx ON keystore.id = otx.signer_account
 INNER JOIN dispatch ON otx.id = dispatch.otx_id
-WHERE dispatch.status NOT IN ('SUCCESS', 'REVERTED', 'PENDING') AND otx.otx_type NOT IN ('GENERIC_SIGN', 'OTHER_MANUAL')
-ORDER BY otx.id ASC LIMIT 100;
\ No newline at end of file
+WHERE dispatch.status NOT IN ('SUCCESS', 'CONFIRMED', 'REVERTED', 'CANCELLED', 'REPLACED', 'EXTERNAL_DISPATCH', 'ACCOUNT_INACTIVE', 'ABORTED')
+AND otx.otx_type NOT IN ('GENERIC_SIGN', 'OTHER_MANUAL')
+AND otx.id > $1
+ORDER BY otx.id ASC LIMIT $2;
+--name: insert-simulation-failure
+-- Record a simulation that reverted before signing
+-- $1: tracking_id
+-- $2: otx_type
+-- $3: signer_account
+-- $4: contract_address
+-- $5: input
+-- $6: revert_reason
+INSERT INTO simulation_failure(tracking_id, otx_type, signer_account, contract_address, input, revert_reason)
+VALUES($1, $2, $3, $4, $5, $6);
+
+--name: get-simulation-failures-by-tracking-id
+-- Get the failed simulations of an OTX chain
+-- $1: tracking_id
+SELECT id, tracking_id, otx_type, signer_account, contract_address, input, revert_reason, created_at
+FROM simulation_failure
+WHERE tracking_id = $1
+ORDER BY id ASC;